@@ -0,0 +1,65 @@
+package action
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Handle tracks a high-level action (typing, clicking...) running in the background.
+type Handle interface {
+	// Wait blocks until the action ends and returns context.Canceled if it was cancelled.
+	Wait() error
+	// Cancel stops the action: pressed keys and buttons are released before Wait returns.
+	Cancel()
+	// Done is closed when the action ends.
+	Done() <-chan struct{}
+	// Progress returns the number of completed steps and the total number of steps.
+	Progress() (done, total int)
+}
+
+// Func runs an action, calling step once each step is completed.
+type Func func(ctx context.Context, step func()) error
+
+// Start runs fn in a new goroutine and returns its handle.
+// The action is cancelled when ctx is done or when Cancel is called.
+func Start(ctx context.Context, total int, fn Func) Handle {
+	ctx, cancel := context.WithCancel(ctx)
+	h := &handle{
+		cancel: cancel,
+		done:   make(chan struct{}),
+		total:  total,
+	}
+	go func() {
+		defer close(h.done)
+		defer cancel()
+		h.err = fn(ctx, func() {
+			h.completed.Add(1)
+		})
+	}()
+	return h
+}
+
+type handle struct {
+	cancel    context.CancelFunc
+	done      chan struct{}
+	err       error
+	total     int
+	completed atomic.Int64
+}
+
+func (h *handle) Wait() error {
+	<-h.done
+	return h.err
+}
+
+func (h *handle) Cancel() {
+	h.cancel()
+}
+
+func (h *handle) Done() <-chan struct{} {
+	return h.done
+}
+
+func (h *handle) Progress() (done, total int) {
+	return int(h.completed.Load()), h.total
+}
@@ -0,0 +1,58 @@
+package action
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStart_Completes(t *testing.T) {
+	h := Start(context.Background(), 3, func(ctx context.Context, step func()) error {
+		for i := 0; i < 3; i++ {
+			step()
+		}
+		return nil
+	})
+	if err := h.Wait(); err != nil {
+		t.Fatalf("Wait = %v, want nil", err)
+	}
+	if done, total := h.Progress(); done != 3 || total != 3 {
+		t.Errorf("Progress = %d/%d, want 3/3", done, total)
+	}
+	select {
+	case <-h.Done():
+	default:
+		t.Error("Done must be closed after Wait")
+	}
+}
+
+func TestStart_Cancel(t *testing.T) {
+	started := make(chan struct{})
+	h := Start(context.Background(), 2, func(ctx context.Context, step func()) error {
+		step()
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+	h.Cancel()
+	h.Cancel()
+	if err := h.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait = %v, want context.Canceled", err)
+	}
+	if done, total := h.Progress(); done != 1 || total != 2 {
+		t.Errorf("Progress = %d/%d, want 1/2", done, total)
+	}
+}
+
+func TestStart_ParentContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	h := Start(ctx, 1, func(ctx context.Context, step func()) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	cancel()
+	if err := h.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait = %v, want context.Canceled", err)
+	}
+}
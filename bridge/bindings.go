@@ -0,0 +1,78 @@
+package bridge
+
+import (
+	"time"
+
+	"github.com/jbdemonte/virtual-device/gamepad"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// Side selects a stick of the gamepad.
+type Side int
+
+const (
+	SideLeft Side = iota
+	SideRight
+)
+
+// Bindings is the table translating the keyboard and the mouse into gamepad inputs.
+type Bindings struct {
+	Keys         map[linux.Key]gamepad.Button
+	MouseButtons map[linux.Button]gamepad.Button
+	KeySticks    []KeyStick
+	Mouse        *MouseStick // nil leaves the mouse motion unbound
+}
+
+// KeyStick moves a stick with 4 keys, the diagonals being kept on the unit circle.
+type KeyStick struct {
+	Side                  Side
+	Up, Down, Left, Right linux.Key
+	Ramp                  time.Duration // time to go from the center to a full deflection, 0 being immediate
+}
+
+// MouseStick moves a stick with the speed of the mouse.
+// A speed of s counts per millisecond deflects the stick by Sensitivity * s * (1 + Acceleration * s), up to 1.
+type MouseStick struct {
+	Side         Side
+	Sensitivity  float64
+	Acceleration float64
+	Decay        time.Duration // time constant of the return to the center once the mouse stops, 0 being immediate
+	AntiDeadzone float32       // minimal deflection of a moving mouse, to get past the deadzone of the game
+}
+
+// DefaultBindings returns the usual layout of the shooters: WASD to the left stick, the mouse to the right stick,
+// the mouse buttons to the triggers, Space to jump (South) and the arrows to the D-pad.
+func DefaultBindings() Bindings {
+	return Bindings{
+		Keys: map[linux.Key]gamepad.Button{
+			linux.KEY_SPACE:     gamepad.ButtonSouth,
+			linux.KEY_LEFTCTRL:  gamepad.ButtonEast,
+			linux.KEY_R:         gamepad.ButtonWest,
+			linux.KEY_F:         gamepad.ButtonNorth,
+			linux.KEY_Q:         gamepad.ButtonL1,
+			linux.KEY_E:         gamepad.ButtonR1,
+			linux.KEY_LEFTSHIFT: gamepad.ButtonL3,
+			linux.KEY_V:         gamepad.ButtonR3,
+			linux.KEY_TAB:       gamepad.ButtonSelect,
+			linux.KEY_ESC:       gamepad.ButtonStart,
+			linux.KEY_UP:        gamepad.ButtonUp,
+			linux.KEY_DOWN:      gamepad.ButtonDown,
+			linux.KEY_LEFT:      gamepad.ButtonLeft,
+			linux.KEY_RIGHT:     gamepad.ButtonRight,
+		},
+		MouseButtons: map[linux.Button]gamepad.Button{
+			linux.BTN_LEFT:  gamepad.ButtonR2,
+			linux.BTN_RIGHT: gamepad.ButtonL2,
+		},
+		KeySticks: []KeyStick{
+			{Side: SideLeft, Up: linux.KEY_W, Down: linux.KEY_S, Left: linux.KEY_A, Right: linux.KEY_D, Ramp: 100 * time.Millisecond},
+		},
+		Mouse: &MouseStick{
+			Side:         SideRight,
+			Sensitivity:  0.05,
+			Acceleration: 0.1,
+			Decay:        30 * time.Millisecond,
+			AntiDeadzone: 0.15,
+		},
+	}
+}
@@ -0,0 +1,307 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jbdemonte/virtual-device/clock"
+	"github.com/jbdemonte/virtual-device/evdev"
+	"github.com/jbdemonte/virtual-device/gamepad"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// Bridge reads a real keyboard and mouse and drives a virtual gamepad according to a binding table.
+type Bridge interface {
+	Start() error
+	Stop() error
+
+	// Gamepad returns the driven gamepad.
+	Gamepad() gamepad.VirtualGamepad
+}
+
+// BridgeFactory configures and creates Bridge instances.
+type BridgeFactory interface {
+	WithKeyboardPath(path string) BridgeFactory
+	WithKeyboard(source evdev.InputDevice) BridgeFactory
+	WithMousePath(path string) BridgeFactory
+	WithMouse(source evdev.InputDevice) BridgeFactory
+	WithGamepad(gp gamepad.VirtualGamepad) BridgeFactory
+	WithBindings(bindings Bindings) BridgeFactory
+	WithTickRate(hz float64) BridgeFactory
+	WithoutGrab() BridgeFactory
+	WithClock(clock clock.Clock) BridgeFactory
+	Create() Bridge
+}
+
+// DefaultTickRate is the rate at which the ramps and the mouse stick are updated.
+const DefaultTickRate = 250
+
+// NewBridgeFactory returns a new factory for building bridges.
+func NewBridgeFactory() BridgeFactory {
+	return &bridgeFactory{
+		bindings: DefaultBindings(),
+		tickRate: DefaultTickRate,
+		grab:     true,
+	}
+}
+
+type bridgeFactory struct {
+	keyboardPath string
+	keyboard     evdev.InputDevice
+	mousePath    string
+	mouse        evdev.InputDevice
+	gamepad      gamepad.VirtualGamepad
+	bindings     Bindings
+	tickRate     float64
+	grab         bool
+	clock        clock.Clock
+}
+
+func (f *bridgeFactory) WithKeyboardPath(path string) BridgeFactory {
+	f.keyboardPath = path
+	return f
+}
+
+func (f *bridgeFactory) WithKeyboard(source evdev.InputDevice) BridgeFactory {
+	f.keyboard = source
+	return f
+}
+
+func (f *bridgeFactory) WithMousePath(path string) BridgeFactory {
+	f.mousePath = path
+	return f
+}
+
+func (f *bridgeFactory) WithMouse(source evdev.InputDevice) BridgeFactory {
+	f.mouse = source
+	return f
+}
+
+func (f *bridgeFactory) WithGamepad(gp gamepad.VirtualGamepad) BridgeFactory {
+	f.gamepad = gp
+	return f
+}
+
+// WithBindings replaces the binding table, DefaultBindings being used otherwise.
+func (f *bridgeFactory) WithBindings(bindings Bindings) BridgeFactory {
+	f.bindings = bindings
+	return f
+}
+
+func (f *bridgeFactory) WithTickRate(hz float64) BridgeFactory {
+	f.tickRate = hz
+	return f
+}
+
+// WithoutGrab leaves the keyboard and the mouse to the other applications as well.
+func (f *bridgeFactory) WithoutGrab() BridgeFactory {
+	f.grab = false
+	return f
+}
+
+func (f *bridgeFactory) WithClock(clock clock.Clock) BridgeFactory {
+	f.clock = clock
+	return f
+}
+
+// Create falls back to DefaultTickRate for a tick rate which is not positive.
+func (f *bridgeFactory) Create() Bridge {
+	c := f.clock
+	if c == nil {
+		c = clock.New()
+	}
+	tickRate := f.tickRate
+	if !(tickRate > 0) {
+		tickRate = DefaultTickRate
+	}
+	return &bridge{
+		sources: []*source{
+			{path: f.keyboardPath, device: f.keyboard},
+			{path: f.mousePath, device: f.mouse},
+		},
+		gamepad:  f.gamepad,
+		bindings: f.bindings,
+		tick:     time.Duration(float64(time.Second) / tickRate),
+		grab:     f.grab,
+		clock:    c,
+	}
+}
+
+// source is the keyboard or the mouse, opened by Start when only its path is set.
+type source struct {
+	path   string
+	device evdev.InputDevice
+	closed bool // an injected device closed by Stop, which can not be read again
+}
+
+type bridge struct {
+	sources  []*source
+	gamepad  gamepad.VirtualGamepad
+	bindings Bindings
+	tick     time.Duration
+	grab     bool
+	clock    clock.Clock
+
+	mu      sync.Mutex
+	running bool
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func (b *bridge) Gamepad() gamepad.VirtualGamepad {
+	return b.gamepad
+}
+
+func (b *bridge) Start() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.running {
+		return nil
+	}
+	if b.gamepad == nil {
+		return errors.New("no gamepad configured")
+	}
+
+	for _, s := range b.sources {
+		if s.closed {
+			return fmt.Errorf("%s was closed by Stop, the bridge needs a fresh device", s.device.Path())
+		}
+	}
+
+	var devices []evdev.InputDevice
+	for _, s := range b.sources {
+		if s.device == nil && s.path != "" {
+			device, err := evdev.Open(s.path)
+			if err != nil {
+				b.releaseSources()
+				return err
+			}
+			s.device = device
+		}
+		if s.device == nil {
+			continue
+		}
+		devices = append(devices, s.device)
+		if b.grab {
+			if err := s.device.Grab(); err != nil {
+				b.releaseSources()
+				return err
+			}
+		}
+	}
+	if len(devices) == 0 {
+		return errors.New("no keyboard or mouse configured")
+	}
+
+	if err := b.gamepad.Register(); err != nil {
+		b.releaseSources()
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan []linux.InputEvent)
+	for _, device := range devices {
+		b.wg.Add(1)
+		go b.read(ctx, device, events)
+	}
+	b.wg.Add(1)
+	go b.loop(ctx, newMapper(b.gamepad, b.bindings), events)
+
+	b.running = true
+	b.cancel = cancel
+	return nil
+}
+
+// read forwards the events of a source to the loop, until the source is closed.
+func (b *bridge) read(ctx context.Context, device evdev.InputDevice, events chan<- []linux.InputEvent) {
+	defer b.wg.Done()
+	for {
+		batch, err := device.ReadEvents()
+		if err != nil {
+			if !errors.Is(err, evdev.ErrClosed) {
+				fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", device.Path(), err)
+			}
+			return
+		}
+		select {
+		case events <- batch:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// loop applies the events as they come, and ticks the sticks at a fixed rate.
+func (b *bridge) loop(ctx context.Context, m *mapper, events <-chan []linux.InputEvent) {
+	defer b.wg.Done()
+	defer m.reset()
+
+	last := b.clock.Now()
+	for {
+		timer := b.clock.NewTimer(last.Add(b.tick).Sub(b.clock.Now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case batch := <-events:
+			timer.Stop()
+			for _, event := range batch {
+				m.handle(event)
+			}
+		case <-timer.C():
+			now := b.clock.Now()
+			m.tick(now.Sub(last))
+			last = now
+		}
+	}
+}
+
+// Stop closes the keyboard and the mouse, releasing their grabs, releases the inputs held by the bridge
+// and unregisters the gamepad. The sources set by path are opened again by the next Start, while an
+// injected device stays closed: Start then fails.
+func (b *bridge) Stop() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.running {
+		return nil
+	}
+	b.running = false
+
+	b.cancel()
+	var errs []error
+	for _, s := range b.sources {
+		if s.device == nil {
+			continue
+		}
+		errs = append(errs, s.device.Close())
+		if s.path != "" {
+			s.device = nil // a closed source can not be reused, Start opens its path again
+		} else {
+			s.closed = true
+		}
+	}
+	b.wg.Wait()
+
+	errs = append(errs, b.gamepad.Unregister())
+	return errors.Join(errs...)
+}
+
+// releaseSources undoes a failed Start: the grabs are released and the sources opened from a path are closed.
+func (b *bridge) releaseSources() {
+	for _, s := range b.sources {
+		if s.device == nil {
+			continue
+		}
+		_ = s.device.Ungrab()
+		if s.path != "" {
+			_ = s.device.Close()
+			s.device = nil
+		}
+	}
+}
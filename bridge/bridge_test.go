@@ -0,0 +1,256 @@
+package bridge
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/clock"
+	"github.com/jbdemonte/virtual-device/evdev"
+	"github.com/jbdemonte/virtual-device/gamepad"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+type fakeSource struct {
+	events  chan []linux.InputEvent
+	grabbed bool
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{events: make(chan []linux.InputEvent, 16)}
+}
+
+func (s *fakeSource) Path() string                              { return "/dev/input/event42" }
+func (s *fakeSource) Name() string                              { return "Fake Keyboard" }
+func (s *fakeSource) Phys() string                              { return "" }
+func (s *fakeSource) Uniq() string                              { return "" }
+func (s *fakeSource) ID() linux.InputID                         { return linux.InputID{} }
+func (s *fakeSource) Capabilities() virtual_device.Capabilities { return virtual_device.Capabilities{} }
+func (s *fakeSource) AbsAxes() []virtual_device.AbsAxis         { return nil }
+
+func (s *fakeSource) Grab() error   { s.grabbed = true; return nil }
+func (s *fakeSource) Ungrab() error { s.grabbed = false; return nil }
+
+func (s *fakeSource) ReadEvents() ([]linux.InputEvent, error) {
+	events, ok := <-s.events
+	if !ok {
+		return nil, evdev.ErrClosed
+	}
+	return events, nil
+}
+
+func (s *fakeSource) Close() error {
+	s.grabbed = false
+	close(s.events)
+	return nil
+}
+
+func key(code linux.Key, value int32) linux.InputEvent {
+	return linux.InputEvent{Type: uint16(linux.EV_KEY), Code: uint16(code), Value: value}
+}
+
+func rel(axis linux.RelativeAxis, value int32) linux.InputEvent {
+	return linux.InputEvent{Type: uint16(linux.EV_REL), Code: uint16(axis), Value: value}
+}
+
+var stickAxis = virtual_device.AbsAxis{Min: -32768, Max: 32767}
+
+func newTestGamepad(mock *vdtest.Device) gamepad.VirtualGamepad {
+	axis := func(code linux.AbsoluteAxis) virtual_device.AbsAxis {
+		a := stickAxis
+		a.Axis = code
+		return a
+	}
+	return gamepad.NewVirtualGamepadFactory().
+		WithDevice(mock).
+		WithDigital(gamepad.MappingDigital{
+			gamepad.ButtonSouth: linux.BTN_SOUTH,
+			gamepad.ButtonR2:    linux.BTN_TR2,
+		}).
+		WithLeftStick(gamepad.MappingStick{X: axis(linux.ABS_X), Y: axis(linux.ABS_Y)}).
+		WithRightStick(gamepad.MappingStick{X: axis(linux.ABS_RX), Y: axis(linux.ABS_RY)}).
+		Create()
+}
+
+func TestMapper_Buttons(t *testing.T) {
+	mock := vdtest.NewDevice()
+	bindings := DefaultBindings()
+	bindings.Keys[linux.KEY_ENTER] = gamepad.ButtonSouth
+	m := newMapper(newTestGamepad(mock), bindings)
+
+	m.handle(key(linux.KEY_SPACE, 1))
+	m.handle(key(linux.KEY_ENTER, 1))
+	m.handle(key(linux.KEY_SPACE, 2))
+	m.handle(key(linux.KEY_SPACE, 0))
+	m.handle(linux.InputEvent{Type: uint16(linux.EV_KEY), Code: uint16(linux.BTN_LEFT), Value: 1})
+
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_SOUTH, 1))
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_TR2, 1))
+	mock.ExpectNoFrame(t)
+
+	m.handle(key(linux.KEY_ENTER, 0))
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_SOUTH, 0))
+
+	m.reset()
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_TR2, 0))
+	mock.ExpectNoFrame(t)
+}
+
+func TestMapper_KeyStickRamp(t *testing.T) {
+	mock := vdtest.NewDevice()
+	m := newMapper(newTestGamepad(mock), Bindings{
+		KeySticks: []KeyStick{{Side: SideLeft, Up: linux.KEY_W, Down: linux.KEY_S, Left: linux.KEY_A, Right: linux.KEY_D, Ramp: 100 * time.Millisecond}},
+	})
+
+	m.handle(key(linux.KEY_D, 1))
+	mock.ExpectNoFrame(t)
+	m.tick(50 * time.Millisecond)
+	mock.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_X, stickAxis.Denormalize(0.5)))
+	m.tick(100 * time.Millisecond)
+	mock.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_X, 32767))
+	m.tick(10 * time.Millisecond)
+	mock.ExpectNoFrame(t)
+
+	m.handle(key(linux.KEY_D, 0))
+	m.tick(time.Second)
+	mock.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_X, 0))
+}
+
+func TestMapper_KeyStickDiagonal(t *testing.T) {
+	mock := vdtest.NewDevice()
+	m := newMapper(newTestGamepad(mock), Bindings{
+		KeySticks: []KeyStick{{Side: SideRight, Up: linux.KEY_I, Down: linux.KEY_K, Left: linux.KEY_J, Right: linux.KEY_L}},
+	})
+
+	m.handle(key(linux.KEY_I, 1))
+	mock.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_RY, -32768))
+	m.handle(key(linux.KEY_J, 1))
+	diagonal := stickAxis.Denormalize(-0.70710677)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_RX, diagonal), vdtest.Abs(linux.ABS_RY, diagonal))
+}
+
+func TestMapper_Mouse(t *testing.T) {
+	mock := vdtest.NewDevice()
+	m := newMapper(newTestGamepad(mock), Bindings{
+		Mouse: &MouseStick{Side: SideRight, Sensitivity: 0.1, Decay: 10 * time.Millisecond},
+	})
+
+	// 20 counts in 4ms: 5 counts per ms, half a deflection
+	m.handle(rel(linux.REL_X, 12))
+	m.handle(rel(linux.REL_X, 8))
+	mock.ExpectNoFrame(t)
+	m.tick(4 * time.Millisecond)
+	mock.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_RX, stickAxis.Denormalize(0.5)))
+
+	// no motion: the stick decays, then centers
+	m.tick(10 * time.Millisecond)
+	if got := mock.State().AbsValue(linux.ABS_RX); got <= 0 || got >= stickAxis.Denormalize(0.5) {
+		t.Errorf("ABS_RX = %d, want a decayed deflection", got)
+	}
+	m.tick(time.Second)
+	if got := mock.State().AbsValue(linux.ABS_RX); got != 0 {
+		t.Errorf("ABS_RX = %d, want 0", got)
+	}
+
+	// a fast motion is clamped, with the direction kept
+	m.handle(rel(linux.REL_Y, -400))
+	m.tick(4 * time.Millisecond)
+	if got := mock.State().AbsValue(linux.ABS_RY); got != -32768 {
+		t.Errorf("ABS_RY = %d, want -32768", got)
+	}
+}
+
+func TestMouseStick_AccelerationAndAntiDeadzone(t *testing.T) {
+	s := &MouseStick{Sensitivity: 0.1, Acceleration: 0.2, AntiDeadzone: 0.2}
+	// 1 count per ms: 0.1 * 1 * 1.2 = 0.12, then 0.2 + 0.12 * 0.8
+	got := s.position(gamepad.Stick{}, 4, 0, 4*time.Millisecond)
+	if want := float32(0.296); got.X < want-1e-6 || got.X > want+1e-6 || got.Y != 0 {
+		t.Errorf("position = %+v, want X %v", got, want)
+	}
+}
+
+func TestBridge_StartStop(t *testing.T) {
+	keyboard := newFakeSource()
+	mouse := newFakeSource()
+	mock := vdtest.NewDevice()
+	fake := clock.NewFake(time.Now())
+
+	b := NewBridgeFactory().
+		WithKeyboard(keyboard).
+		WithMouse(mouse).
+		WithGamepad(newTestGamepad(mock)).
+		WithClock(fake).
+		Create()
+
+	if err := b.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if !keyboard.grabbed || !mouse.grabbed {
+		t.Error("expected the keyboard and the mouse to be grabbed")
+	}
+	if !mock.Registered() {
+		t.Error("expected the gamepad to be registered")
+	}
+
+	keyboard.events <- []linux.InputEvent{key(linux.KEY_SPACE, 1)}
+	deadline := time.Now().Add(time.Second)
+	for !mock.State().IsButtonPressed(linux.BTN_SOUTH) {
+		if time.Now().After(deadline) {
+			t.Fatal("KEY_SPACE did not press South")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := b.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if keyboard.grabbed || mouse.grabbed {
+		t.Error("expected the grabs to be released")
+	}
+	if mock.Registered() {
+		t.Error("expected the gamepad to be unregistered")
+	}
+	if mock.State().IsButtonPressed(linux.BTN_SOUTH) {
+		t.Error("expected South to be released")
+	}
+}
+
+func TestBridge_NoSource(t *testing.T) {
+	b := NewBridgeFactory().WithGamepad(newTestGamepad(vdtest.NewDevice())).Create()
+	if err := b.Start(); err == nil {
+		t.Error("Start should fail without keyboard and mouse")
+	}
+}
+
+func TestBridge_RestartWithClosedSource(t *testing.T) {
+	mock := vdtest.NewDevice()
+	b := NewBridgeFactory().
+		WithKeyboard(newFakeSource()).
+		WithGamepad(newTestGamepad(mock)).
+		WithClock(clock.NewFake(time.Now())).
+		Create()
+
+	if err := b.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := b.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if err := b.Start(); err == nil {
+		t.Fatal("Start should fail with a keyboard closed by Stop")
+	}
+	if mock.Registered() {
+		t.Error("the gamepad must not be registered by a failed Start")
+	}
+}
+
+func TestBridgeFactory_InvalidTickRate(t *testing.T) {
+	want := NewBridgeFactory().Create().(*bridge).tick
+	for _, hz := range []float64{0, -250, math.NaN()} {
+		if got := NewBridgeFactory().WithTickRate(hz).Create().(*bridge).tick; got != want {
+			t.Errorf("tick at %v Hz = %v, want %v", hz, got, want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+//go:build integration
+
+package bridge
+
+import (
+	"testing"
+	"time"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/utils"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+func TestIntegration_StopUnblocksReaders(t *testing.T) {
+	physical := virtual_device.NewVirtualDevice().
+		WithName("test-bridge-keyboard").
+		WithKeys([]linux.Key{linux.KEY_SPACE})
+
+	if err := physical.Register(); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	defer physical.Unregister()
+
+	if err := utils.WaitForEventFile(physical.EventPath(), 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBridgeFactory().
+		WithKeyboardPath(physical.EventPath()).
+		WithGamepad(newTestGamepad(vdtest.NewDevice())).
+		Create()
+
+	for i := 0; i < 2; i++ {
+		if err := b.Start(); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		stopped := make(chan error, 1)
+		go func() { stopped <- b.Stop() }()
+		select {
+		case err := <-stopped:
+			if err != nil {
+				t.Fatalf("Stop: %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Stop hangs on the pending ReadEvents")
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package bridge
+
+import (
+	"math"
+	"time"
+
+	"github.com/jbdemonte/virtual-device/gamepad"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// mapper applies the bindings to the gamepad: the buttons on each key event, the sticks on each tick.
+type mapper struct {
+	gamepad  gamepad.VirtualGamepad
+	bindings Bindings
+
+	keys      map[linux.Key]bool
+	pressed   map[gamepad.Button]int // several keys may be bound to a button
+	keySticks []gamepad.Stick
+	mouseX    int32
+	mouseY    int32
+	mouse     gamepad.Stick
+	sent      [2]gamepad.Stick
+}
+
+func newMapper(gp gamepad.VirtualGamepad, bindings Bindings) *mapper {
+	return &mapper{
+		gamepad:   gp,
+		bindings:  bindings,
+		keys:      map[linux.Key]bool{},
+		pressed:   map[gamepad.Button]int{},
+		keySticks: make([]gamepad.Stick, len(bindings.KeySticks)),
+	}
+}
+
+func (m *mapper) handle(event linux.InputEvent) {
+	switch linux.EventType(event.Type) {
+	case linux.EV_KEY:
+		if event.Value == 2 {
+			return // auto-repeat
+		}
+		pressed := event.Value == 1
+		m.keys[linux.Key(event.Code)] = pressed
+		if button, ok := m.bindings.Keys[linux.Key(event.Code)]; ok {
+			m.setButton(button, pressed)
+		}
+		if button, ok := m.bindings.MouseButtons[linux.Button(event.Code)]; ok {
+			m.setButton(button, pressed)
+		}
+		for i, stick := range m.bindings.KeySticks {
+			if stick.Ramp <= 0 {
+				m.keySticks[i] = m.target(stick)
+			}
+		}
+		m.sendSticks()
+	case linux.EV_REL:
+		switch linux.RelativeAxis(event.Code) {
+		case linux.REL_X:
+			m.mouseX += event.Value
+		case linux.REL_Y:
+			m.mouseY += event.Value
+		}
+	}
+}
+
+func (m *mapper) setButton(button gamepad.Button, pressed bool) {
+	if pressed {
+		m.pressed[button]++
+		if m.pressed[button] == 1 {
+			m.gamepad.Press(button)
+		}
+		return
+	}
+	if m.pressed[button] == 0 {
+		return // pressed before the start of the bridge
+	}
+	m.pressed[button]--
+	if m.pressed[button] == 0 {
+		m.gamepad.Release(button)
+	}
+}
+
+// target returns the position of a key stick for the pressed keys.
+func (m *mapper) target(stick KeyStick) gamepad.Stick {
+	var x, y float32
+	if m.keys[stick.Left] {
+		x--
+	}
+	if m.keys[stick.Right] {
+		x++
+	}
+	if m.keys[stick.Up] {
+		y--
+	}
+	if m.keys[stick.Down] {
+		y++
+	}
+	if x != 0 && y != 0 {
+		x *= math.Sqrt2 / 2
+		y *= math.Sqrt2 / 2
+	}
+	return gamepad.Stick{X: x, Y: y}
+}
+
+// tick moves the ramping key sticks and the mouse stick by the time elapsed since the previous tick.
+func (m *mapper) tick(elapsed time.Duration) {
+	if elapsed <= 0 {
+		return
+	}
+	for i, stick := range m.bindings.KeySticks {
+		if stick.Ramp > 0 {
+			m.keySticks[i] = moveToward(m.keySticks[i], m.target(stick), float32(elapsed)/float32(stick.Ramp))
+		}
+	}
+	if mouse := m.bindings.Mouse; mouse != nil {
+		m.mouse = mouse.position(m.mouse, m.mouseX, m.mouseY, elapsed)
+	}
+	m.mouseX, m.mouseY = 0, 0
+	m.sendSticks()
+}
+
+// position returns the position of the stick for a mouse motion of dx, dy counts during elapsed.
+func (s *MouseStick) position(current gamepad.Stick, dx, dy int32, elapsed time.Duration) gamepad.Stick {
+	if dx == 0 && dy == 0 {
+		if s.Decay <= 0 {
+			return gamepad.Stick{}
+		}
+		factor := float32(math.Exp(-float64(elapsed) / float64(s.Decay)))
+		current = gamepad.Stick{X: current.X * factor, Y: current.Y * factor}
+		if math.Hypot(float64(current.X), float64(current.Y)) < 0.01 {
+			return gamepad.Stick{}
+		}
+		return current
+	}
+	ms := float64(elapsed) / float64(time.Millisecond)
+	vx, vy := float64(dx)/ms, float64(dy)/ms
+	speed := math.Hypot(vx, vy)
+	deflection := s.Sensitivity * speed * (1 + s.Acceleration*speed)
+	deflection = float64(s.AntiDeadzone) + deflection*(1-float64(s.AntiDeadzone))
+	if deflection > 1 {
+		deflection = 1
+	}
+	return gamepad.Stick{X: float32(vx / speed * deflection), Y: float32(vy / speed * deflection)}
+}
+
+// moveToward moves a stick toward the target by a distance of at most step.
+func moveToward(current, target gamepad.Stick, step float32) gamepad.Stick {
+	dx, dy := target.X-current.X, target.Y-current.Y
+	distance := float32(math.Hypot(float64(dx), float64(dy)))
+	if distance <= step {
+		return target
+	}
+	return gamepad.Stick{X: current.X + dx/distance*step, Y: current.Y + dy/distance*step}
+}
+
+// sendSticks sums the key sticks and the mouse of each side, and moves the sticks which changed.
+func (m *mapper) sendSticks() {
+	var sticks [2]gamepad.Stick
+	for i, stick := range m.bindings.KeySticks {
+		sticks[stick.Side].X += m.keySticks[i].X
+		sticks[stick.Side].Y += m.keySticks[i].Y
+	}
+	if mouse := m.bindings.Mouse; mouse != nil {
+		sticks[mouse.Side].X += m.mouse.X
+		sticks[mouse.Side].Y += m.mouse.Y
+	}
+	for side := range sticks {
+		stick := gamepad.Stick{X: clamp(sticks[side].X), Y: clamp(sticks[side].Y)}
+		if stick == m.sent[side] {
+			continue
+		}
+		m.sent[side] = stick
+		if Side(side) == SideLeft {
+			m.gamepad.MoveLeftStick(stick.X, stick.Y)
+		} else {
+			m.gamepad.MoveRightStick(stick.X, stick.Y)
+		}
+	}
+}
+
+func clamp(value float32) float32 {
+	return float32(math.Max(-1, math.Min(1, float64(value))))
+}
+
+// reset releases the buttons held by the bridge and centers its sticks.
+func (m *mapper) reset() {
+	for button, count := range m.pressed {
+		if count > 0 {
+			m.gamepad.Release(button)
+		}
+	}
+	m.keys = map[linux.Key]bool{}
+	m.pressed = map[gamepad.Button]int{}
+	m.keySticks = make([]gamepad.Stick, len(m.bindings.KeySticks))
+	m.mouseX, m.mouseY = 0, 0
+	m.mouse = gamepad.Stick{}
+	m.sendSticks()
+}
@@ -0,0 +1,201 @@
+package clock
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock abstracts the passing of time so that timed helpers (clicks, taps, typing) can be driven by tests.
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer fires once on C after its duration. Stop releases a timer that is no longer waited for.
+type Timer interface {
+	C() <-chan time.Time
+	// Stop prevents the timer from firing, returning false if it already fired or was stopped.
+	Stop() bool
+}
+
+// New returns a Clock backed by the time package.
+func New() Clock {
+	return realClock{}
+}
+
+// SleepContext pauses for d on the given clock, returning ctx.Err() early if ctx is done first.
+func SleepContext(ctx context.Context, c Clock, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d <= 0 {
+		return nil
+	}
+	timer := c.NewTimer(d)
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		timer.Stop()
+		return ctx.Err()
+	}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// Fake is a Clock whose time only moves when Advance is called.
+// Sleep blocks until the fake time reaches the deadline.
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*waiter
+	changed chan struct{}
+}
+
+type waiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewFake returns a Fake clock starting at start.
+func NewFake(start time.Time) *Fake {
+	return &Fake{
+		now:     start,
+		changed: make(chan struct{}),
+	}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *Fake) Sleep(d time.Duration) {
+	<-f.After(d)
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// NewTimer returns a timer firing once Advance reaches its deadline. A stopped timer no longer counts as a sleeper.
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w := &waiter{deadline: f.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.ch <- f.now
+		return &fakeTimer{fake: f, waiter: w}
+	}
+	f.waiters = append(f.waiters, w)
+	f.notify()
+	return &fakeTimer{fake: f, waiter: w}
+}
+
+type fakeTimer struct {
+	fake   *Fake
+	waiter *waiter
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.waiter.ch
+}
+
+func (t *fakeTimer) Stop() bool {
+	f := t.fake
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, w := range f.waiters {
+		if w == t.waiter {
+			f.waiters = append(f.waiters[:i], f.waiters[i+1:]...)
+			f.notify()
+			return true
+		}
+	}
+	return false
+}
+
+// Advance moves the time forward, waking up the sleepers whose deadline is reached, earliest first.
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+
+	sort.SliceStable(f.waiters, func(i, j int) bool {
+		return f.waiters[i].deadline.Before(f.waiters[j].deadline)
+	})
+
+	remaining := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.deadline.After(f.now) {
+			remaining = append(remaining, w)
+			continue
+		}
+		w.ch <- w.deadline
+	}
+	f.waiters = remaining
+	f.notify()
+}
+
+// Sleepers returns the number of pending Sleep, After and timers, the stopped timers excluded.
+func (f *Fake) Sleepers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.waiters)
+}
+
+// BlockUntil waits until at least count sleepers are pending.
+func (f *Fake) BlockUntil(count int) {
+	for {
+		f.mu.Lock()
+		if len(f.waiters) >= count {
+			f.mu.Unlock()
+			return
+		}
+		changed := f.changed
+		f.mu.Unlock()
+		<-changed
+	}
+}
+
+// notify wakes up BlockUntil, must be called with the lock held.
+func (f *Fake) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
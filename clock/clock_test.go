@@ -0,0 +1,142 @@
+package clock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var epoch = time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC)
+
+func TestFake_Now(t *testing.T) {
+	f := NewFake(epoch)
+	if !f.Now().Equal(epoch) {
+		t.Errorf("Now = %v, want %v", f.Now(), epoch)
+	}
+	f.Advance(time.Second)
+	if !f.Now().Equal(epoch.Add(time.Second)) {
+		t.Errorf("Now = %v, want %v", f.Now(), epoch.Add(time.Second))
+	}
+}
+
+func TestFake_SleepBlocksUntilAdvance(t *testing.T) {
+	f := NewFake(epoch)
+	done := make(chan struct{})
+
+	go func() {
+		f.Sleep(50 * time.Millisecond)
+		close(done)
+	}()
+
+	f.BlockUntil(1)
+	f.Advance(49 * time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("Sleep returned before its deadline")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	f.Advance(time.Millisecond)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Sleep did not return after its deadline")
+	}
+	if f.Sleepers() != 0 {
+		t.Errorf("Sleepers = %d, want 0", f.Sleepers())
+	}
+}
+
+func TestFake_AfterZero(t *testing.T) {
+	f := NewFake(epoch)
+	select {
+	case now := <-f.After(0):
+		if !now.Equal(epoch) {
+			t.Errorf("After(0) = %v, want %v", now, epoch)
+		}
+	default:
+		t.Fatal("After(0) must fire immediately")
+	}
+}
+
+func TestFake_AdvanceFiresInOrder(t *testing.T) {
+	f := NewFake(epoch)
+	late := f.After(2 * time.Second)
+	early := f.After(time.Second)
+
+	f.Advance(3 * time.Second)
+
+	if got := <-early; !got.Equal(epoch.Add(time.Second)) {
+		t.Errorf("early fired at %v", got)
+	}
+	if got := <-late; !got.Equal(epoch.Add(2 * time.Second)) {
+		t.Errorf("late fired at %v", got)
+	}
+}
+
+func TestFake_TimerStop(t *testing.T) {
+	f := NewFake(epoch)
+	timer := f.NewTimer(time.Second)
+	if f.Sleepers() != 1 {
+		t.Fatalf("Sleepers = %d, want 1", f.Sleepers())
+	}
+	if !timer.Stop() {
+		t.Error("Stop = false, want true on a pending timer")
+	}
+	if f.Sleepers() != 0 {
+		t.Errorf("Sleepers = %d, want 0 once stopped", f.Sleepers())
+	}
+	f.Advance(time.Second)
+	select {
+	case <-timer.C():
+		t.Error("a stopped timer must not fire")
+	default:
+	}
+	if timer.Stop() {
+		t.Error("Stop = true, want false on a stopped timer")
+	}
+}
+
+func TestReal_Sleep(t *testing.T) {
+	c := New()
+	start := c.Now()
+	c.Sleep(time.Millisecond)
+	if c.Now().Sub(start) < time.Millisecond {
+		t.Error("real clock did not sleep")
+	}
+}
+
+func TestSleepContext_Cancelled(t *testing.T) {
+	f := NewFake(epoch)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() {
+		done <- SleepContext(ctx, f, time.Second)
+	}()
+
+	f.BlockUntil(1)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("SleepContext = %v, want context.Canceled", err)
+	}
+	if f.Sleepers() != 0 {
+		t.Errorf("Sleepers = %d, want 0 once cancelled", f.Sleepers())
+	}
+}
+
+func TestSleepContext_Elapsed(t *testing.T) {
+	f := NewFake(epoch)
+	done := make(chan error)
+
+	go func() {
+		done <- SleepContext(context.Background(), f, time.Second)
+	}()
+
+	f.BlockUntil(1)
+	f.Advance(time.Second)
+	if err := <-done; err != nil {
+		t.Errorf("SleepContext = %v, want nil", err)
+	}
+}
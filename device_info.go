@@ -0,0 +1,195 @@
+package virtual_device
+
+import (
+	"fmt"
+	"math/bits"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+const sysInputDir = "/sys/devices/virtual/input/"
+
+// DeviceInfo describes a registered device as the kernel exposes it through sysfs.
+type DeviceInfo struct {
+	SysName      string // e.g. "input42"
+	SysPath      string // e.g. "/sys/devices/virtual/input/input42"
+	EventPath    string // e.g. "/dev/input/event7"
+	JoystickPath string // e.g. "/dev/input/js0", empty when joydev did not bind the device
+	Name         string
+	Phys         string
+	Uniq         string
+	ID           linux.InputID
+	Capabilities Capabilities
+}
+
+// Capabilities lists the event codes reported by the kernel for a device.
+type Capabilities struct {
+	Events       []linux.EventType
+	Keys         []linux.Key // keys and buttons share the same bitmap
+	RelAxes      []linux.RelativeAxis
+	AbsAxes      []linux.AbsoluteAxis
+	MiscEvents   []linux.MiscEvent
+	LEDs         []linux.Led
+	Sounds       []linux.Sound
+	ForceEffects []linux.FFEffectType
+	Switches     []linux.SwitchEvent
+	Properties   []linux.InputProp
+}
+
+// HasEvent reports whether the event type is supported.
+func (c Capabilities) HasEvent(evType linux.EventType) bool {
+	return contains(c.Events, evType)
+}
+
+// HasKey reports whether the key is supported.
+func (c Capabilities) HasKey(key linux.Key) bool {
+	return contains(c.Keys, key)
+}
+
+// HasButton reports whether the button is supported.
+func (c Capabilities) HasButton(button linux.Button) bool {
+	return contains(c.Keys, linux.Key(button))
+}
+
+// HasAbsAxis reports whether the absolute axis is supported.
+func (c Capabilities) HasAbsAxis(axis linux.AbsoluteAxis) bool {
+	return contains(c.AbsAxes, axis)
+}
+
+// HasRelAxis reports whether the relative axis is supported.
+func (c Capabilities) HasRelAxis(axis linux.RelativeAxis) bool {
+	return contains(c.RelAxes, axis)
+}
+
+// HasProperty reports whether the input property is set.
+func (c Capabilities) HasProperty(prop linux.InputProp) bool {
+	return contains(c.Properties, prop)
+}
+
+func contains[T comparable](items []T, item T) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+func (vd *virtualDevice) DeviceInfo() (DeviceInfo, error) {
+	if !vd.isRegistered.Get() || vd.sysName == "" {
+		return DeviceInfo{}, fmt.Errorf("device is not registered")
+	}
+	return readDeviceInfo(sysInputDir + vd.sysName)
+}
+
+// readDeviceInfo builds a DeviceInfo from a sysfs input directory.
+func readDeviceInfo(sysPath string) (DeviceInfo, error) {
+	info := DeviceInfo{
+		SysName: filepath.Base(sysPath),
+		SysPath: sysPath,
+	}
+
+	files, err := os.ReadDir(sysPath)
+	if err != nil {
+		return info, fmt.Errorf("unable to read directory %s: %v", sysPath, err)
+	}
+
+	for _, file := range files {
+		name := file.Name()
+		if strings.HasPrefix(name, "event") && info.EventPath == "" {
+			info.EventPath = "/dev/input/" + name
+		}
+		if strings.HasPrefix(name, "js") && info.JoystickPath == "" {
+			info.JoystickPath = "/dev/input/" + name
+		}
+	}
+
+	info.Name = readSysString(sysPath, "name")
+	info.Phys = readSysString(sysPath, "phys")
+	info.Uniq = readSysString(sysPath, "uniq")
+
+	info.ID.BusType = linux.BusType(readSysHex(sysPath, "id/bustype"))
+	info.ID.Vendor = readSysHex(sysPath, "id/vendor")
+	info.ID.Product = readSysHex(sysPath, "id/product")
+	info.ID.Version = readSysHex(sysPath, "id/version")
+
+	caps := &info.Capabilities
+	steps := []struct {
+		file string
+		set  func(codes []uint16)
+	}{
+		{"capabilities/ev", func(codes []uint16) { caps.Events = convertCodes[linux.EventType](codes) }},
+		{"capabilities/key", func(codes []uint16) { caps.Keys = convertCodes[linux.Key](codes) }},
+		{"capabilities/rel", func(codes []uint16) { caps.RelAxes = convertCodes[linux.RelativeAxis](codes) }},
+		{"capabilities/abs", func(codes []uint16) { caps.AbsAxes = convertCodes[linux.AbsoluteAxis](codes) }},
+		{"capabilities/msc", func(codes []uint16) { caps.MiscEvents = convertCodes[linux.MiscEvent](codes) }},
+		{"capabilities/led", func(codes []uint16) { caps.LEDs = convertCodes[linux.Led](codes) }},
+		{"capabilities/snd", func(codes []uint16) { caps.Sounds = convertCodes[linux.Sound](codes) }},
+		{"capabilities/ff", func(codes []uint16) { caps.ForceEffects = convertCodes[linux.FFEffectType](codes) }},
+		{"capabilities/sw", func(codes []uint16) { caps.Switches = convertCodes[linux.SwitchEvent](codes) }},
+		{"properties", func(codes []uint16) { caps.Properties = convertCodes[linux.InputProp](codes) }},
+	}
+
+	for _, step := range steps {
+		content, err := os.ReadFile(filepath.Join(sysPath, step.file))
+		if err != nil {
+			continue // not every kernel exposes every bitmap
+		}
+		codes, err := parseBitmap(string(content))
+		if err != nil {
+			return info, fmt.Errorf("unable to parse %s: %v", step.file, err)
+		}
+		step.set(codes)
+	}
+
+	return info, nil
+}
+
+func readSysString(sysPath, file string) string {
+	content, err := os.ReadFile(filepath.Join(sysPath, file))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+func readSysHex(sysPath, file string) uint16 {
+	value, err := strconv.ParseUint(readSysString(sysPath, file), 16, 16)
+	if err != nil {
+		return 0
+	}
+	return uint16(value)
+}
+
+// parseBitmap decodes a sysfs bitmap ("120013 0 fffe"): space separated
+// hexadecimal longs, most significant word first.
+func parseBitmap(content string) ([]uint16, error) {
+	words := strings.Fields(content)
+	codes := make([]uint16, 0)
+
+	for i := len(words) - 1; i >= 0; i-- {
+		word, err := strconv.ParseUint(words[i], 16, bits.UintSize)
+		if err != nil {
+			return nil, err
+		}
+		offset := (len(words) - 1 - i) * bits.UintSize
+		for bit := 0; bit < bits.UintSize; bit++ {
+			if word&(1<<uint(bit)) != 0 {
+				codes = append(codes, uint16(offset+bit))
+			}
+		}
+	}
+	return codes, nil
+}
+
+func convertCodes[T ~uint16](codes []uint16) []T {
+	result := make([]T, len(codes))
+	for i, code := range codes {
+		result[i] = T(code)
+	}
+	return result
+}
@@ -0,0 +1,141 @@
+package virtual_device
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+func TestParseBitmap_SingleWord(t *testing.T) {
+	codes, err := parseBitmap("120013\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uint16{0, 1, 4, 17, 20}
+	if !reflect.DeepEqual(codes, want) {
+		t.Errorf("got %v, want %v", codes, want)
+	}
+}
+
+func TestParseBitmap_MultipleWords(t *testing.T) {
+	// most significant word first: bit 0 of the first word is code 128
+	codes, err := parseBitmap("1 0 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []uint16{1, 128}
+	if !reflect.DeepEqual(codes, want) {
+		t.Errorf("got %v, want %v", codes, want)
+	}
+}
+
+func TestParseBitmap_Empty(t *testing.T) {
+	codes, err := parseBitmap("0\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 0 {
+		t.Errorf("expected no code, got %v", codes)
+	}
+}
+
+func TestParseBitmap_Invalid(t *testing.T) {
+	if _, err := parseBitmap("zz"); err == nil {
+		t.Error("expected error for invalid bitmap")
+	}
+}
+
+func writeSysFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadDeviceInfo(t *testing.T) {
+	sysPath := filepath.Join(t.TempDir(), "input42")
+
+	writeSysFile(t, sysPath, "name", "Microsoft X-Box 360 pad\n")
+	writeSysFile(t, sysPath, "phys", "usb-0000:00:14.0-1/input0\n")
+	writeSysFile(t, sysPath, "uniq", "\n")
+	writeSysFile(t, sysPath, "id/bustype", "0003\n")
+	writeSysFile(t, sysPath, "id/vendor", "045e\n")
+	writeSysFile(t, sysPath, "id/product", "028e\n")
+	writeSysFile(t, sysPath, "id/version", "0114\n")
+	writeSysFile(t, sysPath, "capabilities/ev", "b\n")
+	writeSysFile(t, sysPath, "capabilities/key", "7cdb000000000000 0 0 0 0\n")
+	writeSysFile(t, sysPath, "capabilities/abs", "3003f\n")
+	writeSysFile(t, sysPath, "properties", "0\n")
+	writeSysFile(t, sysPath, "event7/dev", "13:71\n")
+	writeSysFile(t, sysPath, "js0/dev", "13:0\n")
+
+	info, err := readDeviceInfo(sysPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if info.SysName != "input42" {
+		t.Errorf("SysName = %q, want input42", info.SysName)
+	}
+	if info.EventPath != "/dev/input/event7" {
+		t.Errorf("EventPath = %q, want /dev/input/event7", info.EventPath)
+	}
+	if info.JoystickPath != "/dev/input/js0" {
+		t.Errorf("JoystickPath = %q, want /dev/input/js0", info.JoystickPath)
+	}
+	if info.Name != "Microsoft X-Box 360 pad" {
+		t.Errorf("Name = %q", info.Name)
+	}
+	if info.Phys != "usb-0000:00:14.0-1/input0" || info.Uniq != "" {
+		t.Errorf("Phys = %q, Uniq = %q", info.Phys, info.Uniq)
+	}
+
+	wantID := linux.InputID{BusType: linux.BUS_USB, Vendor: 0x045e, Product: 0x028e, Version: 0x0114}
+	if info.ID != wantID {
+		t.Errorf("ID = %+v, want %+v", info.ID, wantID)
+	}
+
+	caps := info.Capabilities
+	for _, evType := range []linux.EventType{linux.EV_SYN, linux.EV_KEY, linux.EV_ABS} {
+		if !caps.HasEvent(evType) {
+			t.Errorf("expected event type 0x%x", evType)
+		}
+	}
+	for _, button := range []linux.Button{linux.BTN_SOUTH, linux.BTN_EAST, linux.BTN_MODE, linux.BTN_THUMBR} {
+		if !caps.HasButton(button) {
+			t.Errorf("expected button 0x%x", button)
+		}
+	}
+	for _, axis := range []linux.AbsoluteAxis{linux.ABS_X, linux.ABS_RZ, linux.ABS_HAT0X, linux.ABS_HAT0Y} {
+		if !caps.HasAbsAxis(axis) {
+			t.Errorf("expected axis 0x%x", axis)
+		}
+	}
+	if caps.HasRelAxis(linux.REL_X) {
+		t.Error("unexpected relative axis")
+	}
+	if len(caps.Properties) != 0 {
+		t.Errorf("expected no property, got %v", caps.Properties)
+	}
+}
+
+func TestReadDeviceInfo_MissingDirectory(t *testing.T) {
+	_, err := readDeviceInfo(filepath.Join(t.TempDir(), "input0"))
+	if err == nil {
+		t.Error("expected error for missing sysfs directory")
+	}
+}
+
+func TestDeviceInfo_NotRegistered(t *testing.T) {
+	_, err := NewVirtualDevice().DeviceInfo()
+	if err == nil {
+		t.Error("expected error for unregistered device")
+	}
+}
@@ -0,0 +1,264 @@
+package dsu
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jbdemonte/virtual-device/gamepad"
+	"github.com/jbdemonte/virtual-device/imu"
+)
+
+// Client subscribes to the controller slots of a DSU server and drives virtual devices from their reports.
+// The bound devices are not registered by the client.
+type Client interface {
+	Start() error
+	Stop() error
+}
+
+// ClientFactory configures and creates Client instances.
+type ClientFactory interface {
+	WithServer(address string) ClientFactory
+	WithClientID(id uint32) ClientFactory
+	WithRequestInterval(interval time.Duration) ClientFactory
+	WithIMU(slot uint8, imu imu.VirtualIMU) ClientFactory
+	WithGamepad(slot uint8, gamepad gamepad.VirtualGamepad) ClientFactory
+	Create() Client
+}
+
+// DefaultRequestInterval is the delay between two subscriptions, servers dropping the clients silent for 5 seconds.
+const DefaultRequestInterval = time.Second
+
+// NewClientFactory returns a new factory for building DSU clients.
+func NewClientFactory() ClientFactory {
+	return &clientFactory{
+		address:  DefaultServerAddress,
+		clientID: rand.Uint32(),
+		interval: DefaultRequestInterval,
+		slots:    map[uint8]*binding{},
+	}
+}
+
+type clientFactory struct {
+	address  string
+	clientID uint32
+	interval time.Duration
+	slots    map[uint8]*binding
+}
+
+// binding holds the devices driven by a slot and what was last applied to them.
+type binding struct {
+	imu     imu.VirtualIMU
+	gamepad gamepad.VirtualGamepad
+
+	received        bool
+	serverID        uint32
+	packetNumber    uint32
+	motionTimestamp uint64
+	connected       bool
+}
+
+func (f *clientFactory) WithServer(address string) ClientFactory {
+	f.address = address
+	return f
+}
+
+func (f *clientFactory) WithClientID(id uint32) ClientFactory {
+	f.clientID = id
+	return f
+}
+
+func (f *clientFactory) WithRequestInterval(interval time.Duration) ClientFactory {
+	f.interval = interval
+	return f
+}
+
+func (f *clientFactory) WithIMU(slot uint8, imu imu.VirtualIMU) ClientFactory {
+	f.slot(slot).imu = imu
+	return f
+}
+
+func (f *clientFactory) WithGamepad(slot uint8, gamepad gamepad.VirtualGamepad) ClientFactory {
+	f.slot(slot).gamepad = gamepad
+	return f
+}
+
+func (f *clientFactory) slot(slot uint8) *binding {
+	if f.slots[slot] == nil {
+		f.slots[slot] = &binding{}
+	}
+	return f.slots[slot]
+}
+
+func (f *clientFactory) Create() Client {
+	slots := map[uint8]*binding{}
+	for slot, b := range f.slots {
+		copied := *b
+		slots[slot] = &copied
+	}
+	return &client{
+		address:  f.address,
+		clientID: f.clientID,
+		interval: f.interval,
+		slots:    slots,
+	}
+}
+
+type client struct {
+	address  string
+	clientID uint32
+	interval time.Duration
+	slots    map[uint8]*binding
+
+	mu      sync.Mutex
+	running bool
+	conn    *net.UDPConn
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// Start connects to the server and subscribes to the bound slots.
+func (c *client) Start() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.running {
+		return nil
+	}
+	for slot := range c.slots {
+		if slot >= MaxSlots {
+			return fmt.Errorf("invalid DSU slot %d, the server exposes %d slots", slot, MaxSlots)
+		}
+	}
+
+	address, err := net.ResolveUDPAddr("udp", c.address)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialUDP("udp", nil, address)
+	if err != nil {
+		return err
+	}
+
+	c.conn = conn
+	c.stop = make(chan struct{})
+	c.running = true
+
+	c.wg.Add(2)
+	go c.subscribe(conn, c.stop)
+	go c.receive(conn)
+
+	return nil
+}
+
+// Stop closes the connection, the bound devices keep their last state.
+func (c *client) Stop() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.running {
+		return nil
+	}
+	c.running = false
+
+	close(c.stop)
+	err := c.conn.Close()
+	c.wg.Wait()
+	return err
+}
+
+// subscribe sends the data requests of the bound slots until stop is closed.
+func (c *client) subscribe(conn *net.UDPConn, stop chan struct{}) {
+	defer c.wg.Done()
+
+	slots := make([]uint8, 0, len(c.slots))
+	for slot := range c.slots {
+		slots = append(slots, slot)
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i] < slots[j] })
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		for _, slot := range slots {
+			// the server may not be up yet, the next tick tries again
+			_, _ = conn.Write(EncodeDataRequest(c.clientID, slot))
+		}
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// receive applies the controller data until the connection is closed.
+func (c *client) receive(conn *net.UDPConn) {
+	defer c.wg.Done()
+
+	buffer := make([]byte, 1024)
+	for {
+		n, err := conn.Read(buffer)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			// ICMP port unreachable while the server is down
+			continue
+		}
+		data, err := DecodeControllerData(buffer[:n])
+		if err != nil {
+			// corrupted packets and the other message types
+			continue
+		}
+		if b, exists := c.slots[data.Slot]; exists {
+			b.apply(data)
+		}
+	}
+}
+
+// restartWindow is how far back a packet number may go before the server is considered restarted,
+// reordered UDP packets staying much closer.
+const restartWindow = 1024
+
+// apply drives the devices of a slot, ignoring the packets older than the last one applied.
+// A new server ID or a packet number far behind the last one comes from a restarted server, and starts over.
+func (b *binding) apply(data ControllerData) {
+	if b.received && data.ServerID == b.serverID {
+		if delta := int32(data.PacketNumber - b.packetNumber); delta <= 0 && delta > -restartWindow {
+			return
+		}
+	}
+	b.received = true
+	b.serverID = data.ServerID
+	b.packetNumber = data.PacketNumber
+
+	connected := data.Connected && data.State == SlotConnected
+	if !connected {
+		if b.connected && b.gamepad != nil {
+			b.gamepad.SetState(gamepad.GamepadState{})
+		}
+		b.connected = false
+		return
+	}
+	b.connected = true
+
+	if b.imu != nil && data.MotionTimestamp != b.motionTimestamp {
+		b.motionTimestamp = data.MotionTimestamp
+		var accel, gyro [3]float64
+		for i := 0; i < 3; i++ {
+			accel[i] = float64(data.Accelerometer[i]) * imu.StandardGravity
+			gyro[i] = float64(data.Gyroscope[i])
+		}
+		b.imu.SetMotion(accel, gyro)
+	}
+
+	if b.gamepad != nil {
+		b.gamepad.SetState(GamepadState(data))
+	}
+}
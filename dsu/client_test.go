@@ -0,0 +1,246 @@
+package dsu
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/gamepad"
+	"github.com/jbdemonte/virtual-device/imu"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+// server is a local stand-in for a DSU server.
+type server struct {
+	t      *testing.T
+	conn   *net.UDPConn
+	client *net.UDPAddr
+	id     uint32
+}
+
+func newServer(t *testing.T) *server {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &server{t: t, conn: conn, id: 1}
+}
+
+func (s *server) address() string {
+	return s.conn.LocalAddr().String()
+}
+
+// expectRequest waits for a data request and remembers the client address.
+func (s *server) expectRequest(slot uint8) {
+	s.t.Helper()
+	buffer := make([]byte, 1024)
+	_ = s.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, address, err := s.conn.ReadFromUDP(buffer)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	got, err := DecodeDataRequest(buffer[:n])
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	if got != slot {
+		s.t.Fatalf("requested slot = %d, want %d", got, slot)
+	}
+	s.client = address
+}
+
+func (s *server) send(data ControllerData) {
+	s.t.Helper()
+	if _, err := s.conn.WriteToUDP(EncodeControllerData(s.id, data), s.client); err != nil {
+		s.t.Fatal(err)
+	}
+}
+
+// waitFrames waits until the device recorded count frames.
+func waitFrames(t *testing.T, device *vdtest.Device, count int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(device.Frames()) < count {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %d frames, got %d", count, len(device.Frames()))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func newMockGamepad() (gamepad.VirtualGamepad, *vdtest.Device) {
+	device := vdtest.NewDevice()
+	gp := gamepad.NewVirtualGamepadFactory().
+		WithDevice(device).
+		WithDigital(gamepad.MappingDigital{
+			gamepad.ButtonSouth: linux.BTN_SOUTH,
+			gamepad.ButtonR2:    virtual_device.AbsAxis{Axis: linux.ABS_RZ, Min: 0, Max: 255},
+		}).
+		WithLeftStick(gamepad.MappingStick{
+			X: virtual_device.AbsAxis{Axis: linux.ABS_X, Min: -127, Max: 127},
+			Y: virtual_device.AbsAxis{Axis: linux.ABS_Y, Min: -127, Max: 127},
+		}).
+		Create()
+	return gp, device
+}
+
+func TestClient_DrivesSlot(t *testing.T) {
+	srv := newServer(t)
+	imuDevice := vdtest.NewDevice()
+	motion := imu.NewVirtualIMUFactory().WithDevice(imuDevice).WithAxes(imu.PlayStationAxes()).Create()
+	gp, padDevice := newMockGamepad()
+
+	c := NewClientFactory().
+		WithServer(srv.address()).
+		WithIMU(1, motion).
+		WithGamepad(1, gp).
+		Create()
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+
+	srv.expectRequest(1)
+	srv.send(ControllerData{Slot: 0, State: SlotConnected, Connected: true, PacketNumber: 1, Buttons: ButtonCross})
+	srv.send(ControllerData{
+		Slot:            1,
+		State:           SlotConnected,
+		Connected:       true,
+		PacketNumber:    1,
+		Buttons:         ButtonCross,
+		LeftX:           255,
+		LeftY:           128,
+		RightX:          128,
+		RightY:          128,
+		AnalogR2:        255,
+		MotionTimestamp: 1000,
+		Accelerometer:   [3]float32{0, 1, 0},
+		Gyroscope:       [3]float32{0, 90, 0},
+	})
+
+	waitFrames(t, imuDevice, 1)
+	imuDevice.ExpectFrameContaining(t,
+		vdtest.Abs(linux.ABS_Y, 8192),
+		vdtest.Abs(linux.ABS_RY, 92160),
+	)
+
+	waitFrames(t, padDevice, 1)
+	padDevice.ExpectFrameContaining(t,
+		vdtest.Button(linux.BTN_SOUTH, 1),
+		vdtest.Abs(linux.ABS_RZ, 255),
+		vdtest.Abs(linux.ABS_X, 127),
+	)
+}
+
+func TestClient_IgnoresStalePackets(t *testing.T) {
+	srv := newServer(t)
+	imuDevice := vdtest.NewDevice()
+	motion := imu.NewVirtualIMUFactory().WithDevice(imuDevice).WithAxes(imu.PlayStationAxes()).Create()
+
+	c := NewClientFactory().WithServer(srv.address()).WithIMU(0, motion).Create()
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+
+	srv.expectRequest(0)
+	connected := ControllerData{State: SlotConnected, Connected: true}
+
+	first := connected
+	first.PacketNumber, first.MotionTimestamp, first.Accelerometer = 5, 100, [3]float32{1, 0, 0}
+	stale := connected
+	stale.PacketNumber, stale.MotionTimestamp, stale.Accelerometer = 4, 200, [3]float32{0, 0, 1}
+	repeated := connected
+	repeated.PacketNumber, repeated.MotionTimestamp, repeated.Accelerometer = 6, 100, [3]float32{0, 1, 0}
+	last := connected
+	last.PacketNumber, last.MotionTimestamp, last.Accelerometer = 7, 300, [3]float32{0, -1, 0}
+
+	for _, data := range []ControllerData{first, stale, repeated, last} {
+		srv.send(data)
+	}
+
+	waitFrames(t, imuDevice, 2)
+	imuDevice.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_X, 8192))
+	imuDevice.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_Y, -8192))
+	time.Sleep(10 * time.Millisecond)
+	imuDevice.ExpectNoFrame(t)
+}
+
+func TestClient_ServerRestart(t *testing.T) {
+	srv := newServer(t)
+	imuDevice := vdtest.NewDevice()
+	motion := imu.NewVirtualIMUFactory().WithDevice(imuDevice).WithAxes(imu.PlayStationAxes()).Create()
+
+	c := NewClientFactory().WithServer(srv.address()).WithIMU(0, motion).Create()
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+
+	srv.expectRequest(0)
+	data := ControllerData{State: SlotConnected, Connected: true}
+
+	data.PacketNumber, data.MotionTimestamp, data.Accelerometer = 5000, 100, [3]float32{1, 0, 0}
+	srv.send(data)
+	waitFrames(t, imuDevice, 1)
+
+	// the same server counting again from 0
+	data.PacketNumber, data.MotionTimestamp, data.Accelerometer = 1, 200, [3]float32{0, 1, 0}
+	srv.send(data)
+	waitFrames(t, imuDevice, 2)
+
+	// another server, its counter being behind
+	srv.id = 2
+	data.PacketNumber, data.MotionTimestamp, data.Accelerometer = 0, 300, [3]float32{0, 0, 1}
+	srv.send(data)
+	waitFrames(t, imuDevice, 3)
+
+	imuDevice.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_X, 8192))
+	imuDevice.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_Y, 8192))
+	imuDevice.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_Z, 8192))
+}
+
+func TestClient_Disconnect(t *testing.T) {
+	srv := newServer(t)
+	gp, padDevice := newMockGamepad()
+
+	c := NewClientFactory().WithServer(srv.address()).WithGamepad(0, gp).Create()
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+
+	srv.expectRequest(0)
+	srv.send(ControllerData{State: SlotConnected, Connected: true, PacketNumber: 1, Buttons: ButtonCross, LeftX: 128, LeftY: 128})
+	srv.send(ControllerData{State: SlotNotConnected, PacketNumber: 2})
+
+	waitFrames(t, padDevice, 2)
+	padDevice.ExpectFrameContaining(t, vdtest.Button(linux.BTN_SOUTH, 1))
+	padDevice.ExpectFrameContaining(t, vdtest.Button(linux.BTN_SOUTH, 0))
+}
+
+func TestClient_InvalidSlot(t *testing.T) {
+	gp, _ := newMockGamepad()
+	c := NewClientFactory().WithGamepad(MaxSlots, gp).Create()
+	if err := c.Start(); err == nil {
+		c.Stop()
+		t.Fatal("expected an error")
+	}
+}
+
+func TestClient_ResubscribesPeriodically(t *testing.T) {
+	srv := newServer(t)
+	gp, _ := newMockGamepad()
+
+	c := NewClientFactory().WithServer(srv.address()).WithGamepad(3, gp).WithRequestInterval(10 * time.Millisecond).Create()
+	if err := c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Stop()
+
+	srv.expectRequest(3)
+	srv.expectRequest(3)
+}
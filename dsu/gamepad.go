@@ -0,0 +1,66 @@
+package dsu
+
+import "github.com/jbdemonte/virtual-device/gamepad"
+
+// buttonMapping associates the DSU buttons with the logical gamepad buttons.
+var buttonMapping = map[Buttons]gamepad.Button{
+	ButtonShare:    gamepad.ButtonSelect,
+	ButtonL3:       gamepad.ButtonL3,
+	ButtonR3:       gamepad.ButtonR3,
+	ButtonOptions:  gamepad.ButtonStart,
+	ButtonUp:       gamepad.ButtonUp,
+	ButtonRight:    gamepad.ButtonRight,
+	ButtonDown:     gamepad.ButtonDown,
+	ButtonLeft:     gamepad.ButtonLeft,
+	ButtonL1:       gamepad.ButtonL1,
+	ButtonR1:       gamepad.ButtonR1,
+	ButtonTriangle: gamepad.ButtonNorth,
+	ButtonCircle:   gamepad.ButtonEast,
+	ButtonCross:    gamepad.ButtonSouth,
+	ButtonSquare:   gamepad.ButtonWest,
+}
+
+// GamepadState converts the controller data of a slot to a gamepad state.
+// L2 and R2 are reported as analog triggers, the face buttons and the D-pad as digital buttons.
+func GamepadState(data ControllerData) gamepad.GamepadState {
+	state := gamepad.GamepadState{
+		Buttons: map[gamepad.Button]bool{},
+		LeftStick: gamepad.Stick{
+			X: stickAxis(data.LeftX),
+			Y: -stickAxis(data.LeftY),
+		},
+		RightStick: gamepad.Stick{
+			X: stickAxis(data.RightX),
+			Y: -stickAxis(data.RightY),
+		},
+		LeftTrigger:  float32(data.AnalogL2) / 255,
+		RightTrigger: float32(data.AnalogR2) / 255,
+	}
+	for button, logical := range buttonMapping {
+		if data.Buttons&button != 0 {
+			state.Buttons[logical] = true
+		}
+	}
+	if data.AnalogL2 == 0 && data.Buttons&ButtonL2 != 0 {
+		state.LeftTrigger = 1
+	}
+	if data.AnalogR2 == 0 && data.Buttons&ButtonR2 != 0 {
+		state.RightTrigger = 1
+	}
+	if data.Home {
+		state.Buttons[gamepad.ButtonMode] = true
+	}
+	if data.TouchButton {
+		state.Buttons[gamepad.ButtonTouchpad] = true
+	}
+	return state
+}
+
+// stickAxis converts a DSU stick position (0 to 255, 128 being the center) to a normalized axis, the top being positive.
+func stickAxis(value uint8) float32 {
+	normalized := (float32(value) - 128) / 127
+	if normalized < -1 {
+		return -1
+	}
+	return normalized
+}
@@ -0,0 +1,33 @@
+package dsu
+
+import (
+	"testing"
+
+	"github.com/jbdemonte/virtual-device/gamepad"
+)
+
+func TestGamepadState(t *testing.T) {
+	state := GamepadState(ControllerData{
+		Buttons: ButtonTriangle | ButtonUp | ButtonL2,
+		Home:    true,
+		LeftX:   0,
+		LeftY:   255,
+		RightX:  128,
+		RightY:  128,
+	})
+
+	for _, button := range []gamepad.Button{gamepad.ButtonNorth, gamepad.ButtonUp, gamepad.ButtonMode} {
+		if !state.Buttons[button] {
+			t.Errorf("button 0x%x not pressed", button)
+		}
+	}
+	if state.LeftTrigger != 1 {
+		t.Errorf("digital L2 without pressure: LeftTrigger = %v, want 1", state.LeftTrigger)
+	}
+	if state.LeftStick != (gamepad.Stick{X: -1, Y: -1}) {
+		t.Errorf("LeftStick = %+v, want top left", state.LeftStick)
+	}
+	if state.RightStick != (gamepad.Stick{}) {
+		t.Errorf("RightStick = %+v, want centered", state.RightStick)
+	}
+}
@@ -0,0 +1,280 @@
+package dsu
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+)
+
+// https://v1993.github.io/cemuhook-protocol/
+
+// ProtocolVersion is the version of the DSU protocol implemented by this package.
+const ProtocolVersion = 1001
+
+// DefaultServerAddress is the address DSU servers listen on by default.
+const DefaultServerAddress = "127.0.0.1:26760"
+
+// MaxSlots is the number of controller slots a DSU server exposes.
+const MaxSlots = 4
+
+// ErrInvalidPacket is returned when a packet can not be decoded.
+var ErrInvalidPacket = errors.New("invalid DSU packet")
+
+// MessageType identifies the content of a packet.
+type MessageType uint32
+
+const (
+	MessageVersion        MessageType = 0x100000
+	MessageControllerInfo MessageType = 0x100001
+	MessageControllerData MessageType = 0x100002
+)
+
+const (
+	magicClient = "DSUC"
+	magicServer = "DSUS"
+
+	headerSize         = 16
+	dataRequestSize    = headerSize + 4 + 8
+	controllerDataSize = headerSize + 4 + 80
+)
+
+// SlotState tells whether a controller is connected to a slot.
+type SlotState uint8
+
+const (
+	SlotNotConnected SlotState = 0
+	SlotReserved     SlotState = 1
+	SlotConnected    SlotState = 2
+)
+
+// DeviceModel tells which motion data a controller provides.
+type DeviceModel uint8
+
+const (
+	ModelNotApplicable DeviceModel = 0
+	ModelPartialGyro   DeviceModel = 1
+	ModelFullGyro      DeviceModel = 2
+)
+
+// ConnectionType is the way a controller is connected to the server.
+type ConnectionType uint8
+
+const (
+	ConnectionNotApplicable ConnectionType = 0
+	ConnectionUSB           ConnectionType = 1
+	ConnectionBluetooth     ConnectionType = 2
+)
+
+// Buttons is the bitmask of the digital buttons, using the DualShock 4 names.
+type Buttons uint16
+
+const (
+	ButtonShare Buttons = 1 << iota
+	ButtonL3
+	ButtonR3
+	ButtonOptions
+	ButtonUp
+	ButtonRight
+	ButtonDown
+	ButtonLeft
+	ButtonL2
+	ButtonR2
+	ButtonL1
+	ButtonR1
+	ButtonTriangle
+	ButtonCircle
+	ButtonCross
+	ButtonSquare
+)
+
+// Touch is a contact on the touchpad.
+type Touch struct {
+	Active bool
+	ID     uint8
+	X      uint16
+	Y      uint16
+}
+
+// ControllerData is the state of the controller of a slot, as sent by the server.
+type ControllerData struct {
+	Slot       uint8
+	State      SlotState
+	Model      DeviceModel
+	Connection ConnectionType
+	MAC        [6]byte
+	Battery    uint8
+
+	Connected    bool
+	PacketNumber uint32
+	ServerID     uint32 // set by DecodeControllerData, EncodeControllerData taking it as a parameter
+
+	Buttons     Buttons
+	Home        bool
+	TouchButton bool
+
+	// stick positions, 128 being the center and 255 the right or the top
+	LeftX, LeftY   uint8
+	RightX, RightY uint8
+
+	// pressure of the analog buttons, from 0 to 255
+	AnalogLeft, AnalogDown, AnalogRight, AnalogUp           uint8
+	AnalogTriangle, AnalogCircle, AnalogCross, AnalogSquare uint8
+	AnalogR1, AnalogL1, AnalogR2, AnalogL2                  uint8
+
+	Touches [2]Touch
+
+	MotionTimestamp uint64     // microseconds
+	Accelerometer   [3]float32 // g
+	Gyroscope       [3]float32 // pitch, yaw and roll in degrees per second
+}
+
+// EncodeDataRequest builds the packet a client sends to subscribe to the data of a slot.
+func EncodeDataRequest(clientID uint32, slot uint8) []byte {
+	packet := make([]byte, dataRequestSize)
+	packet[20] = 1 // slot-based registration
+	packet[21] = slot
+	return seal(packet, magicClient, clientID, MessageControllerData)
+}
+
+// DecodeDataRequest returns the slot a client subscribes to.
+func DecodeDataRequest(packet []byte) (uint8, error) {
+	messageType, err := open(packet, magicClient)
+	if err != nil {
+		return 0, err
+	}
+	if messageType != MessageControllerData || len(packet) < dataRequestSize {
+		return 0, fmt.Errorf("%w: not a data request", ErrInvalidPacket)
+	}
+	if packet[20]&1 == 0 {
+		return 0, fmt.Errorf("%w: only slot-based requests are supported", ErrInvalidPacket)
+	}
+	return packet[21], nil
+}
+
+// EncodeControllerData builds the packet a server sends with the state of a controller.
+func EncodeControllerData(serverID uint32, data ControllerData) []byte {
+	packet := make([]byte, controllerDataSize)
+	packet[20] = data.Slot
+	packet[21] = byte(data.State)
+	packet[22] = byte(data.Model)
+	packet[23] = byte(data.Connection)
+	copy(packet[24:30], data.MAC[:])
+	packet[30] = data.Battery
+	packet[31] = boolByte(data.Connected)
+	binary.LittleEndian.PutUint32(packet[32:], data.PacketNumber)
+	packet[36] = byte(data.Buttons)
+	packet[37] = byte(data.Buttons >> 8)
+	packet[38] = boolByte(data.Home)
+	packet[39] = boolByte(data.TouchButton)
+	copy(packet[40:56], []byte{
+		data.LeftX, data.LeftY, data.RightX, data.RightY,
+		data.AnalogLeft, data.AnalogDown, data.AnalogRight, data.AnalogUp,
+		data.AnalogTriangle, data.AnalogCircle, data.AnalogCross, data.AnalogSquare,
+		data.AnalogR1, data.AnalogL1, data.AnalogR2, data.AnalogL2,
+	})
+	for i, touch := range data.Touches {
+		offset := 56 + i*6
+		packet[offset] = boolByte(touch.Active)
+		packet[offset+1] = touch.ID
+		binary.LittleEndian.PutUint16(packet[offset+2:], touch.X)
+		binary.LittleEndian.PutUint16(packet[offset+4:], touch.Y)
+	}
+	binary.LittleEndian.PutUint64(packet[68:], data.MotionTimestamp)
+	for i, value := range append(data.Accelerometer[:], data.Gyroscope[:]...) {
+		binary.LittleEndian.PutUint32(packet[76+i*4:], math.Float32bits(value))
+	}
+	return seal(packet, magicServer, serverID, MessageControllerData)
+}
+
+// DecodeControllerData reads the state of a controller sent by a server.
+func DecodeControllerData(packet []byte) (ControllerData, error) {
+	messageType, err := open(packet, magicServer)
+	if err != nil {
+		return ControllerData{}, err
+	}
+	if messageType != MessageControllerData || len(packet) < controllerDataSize {
+		return ControllerData{}, fmt.Errorf("%w: not controller data", ErrInvalidPacket)
+	}
+	data := ControllerData{
+		Slot:         packet[20],
+		State:        SlotState(packet[21]),
+		Model:        DeviceModel(packet[22]),
+		Connection:   ConnectionType(packet[23]),
+		Battery:      packet[30],
+		Connected:    packet[31] != 0,
+		PacketNumber: binary.LittleEndian.Uint32(packet[32:]),
+		ServerID:     binary.LittleEndian.Uint32(packet[12:]),
+		Buttons:      Buttons(packet[36]) | Buttons(packet[37])<<8,
+		Home:         packet[38] != 0,
+		TouchButton:  packet[39] != 0,
+
+		LeftX: packet[40], LeftY: packet[41], RightX: packet[42], RightY: packet[43],
+
+		AnalogLeft: packet[44], AnalogDown: packet[45], AnalogRight: packet[46], AnalogUp: packet[47],
+		AnalogTriangle: packet[48], AnalogCircle: packet[49], AnalogCross: packet[50], AnalogSquare: packet[51],
+		AnalogR1: packet[52], AnalogL1: packet[53], AnalogR2: packet[54], AnalogL2: packet[55],
+
+		MotionTimestamp: binary.LittleEndian.Uint64(packet[68:]),
+	}
+	copy(data.MAC[:], packet[24:30])
+	for i := range data.Touches {
+		offset := 56 + i*6
+		data.Touches[i] = Touch{
+			Active: packet[offset] != 0,
+			ID:     packet[offset+1],
+			X:      binary.LittleEndian.Uint16(packet[offset+2:]),
+			Y:      binary.LittleEndian.Uint16(packet[offset+4:]),
+		}
+	}
+	for i := 0; i < 3; i++ {
+		data.Accelerometer[i] = math.Float32frombits(binary.LittleEndian.Uint32(packet[76+i*4:]))
+		data.Gyroscope[i] = math.Float32frombits(binary.LittleEndian.Uint32(packet[88+i*4:]))
+	}
+	return data, nil
+}
+
+// seal writes the header of a packet, the checksum being computed last.
+func seal(packet []byte, magic string, id uint32, messageType MessageType) []byte {
+	copy(packet[0:4], magic)
+	binary.LittleEndian.PutUint16(packet[4:], ProtocolVersion)
+	binary.LittleEndian.PutUint16(packet[6:], uint16(len(packet)-headerSize))
+	binary.LittleEndian.PutUint32(packet[12:], id)
+	binary.LittleEndian.PutUint32(packet[16:], uint32(messageType))
+	binary.LittleEndian.PutUint32(packet[8:], crc32.ChecksumIEEE(packet))
+	return packet
+}
+
+// open checks the header and the checksum of a packet and returns its message type.
+func open(packet []byte, magic string) (MessageType, error) {
+	if len(packet) < headerSize+4 {
+		return 0, fmt.Errorf("%w: too short", ErrInvalidPacket)
+	}
+	if string(packet[0:4]) != magic {
+		return 0, fmt.Errorf("%w: unexpected magic %q", ErrInvalidPacket, packet[0:4])
+	}
+	if version := binary.LittleEndian.Uint16(packet[4:]); version != ProtocolVersion {
+		return 0, fmt.Errorf("%w: unsupported version %d", ErrInvalidPacket, version)
+	}
+	length := int(binary.LittleEndian.Uint16(packet[6:]))
+	if headerSize+length > len(packet) {
+		return 0, fmt.Errorf("%w: truncated", ErrInvalidPacket)
+	}
+	packet = packet[:headerSize+length]
+
+	checksum := binary.LittleEndian.Uint32(packet[8:])
+	unsealed := append([]byte{}, packet...)
+	binary.LittleEndian.PutUint32(unsealed[8:], 0)
+	if crc32.ChecksumIEEE(unsealed) != checksum {
+		return 0, fmt.Errorf("%w: bad checksum", ErrInvalidPacket)
+	}
+	return MessageType(binary.LittleEndian.Uint32(packet[16:])), nil
+}
+
+func boolByte(value bool) byte {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,79 @@
+package dsu
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestControllerData_RoundTrip(t *testing.T) {
+	data := ControllerData{
+		Slot:            2,
+		State:           SlotConnected,
+		Model:           ModelFullGyro,
+		Connection:      ConnectionBluetooth,
+		MAC:             [6]byte{1, 2, 3, 4, 5, 6},
+		Battery:         5,
+		Connected:       true,
+		PacketNumber:    42,
+		Buttons:         ButtonCross | ButtonLeft | ButtonR2,
+		Home:            true,
+		LeftX:           255,
+		LeftY:           128,
+		RightX:          0,
+		RightY:          200,
+		AnalogR2:        180,
+		Touches:         [2]Touch{{Active: true, ID: 7, X: 1000, Y: 500}},
+		MotionTimestamp: 123456789,
+		Accelerometer:   [3]float32{0, 1, -0.5},
+		Gyroscope:       [3]float32{10, -20, 30.5},
+	}
+
+	packet := EncodeControllerData(99, data)
+	data.ServerID = 99
+	if len(packet) != 100 {
+		t.Fatalf("packet size = %d, want 100", len(packet))
+	}
+	decoded, err := DecodeControllerData(packet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded != data {
+		t.Errorf("decoded = %+v\nwant %+v", decoded, data)
+	}
+}
+
+func TestDataRequest_RoundTrip(t *testing.T) {
+	packet := EncodeDataRequest(1, 3)
+	if len(packet) != 28 {
+		t.Fatalf("packet size = %d, want 28", len(packet))
+	}
+	slot, err := DecodeDataRequest(packet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slot != 3 {
+		t.Errorf("slot = %d, want 3", slot)
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	valid := EncodeControllerData(1, ControllerData{})
+
+	corrupted := append([]byte{}, valid...)
+	corrupted[40] ^= 0xff
+
+	wrongMagic := append([]byte{}, valid...)
+	copy(wrongMagic, "DSUC")
+
+	for name, packet := range map[string][]byte{
+		"short":        valid[:10],
+		"truncated":    valid[:50],
+		"checksum":     corrupted,
+		"magic":        wrongMagic,
+		"data request": EncodeDataRequest(1, 0),
+	} {
+		if _, err := DecodeControllerData(packet); !errors.Is(err, ErrInvalidPacket) {
+			t.Errorf("%s: expected ErrInvalidPacket, got %v", name, err)
+		}
+	}
+}
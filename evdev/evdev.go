@@ -0,0 +1,240 @@
+package evdev
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// https://www.kernel.org/doc/html/latest/input/input.html#event-interface
+
+// InputDevice is a real (or virtual) evdev device opened for reading.
+type InputDevice interface {
+	Path() string
+	Name() string
+	Phys() string
+	Uniq() string
+	ID() linux.InputID
+
+	Capabilities() virtual_device.Capabilities
+	AbsAxes() []virtual_device.AbsAxis
+
+	Grab() error
+	Ungrab() error
+
+	ReadEvents() ([]linux.InputEvent, error)
+	Close() error
+}
+
+// Open opens the evdev node at path (e.g. /dev/input/event3) and reads its identity and capabilities.
+func Open(path string) (InputDevice, error) {
+	fd, err := os.OpenFile(path, syscall.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		return nil, fmt.Errorf("could not open %s: %v", path, err)
+	}
+
+	d := &inputDevice{fd: fd, path: path}
+
+	steps := []func() error{
+		d.readIdentity,
+		d.readCapabilities,
+		d.readAbsAxes,
+	}
+
+	for _, step := range steps {
+		if err := step(); err != nil {
+			fd.Close()
+			return nil, err
+		}
+	}
+
+	return d, nil
+}
+
+type inputDevice struct {
+	fd           *os.File
+	path         string
+	name         string
+	phys         string
+	uniq         string
+	id           linux.InputID
+	capabilities virtual_device.Capabilities
+	absAxes      []virtual_device.AbsAxis
+	grabbed      bool
+}
+
+func (d *inputDevice) Path() string {
+	return d.path
+}
+
+func (d *inputDevice) Name() string {
+	return d.name
+}
+
+func (d *inputDevice) Phys() string {
+	return d.phys
+}
+
+func (d *inputDevice) Uniq() string {
+	return d.uniq
+}
+
+func (d *inputDevice) ID() linux.InputID {
+	return d.id
+}
+
+func (d *inputDevice) Capabilities() virtual_device.Capabilities {
+	return d.capabilities
+}
+
+func (d *inputDevice) AbsAxes() []virtual_device.AbsAxis {
+	return d.absAxes
+}
+
+func (d *inputDevice) readString(cmd func(int) uintptr) string {
+	buffer := make([]byte, 256)
+	if err := ioctl(d.fd, cmd(len(buffer)), uintptr(unsafe.Pointer(&buffer[0]))); err != nil {
+		return "" // phys and uniq are optional
+	}
+	return strings.TrimRight(string(buffer), "\x00")
+}
+
+func (d *inputDevice) readIdentity() error {
+	err := ioctl(d.fd, linux.EVIOCGID, uintptr(unsafe.Pointer(&d.id)))
+	if err != nil {
+		return fmt.Errorf("failed to get EVIOCGID: %v", err)
+	}
+	d.name = d.readString(linux.EVIOCGNAME)
+	d.phys = d.readString(linux.EVIOCGPHYS)
+	d.uniq = d.readString(linux.EVIOCGUNIQ)
+	return nil
+}
+
+func (d *inputDevice) readBits(evType linux.EventType, count int) ([]uint16, error) {
+	buffer := make([]byte, (count+7)/8)
+	err := ioctl(d.fd, linux.EVIOCGBIT(int(evType), len(buffer)), uintptr(unsafe.Pointer(&buffer[0])))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get EVIOCGBIT(0x%x): %v", evType, err)
+	}
+	return decodeBits(buffer), nil
+}
+
+func (d *inputDevice) readCapabilities() error {
+	events, err := d.readBits(0, int(linux.EV_CNT))
+	if err != nil {
+		return err
+	}
+	caps := &d.capabilities
+	caps.Events = convertCodes[linux.EventType](events)
+
+	for _, evType := range caps.Events {
+		var codes []uint16
+		switch evType {
+		case linux.EV_KEY:
+			codes, err = d.readBits(evType, int(linux.KEY_CNT))
+			caps.Keys = convertCodes[linux.Key](codes)
+		case linux.EV_REL:
+			codes, err = d.readBits(evType, int(linux.REL_CNT))
+			caps.RelAxes = convertCodes[linux.RelativeAxis](codes)
+		case linux.EV_ABS:
+			codes, err = d.readBits(evType, int(linux.ABS_CNT))
+			caps.AbsAxes = convertCodes[linux.AbsoluteAxis](codes)
+		case linux.EV_MSC:
+			codes, err = d.readBits(evType, int(linux.MSC_CNT))
+			caps.MiscEvents = convertCodes[linux.MiscEvent](codes)
+		case linux.EV_SW:
+			codes, err = d.readBits(evType, int(linux.SW_CNT))
+			caps.Switches = convertCodes[linux.SwitchEvent](codes)
+		case linux.EV_LED:
+			codes, err = d.readBits(evType, int(linux.LED_CNT))
+			caps.LEDs = convertCodes[linux.Led](codes)
+		case linux.EV_SND:
+			codes, err = d.readBits(evType, int(linux.SND_CNT))
+			caps.Sounds = convertCodes[linux.Sound](codes)
+		case linux.EV_FF:
+			codes, err = d.readBits(evType, linux.FF_CNT)
+			caps.ForceEffects = convertCodes[linux.FFEffectType](codes)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	buffer := make([]byte, (int(linux.INPUT_PROP_CNT)+7)/8)
+	err = ioctl(d.fd, linux.EVIOCGPROP(len(buffer)), uintptr(unsafe.Pointer(&buffer[0])))
+	if err == nil {
+		caps.Properties = convertCodes[linux.InputProp](decodeBits(buffer))
+	}
+	return nil
+}
+
+func (d *inputDevice) readAbsAxes() error {
+	d.absAxes = make([]virtual_device.AbsAxis, 0, len(d.capabilities.AbsAxes))
+	for _, axis := range d.capabilities.AbsAxes {
+		var absInfo linux.InputAbsInfo
+		err := ioctl(d.fd, linux.EVIOCGABS(axis), uintptr(unsafe.Pointer(&absInfo)))
+		if err != nil {
+			return fmt.Errorf("failed to get EVIOCGABS(0x%x): %v", axis, err)
+		}
+		d.absAxes = append(d.absAxes, virtual_device.AbsAxis{
+			Axis:       axis,
+			Value:      absInfo.Value,
+			Min:        absInfo.Minimum,
+			Max:        absInfo.Maximum,
+			Fuzz:       absInfo.Fuzz,
+			Flat:       absInfo.Flat,
+			Resolution: absInfo.Resolution,
+		})
+	}
+	return nil
+}
+
+// Grab takes exclusive access: other readers, the console and X/Wayland stop receiving the events.
+func (d *inputDevice) Grab() error {
+	if err := ioctl(d.fd, linux.EVIOCGRAB(), 1); err != nil {
+		return fmt.Errorf("failed to grab %s: %v", d.path, err)
+	}
+	d.grabbed = true
+	return nil
+}
+
+func (d *inputDevice) Ungrab() error {
+	if !d.grabbed {
+		return nil
+	}
+	if err := ioctl(d.fd, linux.EVIOCGRAB(), 0); err != nil {
+		return fmt.Errorf("failed to release %s: %v", d.path, err)
+	}
+	d.grabbed = false
+	return nil
+}
+
+// ReadEvents blocks until at least one event is available.
+func (d *inputDevice) ReadEvents() ([]linux.InputEvent, error) {
+	buffer := make([]linux.InputEvent, 64)
+	raw := unsafe.Slice((*byte)(unsafe.Pointer(&buffer[0])), len(buffer)*linux.SizeofEvent)
+
+	n, err := d.fd.Read(raw)
+	if err != nil {
+		if errors.Is(err, os.ErrClosed) {
+			return nil, ErrClosed
+		}
+		return nil, err
+	}
+	return buffer[:n/linux.SizeofEvent], nil
+}
+
+// Close releases the grab if any and closes the device, unblocking a pending ReadEvents.
+func (d *inputDevice) Close() error {
+	_ = d.Ungrab()
+	return d.fd.Close()
+}
+
+// ErrClosed is returned by ReadEvents once the device has been closed.
+var ErrClosed = errors.New("device closed")
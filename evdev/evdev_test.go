@@ -0,0 +1,40 @@
+package evdev
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestClose_UnblocksReadEvents(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	d := &inputDevice{fd: r, path: "pipe"}
+
+	// an ioctl must leave the descriptor in non-blocking mode, even when it fails
+	if err := d.Grab(); err == nil {
+		t.Fatal("Grab should fail on a pipe")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := d.ReadEvents()
+		done <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrClosed) {
+			t.Errorf("ReadEvents = %v, want ErrClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Close did not unblock ReadEvents")
+	}
+}
@@ -0,0 +1,48 @@
+package evdev
+
+import (
+	"os"
+	"syscall"
+)
+
+// original function taken from: https://github.com/tianon/debian-golang-pty/blob/master/ioctl.go
+// The descriptor is reached through SyscallConn, as Fd() would switch the file to blocking mode
+// and a pending ReadEvents could no longer be interrupted by Close.
+func ioctl(deviceFile *os.File, cmd, arg uintptr) error {
+	conn, err := deviceFile.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var errorCode syscall.Errno
+	err = conn.Control(func(fd uintptr) {
+		_, _, errorCode = syscall.Syscall(syscall.SYS_IOCTL, fd, cmd, arg)
+	})
+	if err != nil {
+		return err
+	}
+	if errorCode != 0 {
+		return errorCode
+	}
+	return nil
+}
+
+// decodeBits converts an ioctl bitmask (little-endian bytes) into the list of set bit indices.
+func decodeBits(buffer []byte) []uint16 {
+	codes := make([]uint16, 0)
+	for i, b := range buffer {
+		for bit := 0; bit < 8; bit++ {
+			if b&(1<<uint(bit)) != 0 {
+				codes = append(codes, uint16(i*8+bit))
+			}
+		}
+	}
+	return codes
+}
+
+func convertCodes[T ~uint16](codes []uint16) []T {
+	result := make([]T, len(codes))
+	for i, code := range codes {
+		result[i] = T(code)
+	}
+	return result
+}
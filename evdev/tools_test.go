@@ -0,0 +1,20 @@
+package evdev
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeBits(t *testing.T) {
+	codes := decodeBits([]byte{0x0b, 0x00, 0x01})
+	want := []uint16{0, 1, 3, 16}
+	if !reflect.DeepEqual(codes, want) {
+		t.Errorf("got %v, want %v", codes, want)
+	}
+}
+
+func TestDecodeBits_Empty(t *testing.T) {
+	if codes := decodeBits([]byte{0, 0}); len(codes) != 0 {
+		t.Errorf("expected no code, got %v", codes)
+	}
+}
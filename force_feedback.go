@@ -0,0 +1,176 @@
+package virtual_device
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"unsafe"
+
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// DefaultFFEffectsMax is the number of effects an application can upload when WithForceFeedback is given 0.
+const DefaultFFEffectsMax = 16
+
+// ForceFeedbackEventType is the kind of force feedback request sent by an application.
+type ForceFeedbackEventType int
+
+const (
+	FFUpload     ForceFeedbackEventType = iota // an effect is uploaded or updated
+	FFErase                                    // an effect is removed
+	FFPlay                                     // an effect starts, Value times
+	FFStop                                     // an effect stops
+	FFGain                                     // the gain of all the effects changes, from 0 to 0xffff
+	FFAutocenter                               // the strength of the auto-centering changes, from 0 to 0xffff
+)
+
+func (t ForceFeedbackEventType) String() string {
+	switch t {
+	case FFUpload:
+		return "upload"
+	case FFErase:
+		return "erase"
+	case FFPlay:
+		return "play"
+	case FFStop:
+		return "stop"
+	case FFGain:
+		return "gain"
+	case FFAutocenter:
+		return "autocenter"
+	}
+	return fmt.Sprintf("ForceFeedbackEventType(%d)", int(t))
+}
+
+// ForceFeedbackEvent is a force feedback request of an application, e.g. a game writing to the event node.
+type ForceFeedbackEvent struct {
+	Type     ForceFeedbackEventType
+	EffectID int16          // FFUpload, FFErase, FFPlay and FFStop
+	Effect   linux.FFEffect // FFUpload
+	Value    int32          // the repetitions of FFPlay, the level of FFGain and FFAutocenter
+}
+
+// ForceFeedbackHandler receives the force feedback requests.
+// It is called from the goroutine reading the uinput file and must not block:
+// the application waits for an upload or an erase to be handled.
+type ForceFeedbackHandler func(event ForceFeedbackEvent)
+
+func (vd *virtualDevice) WithForceFeedback(effects []linux.FFEffectType, effectsMax uint32) VirtualDevice {
+	vd.config.ffEffects = effects
+	vd.config.ffEffectsMax = effectsMax
+	return vd
+}
+
+func (vd *virtualDevice) WithForceFeedbackHandler(handler ForceFeedbackHandler) VirtualDevice {
+	vd.config.ffHandler = handler
+	return vd
+}
+
+// ForceFeedback returns the effects declared by WithForceFeedback.
+func (vd *virtualDevice) ForceFeedback() ([]linux.FFEffectType, uint32) {
+	return vd.config.ffEffects, vd.config.ffEffectsMax
+}
+
+func (vd *virtualDevice) hasForceFeedback() bool {
+	return len(vd.config.ffEffects) > 0
+}
+
+func (vd *virtualDevice) registerForceFeedback() error {
+	if !vd.hasForceFeedback() {
+		return nil
+	}
+	err := ioctl(vd.fd, linux.UI_SET_EVBIT, uintptr(linux.EV_FF))
+	if err != nil {
+		return fmt.Errorf("failed to set UI_SET_EVBIT, EV_FF: %v", err)
+	}
+	for _, effect := range vd.config.ffEffects {
+		err := ioctl(vd.fd, linux.UI_SET_FFBIT, uintptr(effect))
+		if err != nil {
+			return fmt.Errorf("failed to set UI_SET_FFBIT, 0x%x: %v", effect, err)
+		}
+	}
+	return nil
+}
+
+func (vd *virtualDevice) effectsMax() uint32 {
+	if !vd.hasForceFeedback() {
+		return 0
+	}
+	if vd.config.ffEffectsMax == 0 {
+		return DefaultFFEffectsMax
+	}
+	return vd.config.ffEffectsMax
+}
+
+// listen reads the requests of the kernel until the uinput file is closed.
+func (vd *virtualDevice) listen() {
+	if !vd.hasForceFeedback() {
+		return
+	}
+	fd := vd.fd
+	vd.listenDone = make(chan struct{})
+
+	go func() {
+		defer close(vd.listenDone)
+		buffer := make([]linux.InputEvent, 16)
+		raw := unsafe.Slice((*byte)(unsafe.Pointer(&buffer[0])), len(buffer)*linux.SizeofEvent)
+		for {
+			n, err := fd.Read(raw)
+			if err != nil {
+				if !errors.Is(err, os.ErrClosed) && vd.isRegistered.Get() {
+					fmt.Fprintf(os.Stderr, "failed to read the uinput requests: %v\n", err)
+				}
+				return
+			}
+			for _, event := range buffer[:n/linux.SizeofEvent] {
+				vd.handleRequest(fd, event)
+			}
+		}
+	}()
+}
+
+func (vd *virtualDevice) handleRequest(fd *os.File, event linux.InputEvent) {
+	switch {
+	case event.Type == uint16(linux.EV_UINPUT) && event.Code == linux.UI_FF_UPLOAD:
+		upload := linux.UInputFFUpload{RequestID: uint32(event.Value)}
+		if err := ioctl(fd, linux.UI_BEGIN_FF_UPLOAD, uintptr(unsafe.Pointer(&upload))); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to begin the effect upload: %v\n", err)
+			return
+		}
+		vd.notifyForceFeedback(ForceFeedbackEvent{Type: FFUpload, EffectID: upload.Effect.ID, Effect: upload.Effect})
+		upload.Retval = 0
+		if err := ioctl(fd, linux.UI_END_FF_UPLOAD, uintptr(unsafe.Pointer(&upload))); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to end the effect upload: %v\n", err)
+		}
+
+	case event.Type == uint16(linux.EV_UINPUT) && event.Code == linux.UI_FF_ERASE:
+		erase := linux.UInputFFErase{RequestID: uint32(event.Value)}
+		if err := ioctl(fd, linux.UI_BEGIN_FF_ERASE, uintptr(unsafe.Pointer(&erase))); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to begin the effect erase: %v\n", err)
+			return
+		}
+		vd.notifyForceFeedback(ForceFeedbackEvent{Type: FFErase, EffectID: int16(erase.EffectID)})
+		erase.Retval = 0
+		if err := ioctl(fd, linux.UI_END_FF_ERASE, uintptr(unsafe.Pointer(&erase))); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to end the effect erase: %v\n", err)
+		}
+
+	case event.Type == uint16(linux.EV_FF) && event.Code == linux.FF_GAIN:
+		vd.notifyForceFeedback(ForceFeedbackEvent{Type: FFGain, Value: event.Value})
+
+	case event.Type == uint16(linux.EV_FF) && event.Code == linux.FF_AUTOCENTER:
+		vd.notifyForceFeedback(ForceFeedbackEvent{Type: FFAutocenter, Value: event.Value})
+
+	case event.Type == uint16(linux.EV_FF) && event.Value > 0:
+		vd.notifyForceFeedback(ForceFeedbackEvent{Type: FFPlay, EffectID: int16(event.Code), Value: event.Value})
+
+	case event.Type == uint16(linux.EV_FF):
+		vd.notifyForceFeedback(ForceFeedbackEvent{Type: FFStop, EffectID: int16(event.Code)})
+	}
+}
+
+func (vd *virtualDevice) notifyForceFeedback(event ForceFeedbackEvent) {
+	if vd.config.ffHandler != nil {
+		vd.config.ffHandler(event)
+	}
+}
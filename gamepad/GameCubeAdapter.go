@@ -0,0 +1,91 @@
+package gamepad
+
+import (
+	"fmt"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/sdl"
+)
+
+// GameCubeAdapterPorts is the number of ports of the GameCube adapters.
+const GameCubeAdapterPorts = 4
+
+// NewGameCubeAdapter creates a Nintendo GameCube adapter for Wii U and Switch (WUP-028),
+// its ports being named as the userspace driver wii-u-gc-adapter does.
+func NewGameCubeAdapter() Adapter {
+	devices := make([]virtual_device.VirtualDevice, GameCubeAdapterPorts)
+	for i := range devices {
+		devices[i] = virtual_device.
+			NewVirtualDevice().
+			WithBusType(linux.BUS_USB).
+			WithVendor(sdl.USB_VENDOR_NINTENDO).
+			WithProduct(sdl.USB_PRODUCT_NINTENDO_GAMECUBE_ADAPTER).
+			WithVersion(0x100).
+			WithName(fmt.Sprintf("Wii U GameCube Adapter Port %d", i+1))
+	}
+	return newGameCubeAdapter("gcadapter", devices)
+}
+
+// NewEvoRetroGameCubeAdapter creates a Mayflash / EVORETRO GameCube adapter in PC mode, its ports sharing the name of the adapter.
+func NewEvoRetroGameCubeAdapter() Adapter {
+	devices := make([]virtual_device.VirtualDevice, GameCubeAdapterPorts)
+	for i := range devices {
+		devices[i] = virtual_device.
+			NewVirtualDevice().
+			WithBusType(linux.BUS_USB).
+			WithVendor(sdl.USB_VENDOR_DRAGONRISE).
+			WithProduct(sdl.USB_PRODUCT_EVORETRO_GAMECUBE_ADAPTER1).
+			WithVersion(0x110).
+			WithName("mayflash limited MAYFLASH GameCube Controller Adapter")
+	}
+	return newGameCubeAdapter("evoretro-gcadapter", devices)
+}
+
+func newGameCubeAdapter(model string, devices []virtual_device.VirtualDevice) Adapter {
+	gamepads := make([]VirtualGamepad, len(devices))
+	for i, device := range devices {
+		gamepads[i] = newGameCubeController(device)
+	}
+	return newAdapter(model, 1, gamepads)
+}
+
+// newGameCubeController maps a GameCube controller: A, B, X, Y by position, Z as R1,
+// L and R as analog triggers clicking at the end of their travel, and the C-stick as the right stick.
+func newGameCubeController(device virtual_device.VirtualDevice) VirtualGamepad {
+	return NewVirtualGamepadFactory().
+		WithDevice(device).
+		WithDigital(
+			MappingDigital{
+				ButtonSouth: linux.BTN_SOUTH, // A
+				ButtonWest:  linux.BTN_WEST,  // B
+				ButtonEast:  linux.BTN_EAST,  // X
+				ButtonNorth: linux.BTN_NORTH, // Y
+
+				ButtonStart: linux.BTN_START,
+				ButtonR1:    linux.BTN_TR, // Z
+
+				ButtonL2: []InputEvent{virtual_device.AbsAxis{Axis: linux.ABS_Z, Min: 0, Value: 0, Max: 255}, linux.BTN_TL2},
+				ButtonR2: []InputEvent{virtual_device.AbsAxis{Axis: linux.ABS_RZ, Min: 0, Value: 0, Max: 255}, linux.BTN_TR2},
+
+				ButtonUp:    linux.BTN_DPAD_UP,
+				ButtonDown:  linux.BTN_DPAD_DOWN,
+				ButtonLeft:  linux.BTN_DPAD_LEFT,
+				ButtonRight: linux.BTN_DPAD_RIGHT,
+			},
+		).
+		WithLeftStick(
+			MappingStick{
+				X: virtual_device.AbsAxis{Axis: linux.ABS_X, Min: 0, Value: 128, Max: 255, Flat: 4, Fuzz: 1},
+				Y: virtual_device.AbsAxis{Axis: linux.ABS_Y, Min: 0, Value: 128, Max: 255, Flat: 4, Fuzz: 1},
+			},
+		).
+		WithRightStick( // C-stick
+			MappingStick{
+				X: virtual_device.AbsAxis{Axis: linux.ABS_RX, Min: 0, Value: 128, Max: 255, Flat: 4, Fuzz: 1},
+				Y: virtual_device.AbsAxis{Axis: linux.ABS_RY, Min: 0, Value: 128, Max: 255, Flat: 4, Fuzz: 1},
+			},
+		).
+		WithTriggerThreshold(1).
+		Create()
+}
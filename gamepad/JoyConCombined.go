@@ -0,0 +1,66 @@
+package gamepad
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/sdl"
+)
+
+// NewJoyConCombined emulates the device joycond creates when a left and a right Joy-Con are paired together.
+func NewJoyConCombined() VirtualGamepad {
+	return newJoyConCombined(joyConCombinedIdentity(virtual_device.NewVirtualDevice()))
+}
+
+// joyConCombinedIdentity sets the bus, IDs and name of the joycond device.
+func joyConCombinedIdentity(device virtual_device.VirtualDevice) virtual_device.VirtualDevice {
+	return device.
+		WithBusType(linux.BUS_VIRTUAL).
+		WithVendor(sdl.USB_VENDOR_NINTENDO).
+		WithProduct(sdl.USB_PRODUCT_NINTENDO_SWITCH_JOYCON_PAIR).
+		WithVersion(0x0000).
+		WithName("Nintendo Switch Combined Joy-Cons")
+}
+
+func newJoyConCombined(device virtual_device.VirtualDevice) VirtualGamepad {
+	return NewVirtualGamepadFactory().
+		WithDevice(device).
+		WithDigital(
+			MappingDigital{
+				ButtonSouth: linux.BTN_SOUTH,
+				ButtonEast:  linux.BTN_EAST,
+				ButtonNorth: linux.BTN_NORTH,
+				ButtonWest:  linux.BTN_WEST,
+
+				ButtonUp:    linux.BTN_DPAD_UP,
+				ButtonRight: linux.BTN_DPAD_RIGHT,
+				ButtonDown:  linux.BTN_DPAD_DOWN,
+				ButtonLeft:  linux.BTN_DPAD_LEFT,
+
+				ButtonSelect:  linux.BTN_SELECT, // Minus
+				ButtonStart:   linux.BTN_START,  // Plus
+				ButtonMode:    linux.BTN_MODE,   // Home
+				ButtonCapture: linux.BTN_Z,
+
+				ButtonL1: linux.BTN_TL,
+				ButtonR1: linux.BTN_TR,
+				ButtonL2: linux.BTN_TL2,
+				ButtonR2: linux.BTN_TR2,
+
+				ButtonL3: linux.BTN_THUMBL,
+				ButtonR3: linux.BTN_THUMBR,
+			},
+		).
+		WithLeftStick(
+			MappingStick{
+				X: virtual_device.AbsAxis{Axis: linux.ABS_X, Min: -32767, Value: 0, Max: 32767, Flat: 500, Fuzz: 250},
+				Y: virtual_device.AbsAxis{Axis: linux.ABS_Y, Min: -32767, Value: 0, Max: 32767, Flat: 500, Fuzz: 250},
+			},
+		).
+		WithRightStick(
+			MappingStick{
+				X: virtual_device.AbsAxis{Axis: linux.ABS_RX, Min: -32767, Value: 0, Max: 32767, Flat: 500, Fuzz: 250},
+				Y: virtual_device.AbsAxis{Axis: linux.ABS_RY, Min: -32767, Value: 0, Max: 32767, Flat: 500, Fuzz: 250},
+			},
+		).
+		Create()
+}
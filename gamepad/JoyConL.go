@@ -7,10 +7,13 @@ import (
 )
 
 func NewJoyConL() VirtualGamepad {
+	return newJoyConL(virtual_device.NewVirtualDevice())
+}
+
+func newJoyConL(device virtual_device.VirtualDevice) VirtualGamepad {
 	return NewVirtualGamepadFactory().
 		WithDevice(
-			virtual_device.
-				NewVirtualDevice().
+			device.
 				WithBusType(linux.BUS_BLUETOOTH).
 				WithVendor(sdl.USB_VENDOR_NINTENDO).
 				WithProduct(sdl.USB_PRODUCT_NINTENDO_SWITCH_JOYCON_LEFT).
@@ -25,7 +28,7 @@ func NewJoyConL() VirtualGamepad {
 				ButtonLeft:  linux.BTN_DPAD_LEFT,
 
 				ButtonSelect:  linux.BTN_SELECT, // Minus
-				ButtonFiller1: linux.BTN_Z,      // Capture
+				ButtonCapture: linux.BTN_Z,
 
 				ButtonL1: linux.BTN_TL,
 				ButtonL2: linux.BTN_TL2,
@@ -33,6 +36,9 @@ func NewJoyConL() VirtualGamepad {
 				ButtonR1: linux.BTN_TR,  // SL
 				ButtonR2: linux.BTN_TR2, // SR
 
+				ButtonSL: linux.BTN_TR,
+				ButtonSR: linux.BTN_TR2,
+
 				ButtonL3: linux.BTN_THUMBL,
 			},
 		).
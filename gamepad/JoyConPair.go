@@ -0,0 +1,129 @@
+package gamepad
+
+import (
+	"errors"
+	"sync"
+)
+
+// JoyConMode tells how a pair of Joy-Cons is presented.
+type JoyConMode int
+
+const (
+	JoyConSplit    JoyConMode = iota // two devices, "Joy-Con (L)" and "Joy-Con (R)"
+	JoyConCombined                   // a single "Nintendo Switch Combined Joy-Cons" device
+)
+
+// JoyConPair is a left and a right Joy-Con that can be presented either split or combined,
+// as joycond does when the user presses the shoulder buttons of both Joy-Cons.
+type JoyConPair interface {
+	Register() error
+	Unregister() error
+
+	// SetMode switches between split and combined, unregistering the devices of the previous mode
+	// and registering the ones of the new mode when the pair is registered.
+	SetMode(mode JoyConMode) error
+	Mode() JoyConMode
+
+	Left() VirtualGamepad
+	Right() VirtualGamepad
+	Combined() VirtualGamepad
+}
+
+// NewJoyConPair returns a pair of Joy-Cons in the given mode.
+func NewJoyConPair(mode JoyConMode) JoyConPair {
+	return newJoyConPair(mode, NewJoyConL(), NewJoyConR(), NewJoyConCombined())
+}
+
+func newJoyConPair(mode JoyConMode, left, right, combined VirtualGamepad) JoyConPair {
+	return &joyConPair{
+		mode:     mode,
+		left:     left,
+		right:    right,
+		combined: combined,
+	}
+}
+
+type joyConPair struct {
+	mu         sync.Mutex
+	mode       JoyConMode
+	registered bool
+	left       VirtualGamepad
+	right      VirtualGamepad
+	combined   VirtualGamepad
+}
+
+func (p *joyConPair) nodes(mode JoyConMode) []node {
+	if mode == JoyConCombined {
+		return []node{p.combined}
+	}
+	return []node{p.left, p.right}
+}
+
+// Register creates the devices of the current mode, none of them is left registered if one fails.
+func (p *joyConPair) Register() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.registered {
+		return nil
+	}
+	if err := registerNodes(p.nodes(p.mode)...); err != nil {
+		return err
+	}
+	p.registered = true
+	return nil
+}
+
+// Unregister removes the devices of the current mode.
+func (p *joyConPair) Unregister() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.registered {
+		return nil
+	}
+	p.registered = false
+	return unregisterNodes(p.nodes(p.mode)...)
+}
+
+// SetMode keeps the previous mode if the devices of the new one cannot be registered,
+// the pair being left unregistered if the previous devices cannot be registered back either.
+func (p *joyConPair) SetMode(mode JoyConMode) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if mode == p.mode {
+		return nil
+	}
+	if !p.registered {
+		p.mode = mode
+		return nil
+	}
+	if err := unregisterNodes(p.nodes(p.mode)...); err != nil {
+		return err
+	}
+	if err := registerNodes(p.nodes(mode)...); err != nil {
+		if rollbackErr := registerNodes(p.nodes(p.mode)...); rollbackErr != nil {
+			p.registered = false
+			return errors.Join(err, rollbackErr)
+		}
+		return err
+	}
+	p.mode = mode
+	return nil
+}
+
+func (p *joyConPair) Mode() JoyConMode {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.mode
+}
+
+func (p *joyConPair) Left() VirtualGamepad {
+	return p.left
+}
+
+func (p *joyConPair) Right() VirtualGamepad {
+	return p.right
+}
+
+func (p *joyConPair) Combined() VirtualGamepad {
+	return p.combined
+}
@@ -7,10 +7,13 @@ import (
 )
 
 func NewJoyConR() VirtualGamepad {
+	return newJoyConR(virtual_device.NewVirtualDevice())
+}
+
+func newJoyConR(device virtual_device.VirtualDevice) VirtualGamepad {
 	return NewVirtualGamepadFactory().
 		WithDevice(
-			virtual_device.
-				NewVirtualDevice().
+			device.
 				WithBusType(linux.BUS_BLUETOOTH).
 				WithVendor(sdl.USB_VENDOR_NINTENDO).
 				WithProduct(sdl.USB_PRODUCT_NINTENDO_SWITCH_JOYCON_RIGHT).
@@ -33,6 +36,9 @@ func NewJoyConR() VirtualGamepad {
 				ButtonL1: linux.BTN_TL,  // SL
 				ButtonL2: linux.BTN_TL2, // SR
 
+				ButtonSL: linux.BTN_TL,
+				ButtonSR: linux.BTN_TL2,
+
 				ButtonR3: linux.BTN_THUMBR,
 			},
 		).
@@ -28,9 +28,9 @@ func NewSN30Pro() VirtualGamepad {
 				ButtonStart:  []InputEvent{MSCScanCode(0x9000c), linux.BTN_START},
 				ButtonMode:   []InputEvent{MSCScanCode(0x9000d), linux.BTN_MODE}, // Button under South button (B)
 
-				ButtonFiller1: linux.BTN_C,         // ?
-				ButtonFiller2: linux.BTN_Z,         // ?
-				ButtonFiller3: linux.Button(0x13f), // ?
+				buttonReserved1: linux.BTN_C,
+				buttonReserved2: linux.BTN_Z,
+				buttonReserved3: linux.Button(0x13f),
 
 				ButtonUp:    HatEvent{Axis: linux.ABS_HAT0Y, Value: -1},
 				ButtonDown:  HatEvent{Axis: linux.ABS_HAT0Y, Value: 1},
@@ -7,10 +7,13 @@ import (
 )
 
 func NewSaitekP2600() VirtualGamepad {
+	return newSaitekP2600(virtual_device.NewVirtualDevice())
+}
+
+func newSaitekP2600(device virtual_device.VirtualDevice) VirtualGamepad {
 	return NewVirtualGamepadFactory().
 		WithDevice(
-			virtual_device.
-				NewVirtualDevice().
+			device.
 				WithBusType(linux.BUS_USB).
 				WithVendor(sdl.USB_VENDOR_SAITEK).
 				WithProduct(0xff0d).
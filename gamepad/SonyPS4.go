@@ -7,42 +7,57 @@ import (
 )
 
 func NewSonyPS4() VirtualGamepad {
+	digital := sonyPS4Digital()
+	// the kernel reports the touchpad click on the touchpad node, a single node exposes it as an extra button
+	digital[ButtonTouchpad] = linux.BTN_TRIGGER_HAPPY1
+	return newSonyPS4(newSonyPS4Device(), digital)
+}
+
+func newSonyPS4Device() virtual_device.VirtualDevice {
+	return sonyPS4Identity(virtual_device.NewVirtualDevice())
+}
+
+// sonyPS4Identity sets the bus, IDs and name of the gamepad node.
+func sonyPS4Identity(device virtual_device.VirtualDevice) virtual_device.VirtualDevice {
+	return device.
+		WithBusType(linux.BUS_USB).
+		WithVendor(sdl.USB_VENDOR_SONY).
+		WithProduct(sdl.USB_PRODUCT_SONY_DS4_SLIM).
+		WithVersion(0x8111).
+		WithName("Sony Interactive Entertainment Wireless Controller")
+}
+
+func sonyPS4Digital() MappingDigital {
+	return MappingDigital{
+		ButtonSouth: linux.BTN_SOUTH,
+		ButtonEast:  linux.BTN_EAST,
+		ButtonNorth: linux.BTN_NORTH,
+		ButtonWest:  linux.BTN_WEST,
+
+		ButtonSelect: linux.BTN_SELECT,
+		ButtonStart:  linux.BTN_START,
+		ButtonMode:   linux.BTN_MODE, // Button Playstation
+
+		ButtonUp:    HatEvent{Axis: linux.ABS_HAT0Y, Value: -1},
+		ButtonDown:  HatEvent{Axis: linux.ABS_HAT0Y, Value: 1},
+		ButtonLeft:  HatEvent{Axis: linux.ABS_HAT0X, Value: -1},
+		ButtonRight: HatEvent{Axis: linux.ABS_HAT0X, Value: 1},
+
+		ButtonL1: linux.BTN_TL,
+		ButtonR1: linux.BTN_TR,
+
+		ButtonL2: virtual_device.AbsAxis{Axis: linux.ABS_Z, Min: 0, Value: 0, Max: 255},
+		ButtonR2: virtual_device.AbsAxis{Axis: linux.ABS_RZ, Min: 0, Value: 0, Max: 255},
+
+		ButtonL3: linux.BTN_THUMBL,
+		ButtonR3: linux.BTN_THUMBR,
+	}
+}
+
+func newSonyPS4(device virtual_device.VirtualDevice, digital MappingDigital) VirtualGamepad {
 	return NewVirtualGamepadFactory().
-		WithDevice(
-			virtual_device.
-				NewVirtualDevice().
-				WithBusType(linux.BUS_USB).
-				WithVendor(sdl.USB_VENDOR_SONY).
-				WithProduct(sdl.USB_PRODUCT_SONY_DS4_SLIM).
-				WithVersion(0x8111).
-				WithName("Sony Interactive Entertainment Wireless Controller"),
-		).
-		WithDigital(
-			MappingDigital{
-				ButtonSouth: linux.BTN_SOUTH,
-				ButtonEast:  linux.BTN_EAST,
-				ButtonNorth: linux.BTN_NORTH,
-				ButtonWest:  linux.BTN_WEST,
-
-				ButtonSelect: linux.BTN_SELECT,
-				ButtonStart:  linux.BTN_START,
-				ButtonMode:   linux.BTN_MODE, // Button Playstation
-
-				ButtonUp:    HatEvent{Axis: linux.ABS_HAT0Y, Value: -1},
-				ButtonDown:  HatEvent{Axis: linux.ABS_HAT0Y, Value: 1},
-				ButtonLeft:  HatEvent{Axis: linux.ABS_HAT0X, Value: -1},
-				ButtonRight: HatEvent{Axis: linux.ABS_HAT0X, Value: 1},
-
-				ButtonL1: linux.BTN_TL,
-				ButtonR1: linux.BTN_TR,
-
-				ButtonL2: virtual_device.AbsAxis{Axis: linux.ABS_Z, Min: 0, Value: 0, Max: 255},
-				ButtonR2: virtual_device.AbsAxis{Axis: linux.ABS_RZ, Min: 0, Value: 0, Max: 255},
-
-				ButtonL3: linux.BTN_THUMBL,
-				ButtonR3: linux.BTN_THUMBR,
-			},
-		).
+		WithDevice(device).
+		WithDigital(digital).
 		WithLeftStick(
 			MappingStick{
 				X: virtual_device.AbsAxis{Axis: linux.ABS_X, Min: 0, Value: 0, Max: 255},
@@ -0,0 +1,117 @@
+package gamepad
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/imu"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/touchpad"
+)
+
+// DualShock4 bundles the three evdev nodes the kernel creates for a DualShock 4:
+// the gamepad, the touchpad and the motion sensors, all sharing the same identity and phys.
+type DualShock4 interface {
+	Register() error
+	Unregister() error
+
+	Gamepad() VirtualGamepad
+	Touchpad() touchpad.VirtualTouchpad
+	MotionSensors() imu.VirtualIMU
+
+	Phys() string
+}
+
+// DS4 touchpad size, as reported by hid-playstation.
+const (
+	sonyPS4TouchpadWidth  = 1920
+	sonyPS4TouchpadHeight = 942
+)
+
+// NewSonyPS4Composite creates a DualShock 4 with its touchpad and motion sensors nodes.
+// The touchpad click is reported as BTN_LEFT on the touchpad node, as the kernel does.
+func NewSonyPS4Composite() DualShock4 {
+	return newSonyPS4Composite(newSonyPS4Device(), newSonyPS4TouchpadDevice(), newSonyPS4MotionDevice())
+}
+
+func newSonyPS4Composite(pad, touch, motion virtual_device.VirtualDevice) DualShock4 {
+	phys := newCompositePhys("ds4")
+	return &sonyComposite{
+		phys:     phys,
+		gamepad:  newSonyPS4(pad.WithPhys(phys), sonyPS4Digital()),
+		touchpad: newSonyTouchpad(touch.WithPhys(phys), sonyPS4TouchpadWidth, sonyPS4TouchpadHeight),
+		motion:   newSonyIMU(motion.WithPhys(phys)),
+	}
+}
+
+func newSonyPS4TouchpadDevice() virtual_device.VirtualDevice {
+	return newSonyPS4Device().WithName("Sony Interactive Entertainment Wireless Controller Touchpad")
+}
+
+func newSonyPS4MotionDevice() virtual_device.VirtualDevice {
+	return newSonyPS4Device().WithName("Sony Interactive Entertainment Wireless Controller Motion Sensors")
+}
+
+// newSonyIMU creates the motion sensors of the Sony controllers.
+func newSonyIMU(device virtual_device.VirtualDevice) imu.VirtualIMU {
+	return imu.NewVirtualIMUFactory().
+		WithDevice(device).
+		WithAxes(imu.PlayStationAxes()).
+		Create()
+}
+
+// newSonyTouchpad creates the two-finger clickable touchpad of the Sony controllers.
+func newSonyTouchpad(device virtual_device.VirtualDevice, width, height int32) touchpad.VirtualTouchpad {
+	return touchpad.NewVirtualTouchpadFactory().
+		WithDevice(device).
+		WithAxes([]virtual_device.AbsAxis{
+			{Axis: linux.ABS_X, Min: 0, Value: 0, Max: width - 1},
+			{Axis: linux.ABS_Y, Min: 0, Value: 0, Max: height - 1},
+			{Axis: linux.ABS_MT_SLOT, Min: 0, Value: 0, Max: 1},
+			{Axis: linux.ABS_MT_POSITION_X, Min: 0, Value: 0, Max: width - 1},
+			{Axis: linux.ABS_MT_POSITION_Y, Min: 0, Value: 0, Max: height - 1},
+			{Axis: linux.ABS_MT_TRACKING_ID, Min: 0, Value: 0, Max: 65535},
+		}).
+		WithButtons([]linux.Button{
+			linux.BTN_LEFT,
+			linux.BTN_TOUCH,
+			linux.BTN_TOOL_FINGER,
+			linux.BTN_TOOL_DOUBLETAP,
+		}).
+		WithProperties([]linux.InputProp{
+			linux.INPUT_PROP_POINTER, linux.INPUT_PROP_BUTTONPAD,
+		}).
+		Create()
+}
+
+// sonyComposite holds the gamepad, touchpad and motion sensors nodes of a Sony controller.
+type sonyComposite struct {
+	phys     string
+	gamepad  VirtualGamepad
+	touchpad touchpad.VirtualTouchpad
+	motion   imu.VirtualIMU
+}
+
+// Register creates the three nodes, none of them is left registered if one fails.
+func (sc *sonyComposite) Register() error {
+	return registerNodes(sc.gamepad, sc.touchpad, sc.motion)
+}
+
+// Unregister removes the three nodes, returning the errors of all the failing ones.
+func (sc *sonyComposite) Unregister() error {
+	return unregisterNodes(sc.gamepad, sc.touchpad, sc.motion)
+}
+
+func (sc *sonyComposite) Gamepad() VirtualGamepad {
+	return sc.gamepad
+}
+
+func (sc *sonyComposite) Touchpad() touchpad.VirtualTouchpad {
+	return sc.touchpad
+}
+
+func (sc *sonyComposite) MotionSensors() imu.VirtualIMU {
+	return sc.motion
+}
+
+func (sc *sonyComposite) Phys() string {
+	return sc.phys
+}
@@ -0,0 +1,76 @@
+package gamepad
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/imu"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/touchpad"
+)
+
+// DualSense bundles the three evdev nodes the kernel creates for a DualSense:
+// the gamepad, the touchpad and the motion sensors, all sharing the same identity and phys.
+// The gamepad node also reports the headset jack through SW_HEADPHONE_INSERT and SW_MICROPHONE_INSERT.
+type DualSense interface {
+	Register() error
+	Unregister() error
+
+	Gamepad() VirtualGamepad
+	Touchpad() touchpad.VirtualTouchpad
+	MotionSensors() imu.VirtualIMU
+
+	Phys() string
+
+	SetHeadphoneInserted(inserted bool)
+	SetMicrophoneInserted(inserted bool)
+}
+
+// DualSense touchpad size, as reported by hid-playstation.
+const (
+	sonyPS5TouchpadWidth  = 1920
+	sonyPS5TouchpadHeight = 1080
+)
+
+// NewSonyPS5Composite creates a DualSense with its touchpad and motion sensors nodes.
+// The touchpad click is reported as BTN_LEFT on the touchpad node, as the kernel does.
+func NewSonyPS5Composite() DualSense {
+	return newSonyPS5Composite(newSonyPS5Device(), newSonyPS5TouchpadDevice(), newSonyPS5MotionDevice())
+}
+
+func newSonyPS5Composite(pad, touch, motion virtual_device.VirtualDevice) DualSense {
+	phys := newCompositePhys("dualsense")
+	pad.WithPhys(phys).WithSwitches([]linux.SwitchEvent{linux.SW_HEADPHONE_INSERT, linux.SW_MICROPHONE_INSERT})
+	return &dualSense{
+		sonyComposite: sonyComposite{
+			phys:     phys,
+			gamepad:  newSonyPS5(pad, sonyPS5Digital()),
+			touchpad: newSonyTouchpad(touch.WithPhys(phys), sonyPS5TouchpadWidth, sonyPS5TouchpadHeight),
+			motion:   newSonyIMU(motion.WithPhys(phys)),
+		},
+		pad: pad,
+	}
+}
+
+func newSonyPS5TouchpadDevice() virtual_device.VirtualDevice {
+	return newSonyPS5Device().WithName("Sony Interactive Entertainment DualSense Wireless Controller Touchpad")
+}
+
+func newSonyPS5MotionDevice() virtual_device.VirtualDevice {
+	return newSonyPS5Device().WithName("Sony Interactive Entertainment DualSense Wireless Controller Motion Sensors")
+}
+
+type dualSense struct {
+	sonyComposite
+	pad virtual_device.VirtualDevice
+}
+
+// SetHeadphoneInserted reports a headphone plugged in or out of the headset jack.
+func (ds *dualSense) SetHeadphoneInserted(inserted bool) {
+	ds.pad.SetSwitch(linux.SW_HEADPHONE_INSERT, inserted)
+	ds.pad.SyncReport()
+}
+
+// SetMicrophoneInserted reports a headset microphone plugged in or out of the headset jack.
+func (ds *dualSense) SetMicrophoneInserted(inserted bool) {
+	ds.pad.SetSwitch(linux.SW_MICROPHONE_INSERT, inserted)
+	ds.pad.SyncReport()
+}
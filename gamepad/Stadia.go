@@ -7,10 +7,13 @@ import (
 )
 
 func NewStadia() VirtualGamepad {
+	return newStadia(virtual_device.NewVirtualDevice())
+}
+
+func newStadia(device virtual_device.VirtualDevice) VirtualGamepad {
 	return NewVirtualGamepadFactory().
 		WithDevice(
-			virtual_device.
-				NewVirtualDevice().
+			device.
 				WithBusType(linux.BUS_USB).
 				WithVendor(sdl.USB_VENDOR_GOOGLE).
 				WithProduct(sdl.USB_PRODUCT_GOOGLE_STADIA_CONTROLLER).
@@ -28,8 +31,8 @@ func NewStadia() VirtualGamepad {
 				ButtonStart:  []InputEvent{MSCScanCode(0x9000c), linux.BTN_START},  // button Menu
 				ButtonMode:   []InputEvent{MSCScanCode(0x9000d), linux.BTN_MODE},   // button Stadia
 
-				ButtonFiller1: []InputEvent{MSCScanCode(90011), linux.BTN_TRIGGER_HAPPY1}, // Button Google Assistant
-				ButtonFiller2: []InputEvent{MSCScanCode(90012), linux.BTN_TRIGGER_HAPPY2}, // Button Capture
+				ButtonMisc1:   []InputEvent{MSCScanCode(90011), linux.BTN_TRIGGER_HAPPY1}, // Button Google Assistant
+				ButtonCapture: []InputEvent{MSCScanCode(90012), linux.BTN_TRIGGER_HAPPY2}, // Button Capture
 
 				ButtonUp:    []InputEvent{HatEvent{Axis: linux.ABS_HAT0Y, Value: -1}},
 				ButtonDown:  []InputEvent{HatEvent{Axis: linux.ABS_HAT0Y, Value: 1}},
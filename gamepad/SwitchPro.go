@@ -7,10 +7,13 @@ import (
 )
 
 func NewSwitchPro() VirtualGamepad {
+	return newSwitchPro(virtual_device.NewVirtualDevice())
+}
+
+func newSwitchPro(device virtual_device.VirtualDevice) VirtualGamepad {
 	return NewVirtualGamepadFactory().
 		WithDevice(
-			virtual_device.
-				NewVirtualDevice().
+			device.
 				WithBusType(linux.BUS_USB).
 				WithVendor(sdl.USB_VENDOR_NINTENDO).
 				WithProduct(sdl.USB_PRODUCT_NINTENDO_SWITCH_PRO).
@@ -27,7 +30,7 @@ func NewSwitchPro() VirtualGamepad {
 				ButtonSelect:  linux.BTN_SELECT, // Button -
 				ButtonStart:   linux.BTN_START,  // Button +
 				ButtonMode:    linux.BTN_MODE,   // Button Home
-				ButtonFiller1: linux.BTN_Z,      // Button Square, under -
+				ButtonCapture: linux.BTN_Z,      // Button Square, under -
 
 				ButtonUp:    HatEvent{Axis: linux.ABS_HAT0Y, Value: -1},
 				ButtonDown:  HatEvent{Axis: linux.ABS_HAT0Y, Value: 1},
@@ -7,10 +7,13 @@ import (
 )
 
 func NewXBox360() VirtualGamepad {
+	return newXBox360(virtual_device.NewVirtualDevice())
+}
+
+func newXBox360(device virtual_device.VirtualDevice) VirtualGamepad {
 	return NewVirtualGamepadFactory().
 		WithDevice(
-			virtual_device.
-				NewVirtualDevice().
+			device.
 				WithBusType(linux.BUS_USB).
 				WithVendor(sdl.USB_VENDOR_MICROSOFT).
 				WithProduct(sdl.USB_PRODUCT_XBOX360_XUSB_CONTROLLER).
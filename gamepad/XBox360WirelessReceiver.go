@@ -0,0 +1,79 @@
+package gamepad
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/sdl"
+)
+
+// XBox360WirelessReceiverPorts is the number of controllers of a receiver.
+const XBox360WirelessReceiverPorts = 4
+
+// NewXBox360WirelessReceiver creates an Xbox 360 wireless receiver for Windows.
+// xpad creates a node per connected controller, on the USB interfaces 0, 2, 4 and 6.
+func NewXBox360WirelessReceiver() Adapter {
+	devices := make([]virtual_device.VirtualDevice, XBox360WirelessReceiverPorts)
+	for i := range devices {
+		devices[i] = virtual_device.
+			NewVirtualDevice().
+			WithBusType(linux.BUS_USB).
+			WithVendor(sdl.USB_VENDOR_MICROSOFT).
+			WithProduct(sdl.USB_PRODUCT_XBOX360_WIRELESS_RECEIVER).
+			WithVersion(0x100).
+			WithName("Xbox 360 Wireless Receiver")
+	}
+	return newXBox360WirelessReceiver(devices)
+}
+
+func newXBox360WirelessReceiver(devices []virtual_device.VirtualDevice) Adapter {
+	gamepads := make([]VirtualGamepad, len(devices))
+	for i, device := range devices {
+		gamepads[i] = newXBox360Wireless(device)
+	}
+	return newAdapter("xbox360-receiver", 2, gamepads)
+}
+
+// newXBox360Wireless maps the controllers of the receiver, xpad reporting their D-pad as buttons only.
+func newXBox360Wireless(device virtual_device.VirtualDevice) VirtualGamepad {
+	return NewVirtualGamepadFactory().
+		WithDevice(device).
+		WithDigital(
+			MappingDigital{
+				ButtonSouth: linux.BTN_SOUTH,
+				ButtonEast:  linux.BTN_EAST,
+				ButtonNorth: linux.BTN_WEST,
+				ButtonWest:  linux.BTN_NORTH,
+
+				ButtonSelect: linux.BTN_SELECT,
+				ButtonStart:  linux.BTN_START,
+				ButtonMode:   linux.BTN_MODE, // button XBox
+
+				ButtonUp:    linux.BTN_TRIGGER_HAPPY3,
+				ButtonDown:  linux.BTN_TRIGGER_HAPPY4,
+				ButtonLeft:  linux.BTN_TRIGGER_HAPPY1,
+				ButtonRight: linux.BTN_TRIGGER_HAPPY2,
+
+				ButtonL1: linux.BTN_TL,
+				ButtonR1: linux.BTN_TR,
+
+				ButtonL2: virtual_device.AbsAxis{Axis: linux.ABS_Z, Min: 0, Value: 0, Max: 255},
+				ButtonR2: virtual_device.AbsAxis{Axis: linux.ABS_RZ, Min: 0, Value: 0, Max: 255},
+
+				ButtonL3: linux.BTN_THUMBL,
+				ButtonR3: linux.BTN_THUMBR,
+			},
+		).
+		WithLeftStick(
+			MappingStick{
+				X: virtual_device.AbsAxis{Axis: linux.ABS_X, Min: -32768, Value: 0, Max: 32767, Flat: 128, Fuzz: 16},
+				Y: virtual_device.AbsAxis{Axis: linux.ABS_Y, Min: -32768, Value: 0, Max: 32767, Flat: 128, Fuzz: 16},
+			},
+		).
+		WithRightStick(
+			MappingStick{
+				X: virtual_device.AbsAxis{Axis: linux.ABS_RX, Min: -32768, Value: 0, Max: 32767, Flat: 128, Fuzz: 16},
+				Y: virtual_device.AbsAxis{Axis: linux.ABS_RY, Min: -32768, Value: 0, Max: 32767, Flat: 128, Fuzz: 16},
+			},
+		).
+		Create()
+}
@@ -7,10 +7,13 @@ import (
 )
 
 func NewXBoxOneElite2() VirtualGamepad {
+	return newXBoxOneElite2(virtual_device.NewVirtualDevice())
+}
+
+func newXBoxOneElite2(device virtual_device.VirtualDevice) VirtualGamepad {
 	return NewVirtualGamepadFactory().
 		WithDevice(
-			virtual_device.
-				NewVirtualDevice().
+			device.
 				WithBusType(linux.BUS_USB).
 				WithVendor(sdl.USB_VENDOR_MICROSOFT).
 				WithProduct(sdl.USB_PRODUCT_XBOX_ONE_ELITE_SERIES_2).
@@ -42,10 +45,10 @@ func NewXBoxOneElite2() VirtualGamepad {
 				ButtonL3: linux.BTN_THUMBL,
 				ButtonR3: linux.BTN_THUMBR,
 
-				ButtonFiller1: linux.BTN_TRIGGER_HAPPY5, // P1
-				ButtonFiller2: linux.BTN_TRIGGER_HAPPY6, // P2
-				ButtonFiller3: linux.BTN_TRIGGER_HAPPY7, // P3
-				ButtonFiller4: linux.BTN_TRIGGER_HAPPY8, // P4
+				ButtonPaddle1: linux.BTN_TRIGGER_HAPPY5, // P1
+				ButtonPaddle2: linux.BTN_TRIGGER_HAPPY6, // P2
+				ButtonPaddle3: linux.BTN_TRIGGER_HAPPY7, // P3
+				ButtonPaddle4: linux.BTN_TRIGGER_HAPPY8, // P4
 
 			},
 		).
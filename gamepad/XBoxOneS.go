@@ -7,10 +7,13 @@ import (
 )
 
 func NewXBoxOneS() VirtualGamepad {
+	return newXBoxOneS(virtual_device.NewVirtualDevice())
+}
+
+func newXBoxOneS(device virtual_device.VirtualDevice) VirtualGamepad {
 	return NewVirtualGamepadFactory().
 		WithDevice(
-			virtual_device.
-				NewVirtualDevice().
+			device.
 				WithBusType(linux.BUS_USB).
 				WithVendor(sdl.USB_VENDOR_MICROSOFT).
 				WithProduct(sdl.USB_PRODUCT_XBOX_ONE_S).
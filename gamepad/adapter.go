@@ -0,0 +1,132 @@
+package gamepad
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Adapter is a multi-port adapter, such as a GameCube adapter or an Xbox 360 wireless receiver.
+// As with the real adapters, each port has its own evdev node, created when a controller connects and removed
+// when it disconnects. The nodes share the identity of the adapter and a phys differing by its interface suffix.
+type Adapter interface {
+	// Ports returns the number of ports, numbered from 1.
+	Ports() int
+
+	// Connect plugs a controller in a port, registering its gamepad.
+	Connect(port int) error
+	// Disconnect unplugs the controller of a port, releasing its inputs and unregistering its gamepad.
+	Disconnect(port int) error
+	IsConnected(port int) bool
+
+	// Gamepad returns the gamepad of a port, nil for an unknown port.
+	Gamepad(port int) VirtualGamepad
+	Phys(port int) string
+
+	// Close disconnects all the ports.
+	Close() error
+}
+
+type adapterPort struct {
+	gamepad   VirtualGamepad
+	phys      string
+	connected bool
+}
+
+type adapter struct {
+	mu    sync.Mutex
+	ports []*adapterPort
+}
+
+// newAdapter gives the gamepads the phys of the adapter, port i using the interface i * interfaceStep.
+func newAdapter(model string, interfaceStep int, gamepads []VirtualGamepad) Adapter {
+	path := newCompositePath(model)
+	a := &adapter{}
+	for i, gp := range gamepads {
+		phys := fmt.Sprintf("%s/input%d", path, i*interfaceStep)
+		gp.Device().WithPhys(phys)
+		a.ports = append(a.ports, &adapterPort{gamepad: gp, phys: phys})
+	}
+	return a
+}
+
+func (a *adapter) Ports() int {
+	return len(a.ports)
+}
+
+func (a *adapter) port(port int) (*adapterPort, error) {
+	if port < 1 || port > len(a.ports) {
+		return nil, fmt.Errorf("unknown port %d", port)
+	}
+	return a.ports[port-1], nil
+}
+
+func (a *adapter) Connect(port int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	p, err := a.port(port)
+	if err != nil {
+		return err
+	}
+	if p.connected {
+		return nil
+	}
+	if err := p.gamepad.Register(); err != nil {
+		return err
+	}
+	p.connected = true
+	return nil
+}
+
+func (a *adapter) Disconnect(port int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	p, err := a.port(port)
+	if err != nil {
+		return err
+	}
+	return p.disconnect()
+}
+
+// disconnect releases the inputs first, so that a controller connected again starts at rest.
+func (p *adapterPort) disconnect() error {
+	if !p.connected {
+		return nil
+	}
+	p.gamepad.SetState(GamepadState{})
+	p.connected = false
+	return p.gamepad.Unregister()
+}
+
+func (a *adapter) IsConnected(port int) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	p, err := a.port(port)
+	return err == nil && p.connected
+}
+
+func (a *adapter) Gamepad(port int) VirtualGamepad {
+	p, err := a.port(port)
+	if err != nil {
+		return nil
+	}
+	return p.gamepad
+}
+
+func (a *adapter) Phys(port int) string {
+	p, err := a.port(port)
+	if err != nil {
+		return ""
+	}
+	return p.phys
+}
+
+func (a *adapter) Close() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var errs []error
+	for _, p := range a.ports {
+		errs = append(errs, p.disconnect())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,131 @@
+package gamepad
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+func newMockPorts(count int) ([]virtual_device.VirtualDevice, []*vdtest.Device) {
+	devices := make([]virtual_device.VirtualDevice, count)
+	mocks := make([]*vdtest.Device, count)
+	for i := range devices {
+		mocks[i] = vdtest.NewDevice()
+		devices[i] = mocks[i]
+	}
+	return devices, mocks
+}
+
+func TestAdapter_Phys(t *testing.T) {
+	devices, mocks := newMockPorts(XBox360WirelessReceiverPorts)
+	receiver := newXBox360WirelessReceiver(devices)
+
+	if receiver.Ports() != 4 {
+		t.Fatalf("Ports = %d, want 4", receiver.Ports())
+	}
+	path := strings.TrimSuffix(receiver.Phys(1), "/input0")
+	for port, suffix := range []string{"/input0", "/input2", "/input4", "/input6"} {
+		if want := path + suffix; receiver.Phys(port+1) != want || mocks[port].Phys != want {
+			t.Errorf("port %d phys = %q, device %q, want %q", port+1, receiver.Phys(port+1), mocks[port].Phys, want)
+		}
+	}
+	gcDevices, _ := newMockPorts(GameCubeAdapterPorts)
+	other := newGameCubeAdapter("gcadapter", gcDevices)
+	if other.Phys(2) != strings.TrimSuffix(other.Phys(1), "/input0")+"/input1" {
+		t.Errorf("GameCube port 2 phys = %q", other.Phys(2))
+	}
+	if strings.HasPrefix(other.Phys(1), path+"/") {
+		t.Error("two adapters share their phys")
+	}
+	if receiver.Gamepad(0) != nil || receiver.Gamepad(5) != nil || receiver.Phys(5) != "" {
+		t.Error("unknown ports must have no gamepad nor phys")
+	}
+}
+
+func TestAdapter_ConnectDisconnect(t *testing.T) {
+	devices, mocks := newMockPorts(GameCubeAdapterPorts)
+	a := newGameCubeAdapter("gcadapter", devices)
+
+	for _, mock := range mocks {
+		if mock.Registered() {
+			t.Fatal("no port is connected before Connect")
+		}
+	}
+	if err := a.Connect(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Connect(3); err != nil {
+		t.Fatal(err)
+	}
+	if !mocks[0].Registered() || mocks[1].Registered() || !mocks[2].Registered() || !a.IsConnected(3) {
+		t.Error("ports 1 and 3 must be connected")
+	}
+
+	a.Gamepad(1).Press(ButtonSouth)
+	a.Gamepad(3).Press(ButtonSouth)
+	if err := a.Disconnect(1); err != nil {
+		t.Fatal(err)
+	}
+	if mocks[0].Registered() || a.IsConnected(1) {
+		t.Error("port 1 must be disconnected")
+	}
+	if mocks[0].State().IsButtonPressed(linux.BTN_SOUTH) {
+		t.Error("the inputs of a disconnected port must be released")
+	}
+	if !mocks[2].Registered() || !mocks[2].State().IsButtonPressed(linux.BTN_SOUTH) {
+		t.Error("port 3 must be left untouched")
+	}
+
+	if err := a.Connect(1); err != nil || !mocks[0].Registered() {
+		t.Errorf("port 1 must connect again: %v", err)
+	}
+	if err := a.Connect(5); err == nil {
+		t.Error("Connect must fail on an unknown port")
+	}
+
+	if err := a.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for i, mock := range mocks {
+		if mock.Registered() {
+			t.Errorf("port %d still connected after Close", i+1)
+		}
+	}
+}
+
+func TestAdapter_ConnectError(t *testing.T) {
+	devices, mocks := newMockPorts(GameCubeAdapterPorts)
+	mocks[1].FailRegister(errors.New("no uinput"))
+	a := newGameCubeAdapter("gcadapter", devices)
+
+	if err := a.Connect(2); err == nil {
+		t.Fatal("Connect should fail")
+	}
+	if a.IsConnected(2) {
+		t.Error("port 2 must stay disconnected")
+	}
+}
+
+func TestGameCubeController(t *testing.T) {
+	mock := vdtest.NewDevice()
+	gc := newGameCubeController(mock)
+
+	// L and R are analog, clicking at the end of their travel
+	gc.PressAnalog(ButtonL2, 0.5)
+	mock.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_Z, 127))
+	gc.PressAnalog(ButtonL2, 1)
+	mock.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_Z, 255), vdtest.Button(linux.BTN_TL2, 1))
+	gc.MoveTrigger(0, 1)
+	mock.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_Z, 0), vdtest.Button(linux.BTN_TL2, 0), vdtest.Abs(linux.ABS_RZ, 255), vdtest.Button(linux.BTN_TR2, 1))
+
+	// the C-stick is the right stick
+	gc.MoveRightStick(1, -1)
+	mock.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_RX, 255), vdtest.Abs(linux.ABS_RY, 0))
+
+	gc.Press(ButtonR1)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_TR, 1))
+}
@@ -0,0 +1,463 @@
+package gamepad
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Turbo repeats the presses of a held button.
+type Turbo struct {
+	Rate      float64 // presses per second, 0 disables the turbo
+	DutyCycle float32 // part of each period the button is pressed, DefaultTurboDutyCycle when 0
+}
+
+// DefaultTurboDutyCycle is the duty cycle of a turbo configured without one.
+const DefaultTurboDutyCycle = 0.5
+
+// periods returns how long the button is pressed then released on each period.
+func (t Turbo) periods() (on, off time.Duration) {
+	duty := t.DutyCycle
+	if duty <= 0 || duty >= 1 {
+		duty = DefaultTurboDutyCycle
+	}
+	period := time.Duration(float64(time.Second) / min(t.Rate, MaxTurboRate))
+	on = time.Duration(float64(period) * float64(duty))
+	return on, period - on
+}
+
+// MaxTurboRate is the highest turbo rate, in presses per second, a higher one being clamped.
+const MaxTurboRate = 1000
+
+type macroAction int
+
+const (
+	macroPress macroAction = iota
+	macroRelease
+	macroWait
+	macroLeftStick
+	macroRightStick
+)
+
+// MacroStep is a step of a macro, built with MacroPress, MacroRelease, MacroWait, MacroMoveLeftStick or MacroMoveRightStick.
+type MacroStep struct {
+	action   macroAction
+	button   Button
+	x, y     float32
+	duration time.Duration
+}
+
+// MacroPress presses a button, without its turbo, toggle nor macro.
+func MacroPress(button Button) MacroStep {
+	return MacroStep{action: macroPress, button: button}
+}
+
+// MacroRelease releases a button.
+func MacroRelease(button Button) MacroStep {
+	return MacroStep{action: macroRelease, button: button}
+}
+
+// MacroWait waits before the next step.
+func MacroWait(duration time.Duration) MacroStep {
+	return MacroStep{action: macroWait, duration: duration}
+}
+
+// MacroMoveLeftStick moves the left stick.
+func MacroMoveLeftStick(x, y float32) MacroStep {
+	return MacroStep{action: macroLeftStick, x: x, y: y}
+}
+
+// MacroMoveRightStick moves the right stick.
+func MacroMoveRightStick(x, y float32) MacroStep {
+	return MacroStep{action: macroRightStick, x: x, y: y}
+}
+
+// automation holds the turbos, toggles and macros of a gamepad, run by a single goroutine while some are active.
+type automation struct {
+	turbos   map[Button]Turbo
+	toggles  map[Button]bool // buttons in toggle-hold mode
+	toggled  map[Button]bool // toggle-hold buttons currently held
+	macros   map[string][]MacroStep
+	bindings map[Button]string
+
+	turboTasks map[Button]*turboTask
+	macroTasks map[string]*macroTask
+
+	running bool          // the goroutine is started
+	wake    chan struct{} // tells the goroutine the tasks changed
+}
+
+type turboTask struct {
+	turbo    Turbo
+	pressed  bool
+	deadline time.Time
+}
+
+type macroTask struct {
+	steps    []MacroStep
+	next     int
+	deadline time.Time
+	pressed  map[Button]bool
+	sticks   [2]bool // left and right sticks moved by the macro
+}
+
+// SetTurbo configures the turbo of a button, a zero Rate disabling it.
+// The turbo runs while the button is held, from Press to Release or while it is toggled.
+// The rate is clamped to MaxTurboRate.
+func (vg *virtualGamepad) SetTurbo(button Button, turbo Turbo) {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
+	a := &vg.automation
+	if !(turbo.Rate > 0) { // NaN disables the turbo as well
+		delete(a.turbos, button)
+		if task, running := a.turboTasks[button]; running {
+			delete(a.turboTasks, button)
+			if !task.pressed {
+				vg.press(button)
+			}
+			vg.wakeAutomation()
+		}
+		return
+	}
+	if a.turbos == nil {
+		a.turbos = map[Button]Turbo{}
+	}
+	a.turbos[button] = turbo
+	if task, running := a.turboTasks[button]; running {
+		task.turbo = turbo
+	}
+}
+
+// SetToggle enables the toggle-hold mode of a button: Press holds it until the next Press, Release is ignored.
+func (vg *virtualGamepad) SetToggle(button Button, enabled bool) {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
+	a := &vg.automation
+	if enabled {
+		if a.toggles == nil {
+			a.toggles = map[Button]bool{}
+		}
+		a.toggles[button] = true
+		return
+	}
+	delete(a.toggles, button)
+	if a.toggled[button] {
+		delete(a.toggled, button)
+		vg.releaseHeld(button)
+	}
+}
+
+// SetMacro defines a named macro, replacing the previous one. Without steps, the macro is removed.
+func (vg *virtualGamepad) SetMacro(name string, steps ...MacroStep) {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
+	a := &vg.automation
+	if len(steps) == 0 {
+		delete(a.macros, name)
+		return
+	}
+	if a.macros == nil {
+		a.macros = map[string][]MacroStep{}
+	}
+	a.macros[name] = append([]MacroStep{}, steps...)
+}
+
+// BindMacro runs a macro when the button is pressed, instead of pressing it. An empty name removes the binding.
+// The macro must be defined by SetMacro first.
+func (vg *virtualGamepad) BindMacro(button Button, name string) error {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
+	a := &vg.automation
+	if name == "" {
+		delete(a.bindings, button)
+		return nil
+	}
+	if _, exist := a.macros[name]; !exist {
+		return fmt.Errorf("macro not defined (%s)", name)
+	}
+	if a.bindings == nil {
+		a.bindings = map[Button]string{}
+	}
+	a.bindings[button] = name
+	return nil
+}
+
+// RunMacro starts a macro, unless it is already running. Its first steps are applied before RunMacro returns.
+func (vg *virtualGamepad) RunMacro(name string) error {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
+	return vg.startMacro(name)
+}
+
+// StopMacro stops a running macro, releasing the buttons it holds and centering the sticks it moved.
+func (vg *virtualGamepad) StopMacro(name string) {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
+	if task, running := vg.automation.macroTasks[name]; running {
+		vg.stopMacroTask(name, task)
+	}
+}
+
+// IsMacroRunning reports whether a macro is running.
+func (vg *virtualGamepad) IsMacroRunning(name string) bool {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
+	_, running := vg.automation.macroTasks[name]
+	return running
+}
+
+func (vg *virtualGamepad) pressLogical(button Button) {
+	a := &vg.automation
+	if name, bound := a.bindings[button]; bound {
+		if _, exist := a.macros[name]; !exist {
+			fmt.Printf("macro not defined (%s)\n", name) // removed by SetMacro after BindMacro
+			return
+		}
+		_ = vg.startMacro(name)
+		return
+	}
+	if a.toggles[button] {
+		if a.toggled[button] {
+			delete(a.toggled, button)
+			vg.releaseHeld(button)
+			return
+		}
+		if a.toggled == nil {
+			a.toggled = map[Button]bool{}
+		}
+		a.toggled[button] = true
+	}
+	vg.pressHeld(button)
+}
+
+func (vg *virtualGamepad) releaseLogical(button Button) {
+	a := &vg.automation
+	if _, bound := a.bindings[button]; bound || a.toggles[button] {
+		return
+	}
+	vg.releaseHeld(button)
+}
+
+// pressHeld presses a button held by the user, starting its turbo when it has one.
+func (vg *virtualGamepad) pressHeld(button Button) {
+	a := &vg.automation
+	turbo, ok := a.turbos[button]
+	if !ok {
+		vg.press(button)
+		return
+	}
+	if _, running := a.turboTasks[button]; running {
+		return
+	}
+	if a.turboTasks == nil {
+		a.turboTasks = map[Button]*turboTask{}
+	}
+	on, _ := turbo.periods()
+	a.turboTasks[button] = &turboTask{turbo: turbo, pressed: true, deadline: vg.clock.Now().Add(on)}
+	vg.press(button)
+	vg.wakeAutomation()
+}
+
+// releaseHeld releases a button held by the user, stopping its turbo.
+func (vg *virtualGamepad) releaseHeld(button Button) {
+	a := &vg.automation
+	if _, running := a.turboTasks[button]; running {
+		delete(a.turboTasks, button)
+		vg.wakeAutomation()
+	}
+	vg.release(button)
+}
+
+func (vg *virtualGamepad) startMacro(name string) error {
+	a := &vg.automation
+	steps, exist := a.macros[name]
+	if !exist {
+		return fmt.Errorf("macro not defined (%s)", name)
+	}
+	if _, running := a.macroTasks[name]; running {
+		return nil
+	}
+	if a.macroTasks == nil {
+		a.macroTasks = map[string]*macroTask{}
+	}
+	task := &macroTask{steps: steps, deadline: vg.clock.Now(), pressed: map[Button]bool{}}
+	a.macroTasks[name] = task
+	vg.runMacroTask(name, task)
+	vg.wakeAutomation()
+	return nil
+}
+
+// runMacroTask applies the steps of a macro until the next wait, or its end.
+func (vg *virtualGamepad) runMacroTask(name string, task *macroTask) {
+	for task.next < len(task.steps) {
+		step := task.steps[task.next]
+		task.next++
+		switch step.action {
+		case macroPress:
+			vg.press(step.button)
+			task.pressed[step.button] = true
+		case macroRelease:
+			vg.release(step.button)
+			delete(task.pressed, step.button)
+		case macroLeftStick:
+			vg.moveLeftStick(step.x, step.y)
+			task.sticks[0] = true
+		case macroRightStick:
+			vg.moveRightStick(step.x, step.y)
+			task.sticks[1] = true
+		case macroWait:
+			task.deadline = task.deadline.Add(step.duration)
+			return
+		}
+	}
+	delete(vg.automation.macroTasks, name)
+}
+
+func (vg *virtualGamepad) stopMacroTask(name string, task *macroTask) {
+	delete(vg.automation.macroTasks, name)
+	buttons := make([]Button, 0, len(task.pressed))
+	for button := range task.pressed {
+		buttons = append(buttons, button)
+	}
+	sort.Slice(buttons, func(i, j int) bool { return buttons[i] < buttons[j] })
+	for _, button := range buttons {
+		vg.release(button)
+	}
+	if task.sticks[0] {
+		vg.moveLeftStick(0, 0)
+	}
+	if task.sticks[1] {
+		vg.moveRightStick(0, 0)
+	}
+	vg.wakeAutomation()
+}
+
+// stopAutomation stops the turbos, the toggles and the macros, releasing their buttons.
+func (vg *virtualGamepad) stopAutomation() {
+	a := &vg.automation
+	for name, task := range a.macroTasks {
+		vg.stopMacroTask(name, task)
+	}
+	for button := range a.turboTasks {
+		vg.releaseHeld(button)
+	}
+	for button := range a.toggled {
+		delete(a.toggled, button)
+		vg.releaseHeld(button)
+	}
+}
+
+// wakeAutomation starts the goroutine, or tells it the tasks changed.
+func (vg *virtualGamepad) wakeAutomation() {
+	a := &vg.automation
+	if a.wake == nil {
+		a.wake = make(chan struct{}, 1)
+	}
+	if !a.running {
+		if len(a.turboTasks) == 0 && len(a.macroTasks) == 0 {
+			return
+		}
+		a.running = true
+		go vg.runAutomation(a.wake)
+		return
+	}
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+}
+
+// runAutomation runs the due tasks and sleeps until the next deadline, ending once no task is left.
+func (vg *virtualGamepad) runAutomation(wake chan struct{}) {
+	for {
+		vg.mu.Lock()
+		now := vg.clock.Now()
+		next, ok := vg.runDueTasks(now)
+		if !ok {
+			vg.automation.running = false
+			vg.mu.Unlock()
+			return
+		}
+		vg.mu.Unlock()
+
+		timer := vg.clock.NewTimer(next.Sub(now))
+		select {
+		case <-timer.C():
+		case <-wake:
+			timer.Stop()
+		}
+	}
+}
+
+// runDueTasks runs the tasks whose deadline is reached and returns the next deadline, false when no task is left.
+// The deadlines follow each other from the start of the task, so the timing does not drift.
+func (vg *virtualGamepad) runDueTasks(now time.Time) (time.Time, bool) {
+	a := &vg.automation
+	var next time.Time
+	earliest := func(deadline time.Time) {
+		if next.IsZero() || deadline.Before(next) {
+			next = deadline
+		}
+	}
+
+	buttons := make([]Button, 0, len(a.turboTasks))
+	for button := range a.turboTasks {
+		buttons = append(buttons, button)
+	}
+	sort.Slice(buttons, func(i, j int) bool { return buttons[i] < buttons[j] })
+	for _, button := range buttons {
+		task := a.turboTasks[button]
+		if !task.deadline.After(now) {
+			pressed := task.pressed
+			task.catchUp(now)
+			if task.pressed != pressed {
+				if task.pressed {
+					vg.press(button)
+				} else {
+					vg.release(button)
+				}
+			}
+		}
+		earliest(task.deadline)
+	}
+
+	names := make([]string, 0, len(a.macroTasks))
+	for name := range a.macroTasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		task := a.macroTasks[name]
+		for !task.deadline.After(now) {
+			vg.runMacroTask(name, task)
+			if _, running := a.macroTasks[name]; !running {
+				break
+			}
+		}
+		if _, running := a.macroTasks[name]; running {
+			earliest(task.deadline)
+		}
+	}
+
+	return next, !next.IsZero()
+}
+
+// catchUp moves a due turbo task to the phase it is in at now, with the deadline of its next change.
+// When late, the missed periods are skipped: the button only ends in the state of the current phase.
+func (task *turboTask) catchUp(now time.Time) {
+	on, off := task.turbo.periods()
+	period := on + off
+	start := task.deadline // start of the period, when the button is pressed
+	if task.pressed {
+		start = start.Add(-on)
+	}
+	elapsed := now.Sub(start)
+	start = start.Add(elapsed / period * period)
+	if elapsed%period < on {
+		task.pressed = true
+		task.deadline = start.Add(on)
+	} else {
+		task.pressed = false
+		task.deadline = start.Add(period)
+	}
+}
@@ -0,0 +1,242 @@
+package gamepad
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/clock"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+func newAutomationGamepad(mock *vdtest.Device, fake *clock.Fake) VirtualGamepad {
+	return NewVirtualGamepadFactory().
+		WithDevice(mock).
+		WithDigital(MappingDigital{
+			ButtonSouth: linux.BTN_SOUTH,
+			ButtonEast:  linux.BTN_EAST,
+			ButtonNorth: linux.BTN_NORTH,
+		}).
+		WithLeftStick(MappingStick{
+			X: virtual_device.AbsAxis{Axis: linux.ABS_X, Min: -32768, Max: 32767},
+			Y: virtual_device.AbsAxis{Axis: linux.ABS_Y, Min: -32768, Max: 32767},
+		}).
+		WithClock(fake).
+		Create()
+}
+
+// advance moves the fake clock once the automation goroutine sleeps, and waits for it to sleep again.
+func advance(fake *clock.Fake, d time.Duration) {
+	fake.BlockUntil(1)
+	fake.Advance(d)
+}
+
+func TestGamepad_Turbo(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	mock := vdtest.NewDevice()
+	gp := newAutomationGamepad(mock, fake)
+
+	gp.SetTurbo(ButtonSouth, Turbo{Rate: 10, DutyCycle: 0.25}) // 25ms pressed, 75ms released
+	gp.Press(ButtonSouth)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_SOUTH, 1))
+
+	advance(fake, 25*time.Millisecond)
+	fake.BlockUntil(1)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_SOUTH, 0))
+
+	advance(fake, 75*time.Millisecond)
+	fake.BlockUntil(1)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_SOUTH, 1))
+
+	gp.Release(ButtonSouth)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_SOUTH, 0))
+
+	// the turbo is stopped: the pending wake-up does nothing
+	fake.Advance(time.Second)
+	gp.Press(ButtonEast)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_EAST, 1))
+	mock.ExpectNoFrame(t)
+}
+
+func TestGamepad_TurboSkipsMissedPeriods(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	mock := vdtest.NewDevice()
+	gp := newAutomationGamepad(mock, fake)
+
+	gp.SetTurbo(ButtonSouth, Turbo{Rate: 10})
+	gp.Press(ButtonSouth)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_SOUTH, 1))
+
+	// 3.5 periods later, the button is released once
+	advance(fake, 350*time.Millisecond)
+	fake.BlockUntil(1)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_SOUTH, 0))
+	mock.ExpectNoFrame(t)
+	gp.Release(ButtonSouth)
+}
+
+func TestGamepad_TurboHugeRate(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	mock := vdtest.NewDevice()
+	gp := newAutomationGamepad(mock, fake)
+
+	gp.SetTurbo(ButtonSouth, Turbo{Rate: math.Inf(1)}) // clamped to 500µs pressed, 500µs released
+	gp.Press(ButtonSouth)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_SOUTH, 1))
+
+	advance(fake, 500*time.Microsecond)
+	fake.BlockUntil(1)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_SOUTH, 0))
+
+	// an hour late, the millions of missed periods are skipped at once
+	advance(fake, time.Hour+500*time.Microsecond)
+	fake.BlockUntil(1)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_SOUTH, 1))
+	mock.ExpectNoFrame(t)
+	gp.Release(ButtonSouth)
+}
+
+func TestGamepad_Toggle(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	mock := vdtest.NewDevice()
+	gp := newAutomationGamepad(mock, fake)
+
+	gp.SetToggle(ButtonNorth, true)
+	gp.Press(ButtonNorth)
+	gp.Release(ButtonNorth)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_NORTH, 1))
+	mock.ExpectNoFrame(t)
+
+	gp.Press(ButtonNorth)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_NORTH, 0))
+
+	gp.Press(ButtonNorth)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_NORTH, 1))
+	gp.SetToggle(ButtonNorth, false)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_NORTH, 0))
+}
+
+func TestGamepad_ToggleTurbo(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	mock := vdtest.NewDevice()
+	gp := newAutomationGamepad(mock, fake)
+
+	gp.SetToggle(ButtonSouth, true)
+	gp.SetTurbo(ButtonSouth, Turbo{Rate: 20})
+	gp.Press(ButtonSouth)
+	gp.Release(ButtonSouth)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_SOUTH, 1))
+
+	advance(fake, 25*time.Millisecond)
+	fake.BlockUntil(1)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_SOUTH, 0))
+
+	gp.Press(ButtonSouth)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_SOUTH, 0))
+	mock.ExpectNoFrame(t)
+}
+
+func TestGamepad_Macro(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	mock := vdtest.NewDevice()
+	gp := newAutomationGamepad(mock, fake)
+
+	gp.SetMacro("dash",
+		MacroMoveLeftStick(1, 0),
+		MacroPress(ButtonEast),
+		MacroWait(50*time.Millisecond),
+		MacroRelease(ButtonEast),
+		MacroMoveLeftStick(0, 0),
+	)
+	if err := gp.BindMacro(ButtonNorth, "dash"); err != nil {
+		t.Fatal(err)
+	}
+
+	gp.Press(ButtonNorth)
+	gp.Release(ButtonNorth)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_X, 32767), vdtest.Abs(linux.ABS_Y, 0))
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_EAST, 1))
+	mock.ExpectNoFrame(t)
+	if !gp.IsMacroRunning("dash") {
+		t.Fatal("macro should be running")
+	}
+
+	advance(fake, 50*time.Millisecond)
+	for gp.IsMacroRunning("dash") {
+		time.Sleep(time.Millisecond)
+	}
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_EAST, 0))
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_X, 0), vdtest.Abs(linux.ABS_Y, 0))
+
+	if err := gp.RunMacro("unknown"); err == nil {
+		t.Error("RunMacro should fail on an unknown macro")
+	}
+}
+
+func TestGamepad_BindUndefinedMacro(t *testing.T) {
+	mock := vdtest.NewDevice()
+	gp := newAutomationGamepad(mock, clock.NewFake(time.Now()))
+
+	if err := gp.BindMacro(ButtonNorth, "missing"); err == nil {
+		t.Fatal("BindMacro should fail on an undefined macro")
+	}
+	gp.Press(ButtonNorth)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_NORTH, 1))
+}
+
+func TestGamepad_StopMacro(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	mock := vdtest.NewDevice()
+	gp := newAutomationGamepad(mock, fake)
+
+	gp.SetMacro("hold", MacroPress(ButtonSouth), MacroMoveLeftStick(0, 1), MacroWait(time.Hour))
+	if err := gp.RunMacro("hold"); err != nil {
+		t.Fatal(err)
+	}
+	fake.BlockUntil(1)
+	gp.StopMacro("hold")
+
+	state := mock.State()
+	if state.IsButtonPressed(linux.BTN_SOUTH) || state.AbsValue(linux.ABS_Y) != 0 {
+		t.Errorf("macro inputs must be released on stop: %+v", state)
+	}
+	if gp.IsMacroRunning("hold") {
+		t.Error("macro should be stopped")
+	}
+}
+
+func TestGamepad_AutomationWithDirectCalls(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	mock := vdtest.NewDevice()
+	gp := newAutomationGamepad(mock, fake)
+
+	gp.SetTurbo(ButtonSouth, Turbo{Rate: 30})
+	gp.Press(ButtonSouth)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			gp.Press(ButtonEast)
+			gp.MoveLeftStick(0.5, 0.5)
+			gp.Release(ButtonEast)
+			_ = gp.State()
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		advance(fake, 10*time.Millisecond)
+	}
+	wg.Wait()
+
+	gp.Release(ButtonSouth)
+	if mock.State().IsButtonPressed(linux.BTN_SOUTH) || mock.State().IsButtonPressed(linux.BTN_EAST) {
+		t.Error("buttons should be released")
+	}
+	if err := gp.Unregister(); err != nil {
+		t.Fatal(err)
+	}
+}
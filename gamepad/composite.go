@@ -0,0 +1,49 @@
+package gamepad
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// node is an evdev node of a composite device.
+type node interface {
+	Register() error
+	Unregister() error
+}
+
+// compositeCounter makes each generated phys unique within the process.
+var compositeCounter atomic.Int64
+
+// newCompositePhys returns a phys unique to this process, shared by all the nodes of a composite device.
+// uinput can set the phys of a node but not its uniq, so the phys is what ties the nodes together.
+func newCompositePhys(model string) string {
+	return newCompositePath(model) + "/input0"
+}
+
+// newCompositePath returns the phys of a device without its interface suffix, unique to this process.
+func newCompositePath(model string) string {
+	return fmt.Sprintf("virtual-device-%s-%d-%d", model, os.Getpid(), compositeCounter.Add(1))
+}
+
+// registerNodes registers the nodes in order, unregistering the registered ones if one fails.
+func registerNodes(nodes ...node) error {
+	for i, n := range nodes {
+		if err := n.Register(); err != nil {
+			return errors.Join(err, unregisterNodes(nodes[:i]...))
+		}
+	}
+	return nil
+}
+
+// unregisterNodes unregisters the nodes in reverse order, returning all the errors.
+func unregisterNodes(nodes ...node) error {
+	var errs []error
+	for i := len(nodes) - 1; i >= 0; i-- {
+		if err := nodes[i].Unregister(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
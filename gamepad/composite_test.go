@@ -0,0 +1,124 @@
+package gamepad
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/touchpad"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+func TestSonyPS4Composite_SharedPhys(t *testing.T) {
+	pad, touch, motion := vdtest.NewDevice(), vdtest.NewDevice(), vdtest.NewDevice()
+	ds4 := newSonyPS4Composite(pad, touch, motion)
+
+	if ds4.Phys() == "" {
+		t.Fatal("expected a phys")
+	}
+	for name, device := range map[string]*vdtest.Device{"pad": pad, "touchpad": touch, "motion": motion} {
+		if device.Phys != ds4.Phys() {
+			t.Errorf("%s phys = %q, want %q", name, device.Phys, ds4.Phys())
+		}
+	}
+	if other := newSonyPS4Composite(vdtest.NewDevice(), vdtest.NewDevice(), vdtest.NewDevice()); other.Phys() == ds4.Phys() {
+		t.Errorf("two composites share the phys %q", ds4.Phys())
+	}
+}
+
+func TestSonyPS4Composite_Touchpad(t *testing.T) {
+	pad, touch := vdtest.NewDevice(), vdtest.NewDevice()
+	ds4 := newSonyPS4Composite(pad, touch, vdtest.NewDevice())
+
+	if len(touch.Properties) != 2 || touch.Properties[0] != linux.INPUT_PROP_POINTER || touch.Properties[1] != linux.INPUT_PROP_BUTTONPAD {
+		t.Errorf("touchpad properties = %v", touch.Properties)
+	}
+	for _, button := range pad.Buttons {
+		if button == linux.BTN_TRIGGER_HAPPY1 {
+			t.Error("the touchpad click must not be on the gamepad node")
+		}
+	}
+
+	ds4.Touchpad().MultiTouch([]touchpad.TouchSlot{{Slot: 0, X: 1, Y: 1, Pressure: 1}})
+	frame, _ := touch.NextFrame()
+	found := false
+	for _, event := range frame {
+		if event.EvType == uint16(linux.EV_ABS) && event.Code == uint16(linux.ABS_MT_POSITION_X) && event.Value == sonyPS4TouchpadWidth-1 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected ABS_MT_POSITION_X at %d in %v", sonyPS4TouchpadWidth-1, frame)
+	}
+}
+
+func TestSonyPS4Composite_RegisterRollback(t *testing.T) {
+	pad, touch, motion := vdtest.NewDevice(), vdtest.NewDevice(), vdtest.NewDevice()
+	motion.FailRegister(errors.New("no uinput"))
+	ds4 := newSonyPS4Composite(pad, touch, motion)
+
+	if err := ds4.Register(); err == nil {
+		t.Fatal("expected an error")
+	}
+	if pad.Registered() || touch.Registered() || motion.Registered() {
+		t.Error("expected every node to be unregistered")
+	}
+}
+
+func TestSonyPS4Composite_UnregisterJoinsErrors(t *testing.T) {
+	pad, touch, motion := vdtest.NewDevice(), vdtest.NewDevice(), vdtest.NewDevice()
+	errPad, errMotion := errors.New("pad"), errors.New("motion")
+	pad.FailUnregister(errPad)
+	motion.FailUnregister(errMotion)
+	ds4 := newSonyPS4Composite(pad, touch, motion)
+
+	if err := ds4.Register(); err != nil {
+		t.Fatal(err)
+	}
+	err := ds4.Unregister()
+	if !errors.Is(err, errPad) || !errors.Is(err, errMotion) {
+		t.Errorf("expected both errors, got %v", err)
+	}
+	if touch.Registered() {
+		t.Error("expected the touchpad to be unregistered")
+	}
+}
+
+func TestSonyPS5Composite_Nodes(t *testing.T) {
+	pad, touch, motion := vdtest.NewDevice(), vdtest.NewDevice(), vdtest.NewDevice()
+	ds := newSonyPS5Composite(pad, touch, motion)
+
+	for name, device := range map[string]*vdtest.Device{"pad": pad, "touchpad": touch, "motion": motion} {
+		if device.Phys != ds.Phys() {
+			t.Errorf("%s phys = %q, want %q", name, device.Phys, ds.Phys())
+		}
+	}
+	if len(pad.Switches) != 2 {
+		t.Errorf("pad switches = %v", pad.Switches)
+	}
+	if max := touch.AbsAxes[3].Max; touch.AbsAxes[3].Axis != linux.ABS_MT_POSITION_X || max != sonyPS5TouchpadWidth-1 {
+		t.Errorf("touchpad width = %d", max)
+	}
+
+	for _, button := range pad.Buttons {
+		if button == linux.BTN_TRIGGER_HAPPY1 || button == linux.BTN_TRIGGER_HAPPY2 {
+			t.Errorf("the touchpad click and the mute button must not be on the gamepad node (0x%x)", button)
+		}
+	}
+}
+
+func TestSonyPS5Composite_HeadsetJack(t *testing.T) {
+	pad := vdtest.NewDevice()
+	ds := newSonyPS5Composite(pad, vdtest.NewDevice(), vdtest.NewDevice())
+
+	ds.SetHeadphoneInserted(true)
+	ds.SetMicrophoneInserted(true)
+	ds.SetMicrophoneInserted(false)
+
+	pad.ExpectFrame(t, vdtest.Switch(linux.SW_HEADPHONE_INSERT, 1))
+	pad.ExpectFrame(t, vdtest.Switch(linux.SW_MICROPHONE_INSERT, 1))
+	pad.ExpectFrame(t, vdtest.Switch(linux.SW_MICROPHONE_INSERT, 0))
+	if !pad.State().IsSwitchOn(linux.SW_HEADPHONE_INSERT) || pad.State().IsSwitchOn(linux.SW_MICROPHONE_INSERT) {
+		t.Error("unexpected switch state")
+	}
+}
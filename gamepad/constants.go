@@ -29,8 +29,32 @@ const (
 	ButtonStart
 	ButtonMode
 
-	ButtonFiller1
-	ButtonFiller2
-	ButtonFiller3
-	ButtonFiller4
+	ButtonCapture  // Capture (Switch, Stadia) or Share (Xbox Series)
+	ButtonTouchpad // Touchpad click (PlayStation)
+	ButtonMute     // Microphone mute (DualSense)
+
+	ButtonPaddle1 // Back paddles (Xbox Elite), upper right
+	ButtonPaddle2 // upper left
+	ButtonPaddle3 // lower right
+	ButtonPaddle4 // lower left
+
+	ButtonMisc1 // Vendor specific button (Stadia Google Assistant)
+
+	ButtonSL // Side buttons of a single Joy-Con
+	ButtonSR
+)
+
+// Aliases of the system button.
+const (
+	ButtonHome  = ButtonMode
+	ButtonGuide = ButtonMode
+	ButtonShare = ButtonCapture
+)
+
+// Reserved buttons map codes advertised by the hardware but without a known physical button.
+// They are not returned by SupportedButtons.
+const (
+	buttonReserved1 Button = iota + 1000
+	buttonReserved2
+	buttonReserved3
 )
@@ -0,0 +1,170 @@
+package gamepad
+
+import "github.com/jbdemonte/virtual-device/linux"
+
+// DPadMode selects the codes emitted for the D-pad directions.
+type DPadMode int
+
+const (
+	DPadProfile DPadMode = iota // keep the mapping of the profile
+	DPadHat                     // ABS_HAT0X and ABS_HAT0Y
+	DPadButtons                 // BTN_DPAD_UP, BTN_DPAD_RIGHT, BTN_DPAD_DOWN and BTN_DPAD_LEFT
+	DPadBoth                    // hat and buttons
+)
+
+// SOCDMode selects how simultaneous opposite directions are resolved.
+type SOCDMode int
+
+const (
+	SOCDNeutral    SOCDMode = iota // opposite directions cancel each other
+	SOCDLastWins                   // the direction pressed last wins
+	SOCDUpPriority                 // up wins over down, left and right cancel each other
+)
+
+// DPad tracks the four directions of a D-pad and resolves them to a hat position.
+type DPad struct {
+	socd    SOCDMode
+	pressed [4]bool
+	order   [4]int // press sequence of each held direction, to find the last one
+	counter int
+}
+
+// dpadDirections lists the directions in the order of the DPad arrays.
+var dpadDirections = [4]Button{ButtonUp, ButtonRight, ButtonDown, ButtonLeft}
+
+// NewDPad returns a D-pad with all the directions released.
+func NewDPad(socd SOCDMode) *DPad {
+	return &DPad{socd: socd}
+}
+
+// Press holds a direction (ButtonUp, ButtonRight, ButtonDown or ButtonLeft).
+func (d *DPad) Press(direction Button) {
+	d.set(direction, true)
+}
+
+// Release releases a direction.
+func (d *DPad) Release(direction Button) {
+	d.set(direction, false)
+}
+
+// Set updates the four directions at once, the newly held ones being considered as pressed last.
+func (d *DPad) Set(up, right, down, left bool) {
+	for i, pressed := range [4]bool{up, right, down, left} {
+		d.set(dpadDirections[i], pressed)
+	}
+}
+
+// Reset releases all the directions.
+func (d *DPad) Reset() {
+	d.pressed = [4]bool{}
+	d.order = [4]int{}
+}
+
+// IsHeld reports whether a direction is held, before SOCD resolution.
+func (d *DPad) IsHeld(direction Button) bool {
+	i := dpadIndex(direction)
+	return i >= 0 && d.pressed[i]
+}
+
+// Hat returns the resolved position, diagonals included.
+func (d *DPad) Hat() Hat {
+	return Hat{
+		X: d.resolve(3, 1, false),
+		Y: d.resolve(0, 2, true),
+	}
+}
+
+func (d *DPad) set(direction Button, pressed bool) {
+	i := dpadIndex(direction)
+	if i < 0 || d.pressed[i] == pressed {
+		return
+	}
+	d.pressed[i] = pressed
+	if pressed {
+		d.counter++
+		d.order[i] = d.counter
+	}
+}
+
+// resolve returns -1, 0 or 1 for an axis, negative and positive being the indexes of its two directions.
+func (d *DPad) resolve(negative, positive int, vertical bool) int8 {
+	switch {
+	case d.pressed[negative] && d.pressed[positive]:
+		switch d.socd {
+		case SOCDLastWins:
+			if d.order[negative] > d.order[positive] {
+				return -1
+			}
+			return 1
+		case SOCDUpPriority:
+			if vertical {
+				return -1
+			}
+		}
+		return 0
+	case d.pressed[negative]:
+		return -1
+	case d.pressed[positive]:
+		return 1
+	}
+	return 0
+}
+
+// points reports whether the hat points to a direction, diagonals pointing to two directions.
+func (h Hat) points(direction Button) bool {
+	switch direction {
+	case ButtonUp:
+		return h.Y < 0
+	case ButtonRight:
+		return h.X > 0
+	case ButtonDown:
+		return h.Y > 0
+	case ButtonLeft:
+		return h.X < 0
+	}
+	return false
+}
+
+func dpadIndex(direction Button) int {
+	for i, item := range dpadDirections {
+		if item == direction {
+			return i
+		}
+	}
+	return -1
+}
+
+func isDPadDirection(button Button) bool {
+	return dpadIndex(button) >= 0
+}
+
+// dpadMapping returns the mapping of the four directions for a mode, nil for DPadProfile.
+func dpadMapping(mode DPadMode) MappingDigital {
+	hats := map[Button]HatEvent{
+		ButtonUp:    {Axis: linux.ABS_HAT0Y, Value: -1},
+		ButtonRight: {Axis: linux.ABS_HAT0X, Value: 1},
+		ButtonDown:  {Axis: linux.ABS_HAT0Y, Value: 1},
+		ButtonLeft:  {Axis: linux.ABS_HAT0X, Value: -1},
+	}
+	buttons := map[Button]linux.Button{
+		ButtonUp:    linux.BTN_DPAD_UP,
+		ButtonRight: linux.BTN_DPAD_RIGHT,
+		ButtonDown:  linux.BTN_DPAD_DOWN,
+		ButtonLeft:  linux.BTN_DPAD_LEFT,
+	}
+
+	mapping := MappingDigital{}
+	for _, direction := range dpadDirections {
+		switch mode {
+		case DPadHat:
+			mapping[direction] = hats[direction]
+		case DPadButtons:
+			mapping[direction] = buttons[direction]
+		case DPadBoth:
+			mapping[direction] = []InputEvent{buttons[direction], hats[direction]}
+		default:
+			return nil
+		}
+	}
+	return mapping
+}
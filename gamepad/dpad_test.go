@@ -0,0 +1,96 @@
+package gamepad
+
+import (
+	"testing"
+
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+func TestDPad_Diagonal(t *testing.T) {
+	d := NewDPad(SOCDNeutral)
+	d.Press(ButtonUp)
+	d.Press(ButtonLeft)
+	if hat := d.Hat(); hat != (Hat{X: -1, Y: -1}) {
+		t.Errorf("Hat = %+v, want up-left", hat)
+	}
+}
+
+func TestDPad_SOCD(t *testing.T) {
+	tests := []struct {
+		mode SOCDMode
+		want Hat
+	}{
+		{SOCDNeutral, Hat{}},
+		{SOCDLastWins, Hat{X: -1, Y: 1}},
+		{SOCDUpPriority, Hat{X: 0, Y: -1}},
+	}
+	for _, tt := range tests {
+		d := NewDPad(tt.mode)
+		d.Press(ButtonUp)
+		d.Press(ButtonRight)
+		d.Press(ButtonDown)
+		d.Press(ButtonLeft)
+		if hat := d.Hat(); hat != tt.want {
+			t.Errorf("mode %d: Hat = %+v, want %+v", tt.mode, hat, tt.want)
+		}
+	}
+}
+
+func TestDPad_LastWinsRelease(t *testing.T) {
+	d := NewDPad(SOCDLastWins)
+	d.Press(ButtonLeft)
+	d.Press(ButtonRight)
+	d.Release(ButtonRight)
+	if hat := d.Hat(); hat.X != -1 {
+		t.Errorf("Hat.X = %d, want the still held left", hat.X)
+	}
+}
+
+func TestGamepad_DPadHatStaysConsistent(t *testing.T) {
+	mock := vdtest.NewDevice()
+	gp := NewVirtualGamepadFactory().
+		WithDevice(mock).
+		WithDPad(DPadHat).
+		Create()
+
+	gp.Press(ButtonUp)
+	gp.Press(ButtonDown)
+	gp.Release(ButtonUp)
+
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_HAT0Y, -1))
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_HAT0Y, 0))
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_HAT0Y, 1))
+}
+
+func TestGamepad_DPadBoth_LastWins(t *testing.T) {
+	mock := vdtest.NewDevice()
+	gp := NewVirtualGamepadFactory().
+		WithDevice(mock).
+		WithDPad(DPadBoth).
+		WithSOCD(SOCDLastWins).
+		Create()
+
+	gp.Press(ButtonLeft)
+	gp.Press(ButtonRight)
+
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_DPAD_LEFT, 1), vdtest.Abs(linux.ABS_HAT0X, -1))
+	mock.ExpectFrame(t,
+		vdtest.Button(linux.BTN_DPAD_RIGHT, 1),
+		vdtest.Abs(linux.ABS_HAT0X, 1),
+		vdtest.Button(linux.BTN_DPAD_LEFT, 0),
+	)
+}
+
+func TestGamepad_DPadButtons_Capabilities(t *testing.T) {
+	mock := vdtest.NewDevice()
+	NewVirtualGamepadFactory().
+		WithDevice(mock).
+		WithDigital(MappingDigital{ButtonUp: HatEvent{Axis: linux.ABS_HAT0Y, Value: -1}}).
+		WithDPad(DPadButtons).
+		Create()
+
+	if len(mock.Buttons) != 4 || len(mock.AbsAxes) != 0 {
+		t.Errorf("expected 4 dpad buttons and no hat, got %v and %v", mock.Buttons, mock.AbsAxes)
+	}
+}
@@ -2,9 +2,13 @@ package gamepad
 
 import (
 	"fmt"
+	"sort"
+	"sync"
 
 	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/clock"
 	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/sdl"
 )
 
 // VirtualGamepad is a high-level virtual gamepad input device.
@@ -14,6 +18,8 @@ type VirtualGamepad interface {
 
 	Press(button Button)
 	Release(button Button)
+	PressAnalog(button Button, value float32)
+	MoveTrigger(left, right float32)
 
 	MoveLeftStick(x, y float32)
 	MoveLeftStickX(x float32)
@@ -23,9 +29,28 @@ type VirtualGamepad interface {
 	MoveRightStickX(x float32)
 	MoveRightStickY(y float32)
 
+	SupportedButtons() []Button
+
+	SetState(state GamepadState)
+	State() GamepadState
+
+	SetTurbo(button Button, turbo Turbo)
+	SetToggle(button Button, enabled bool)
+	SetMacro(name string, steps ...MacroStep)
+	BindMacro(button Button, name string) error
+	RunMacro(name string) error
+	StopMacro(name string)
+	IsMacroRunning(name string) bool
+
 	Send(evType, code uint16, value int32)
 
+	SDLMapping() (sdl.Mapping, error)
+
 	EventPath() string
+	DeviceInfo() (virtual_device.DeviceInfo, error)
+
+	// Device returns the underlying device, e.g. to set its phys or its force feedback before Register.
+	Device() virtual_device.VirtualDevice
 }
 
 // VirtualGamepadFactory configures and creates VirtualGamepad instances.
@@ -34,19 +59,37 @@ type VirtualGamepadFactory interface {
 	WithDigital(mapping MappingDigital) VirtualGamepadFactory
 	WithLeftStick(mapping MappingStick) VirtualGamepadFactory
 	WithRightStick(mapping MappingStick) VirtualGamepadFactory
+	WithLeftStickTransform(transforms ...StickTransform) VirtualGamepadFactory
+	WithRightStickTransform(transforms ...StickTransform) VirtualGamepadFactory
+	WithTriggerThreshold(threshold float32) VirtualGamepadFactory
+	WithDPad(mode DPadMode) VirtualGamepadFactory
+	WithSOCD(mode SOCDMode) VirtualGamepadFactory
+	WithClock(clock clock.Clock) VirtualGamepadFactory
 	Create() VirtualGamepad
 }
 
+// DefaultTriggerThreshold is the analog position from which the digital codes of a trigger are pressed.
+const DefaultTriggerThreshold = 0.5
+
 type virtualGamepadFactory struct {
-	device     virtual_device.VirtualDevice
-	digital    MappingDigital
-	leftStick  *MappingStick
-	rightStick *MappingStick
+	device           virtual_device.VirtualDevice
+	digital          MappingDigital
+	leftStick        *MappingStick
+	rightStick       *MappingStick
+	leftTransform    StickTransform
+	rightTransform   StickTransform
+	triggerThreshold float32
+	dpadMode         DPadMode
+	socdMode         SOCDMode
+	clock            clock.Clock
+	extra            []InputEvent // registered without being a logical button
 }
 
 // NewVirtualGamepadFactory returns a new factory for building virtual gamepads.
 func NewVirtualGamepadFactory() VirtualGamepadFactory {
-	return &virtualGamepadFactory{}
+	return &virtualGamepadFactory{
+		triggerThreshold: DefaultTriggerThreshold,
+	}
 }
 
 func (f *virtualGamepadFactory) WithDevice(device virtual_device.VirtualDevice) VirtualGamepadFactory {
@@ -69,12 +112,64 @@ func (f *virtualGamepadFactory) WithRightStick(mapping MappingStick) VirtualGame
 	return f
 }
 
+func (f *virtualGamepadFactory) WithLeftStickTransform(transforms ...StickTransform) VirtualGamepadFactory {
+	f.leftTransform = StickChain(transforms...)
+	return f
+}
+
+func (f *virtualGamepadFactory) WithRightStickTransform(transforms ...StickTransform) VirtualGamepadFactory {
+	f.rightTransform = StickChain(transforms...)
+	return f
+}
+
+func (f *virtualGamepadFactory) WithTriggerThreshold(threshold float32) VirtualGamepadFactory {
+	f.triggerThreshold = threshold
+	return f
+}
+
+func (f *virtualGamepadFactory) WithDPad(mode DPadMode) VirtualGamepadFactory {
+	f.dpadMode = mode
+	return f
+}
+
+func (f *virtualGamepadFactory) WithSOCD(mode SOCDMode) VirtualGamepadFactory {
+	f.socdMode = mode
+	return f
+}
+
+func (f *virtualGamepadFactory) WithClock(clock clock.Clock) VirtualGamepadFactory {
+	f.clock = clock
+	return f
+}
+
 func (f *virtualGamepadFactory) Create() VirtualGamepad {
+	c := f.clock
+	if c == nil {
+		c = clock.New()
+	}
+
+	digital := f.digital
+	if overrides := dpadMapping(f.dpadMode); overrides != nil {
+		digital = MappingDigital{}
+		for button, event := range f.digital {
+			digital[button] = event
+		}
+		for button, event := range overrides {
+			digital[button] = event
+		}
+	}
+
 	vg := &virtualGamepad{
-		device:     f.device,
-		digital:    f.digital,
-		leftStick:  f.leftStick,
-		rightStick: f.rightStick,
+		device:           f.device,
+		digital:          digital,
+		dpad:             NewDPad(f.socdMode),
+		leftStick:        f.leftStick,
+		rightStick:       f.rightStick,
+		leftTransform:    f.leftTransform,
+		rightTransform:   f.rightTransform,
+		triggerThreshold: f.triggerThreshold,
+		clock:            c,
+		extra:            f.extra,
 	}
 
 	vg.init()
@@ -82,17 +177,32 @@ func (f *virtualGamepadFactory) Create() VirtualGamepad {
 }
 
 type virtualGamepad struct {
-	device     virtual_device.VirtualDevice
-	digital    MappingDigital
-	leftStick  *MappingStick
-	rightStick *MappingStick
+	mu sync.Mutex // guards the state, also updated by the goroutine running the turbos and macros
+
+	device           virtual_device.VirtualDevice
+	digital          MappingDigital
+	leftStick        *MappingStick
+	rightStick       *MappingStick
+	leftTransform    StickTransform
+	rightTransform   StickTransform
+	triggerThreshold float32
+	dpad             *DPad
+	clock            clock.Clock
+	extra            []InputEvent
+	state            GamepadState
+	values           map[output]int32 // last value sent per event code
+	automation       automation
 }
 
 func (vg *virtualGamepad) Register() error {
 	return vg.device.Register()
 }
 
+// Unregister stops the turbos and the macros before removing the device.
 func (vg *virtualGamepad) Unregister() error {
+	vg.mu.Lock()
+	vg.stopAutomation()
+	vg.mu.Unlock()
 	return vg.device.Unregister()
 }
 
@@ -100,6 +210,26 @@ func (vg *virtualGamepad) EventPath() string {
 	return vg.device.EventPath()
 }
 
+func (vg *virtualGamepad) DeviceInfo() (virtual_device.DeviceInfo, error) {
+	return vg.device.DeviceInfo()
+}
+
+func (vg *virtualGamepad) Device() virtual_device.VirtualDevice {
+	return vg.device
+}
+
+// SupportedButtons returns the logical buttons mapped by the gamepad, in the order of their constants.
+func (vg *virtualGamepad) SupportedButtons() []Button {
+	buttons := make([]Button, 0, len(vg.digital))
+	for button := range vg.digital {
+		if button < buttonReserved1 {
+			buttons = append(buttons, button)
+		}
+	}
+	sort.Slice(buttons, func(i, j int) bool { return buttons[i] < buttons[j] })
+	return buttons
+}
+
 func (vg *virtualGamepad) init() {
 	buttons := make([]linux.Button, 0)
 	keys := make([]linux.Key, 0)
@@ -134,6 +264,9 @@ func (vg *virtualGamepad) init() {
 	for _, event := range vg.digital {
 		init(event)
 	}
+	for _, event := range vg.extra {
+		init(event)
+	}
 
 	if vg.leftStick != nil {
 		absoluteAxes = append(
@@ -155,16 +288,35 @@ func (vg *virtualGamepad) init() {
 		absoluteAxes = append(absoluteAxes, convertHatToAbsAxis(hatEvents)...)
 	}
 
+	buttons = unique(buttons)
+	keys = unique(keys)
+
 	vg.device.WithButtons(buttons)
 	vg.device.WithKeys(keys)
 	vg.device.WithAbsAxes(absoluteAxes)
+	vg.initValues(buttons, keys, absoluteAxes)
 
 	if withScanCode {
 		vg.device.WithMiscEvents([]linux.MiscEvent{linux.MSC_SCAN})
 	}
 }
 
+// Press presses a button, starting its turbo, flipping its toggle or running its macro when configured.
 func (vg *virtualGamepad) Press(button Button) {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
+	vg.pressLogical(button)
+}
+
+// Release releases a button, stopping its turbo. Toggled buttons and buttons bound to a macro ignore it.
+func (vg *virtualGamepad) Release(button Button) {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
+	vg.releaseLogical(button)
+}
+
+// press sends the codes of a button, without turbo, toggle nor macro.
+func (vg *virtualGamepad) press(button Button) {
 	var press func(event InputEvent)
 
 	press = func(event InputEvent) {
@@ -175,14 +327,18 @@ func (vg *virtualGamepad) Press(button Button) {
 			}
 		case linux.Button:
 			vg.device.PressButton(e)
+			vg.sent(linux.EV_KEY, uint16(e), 1)
 		case linux.Key:
 			vg.device.PressKey(e)
+			vg.sent(linux.EV_KEY, uint16(e), 1)
 		case MSCScanCode:
 			vg.device.SendMiscEvent(linux.MSC_SCAN, int32(e))
 		case HatEvent:
 			vg.device.SendAbsoluteEvent(e.Axis, e.Value)
+			vg.sent(linux.EV_ABS, uint16(e.Axis), e.Value)
 		case virtual_device.AbsAxis:
 			vg.device.SendAbsoluteEvent(e.Axis, e.Max)
+			vg.sent(linux.EV_ABS, uint16(e.Axis), e.Max)
 		default:
 			fmt.Println("Unknown event type")
 		}
@@ -194,11 +350,19 @@ func (vg *virtualGamepad) Press(button Button) {
 		return
 	}
 
+	if isDPadDirection(button) {
+		vg.setButton(button, true)
+		vg.apply(vg.state, button)
+		return
+	}
+
 	press(event)
+	vg.setButton(button, true)
 	vg.device.SyncReport()
 }
 
-func (vg *virtualGamepad) Release(button Button) {
+// release sends the codes of a released button, without turbo, toggle nor macro.
+func (vg *virtualGamepad) release(button Button) {
 	var release func(event InputEvent)
 
 	release = func(event InputEvent) {
@@ -209,14 +373,18 @@ func (vg *virtualGamepad) Release(button Button) {
 			}
 		case linux.Button:
 			vg.device.ReleaseButton(e)
+			vg.sent(linux.EV_KEY, uint16(e), 0)
 		case linux.Key:
 			vg.device.ReleaseKey(e)
+			vg.sent(linux.EV_KEY, uint16(e), 0)
 		case MSCScanCode:
 			vg.device.SendMiscEvent(linux.MSC_SCAN, int32(e))
 		case HatEvent:
 			vg.device.SendAbsoluteEvent(e.Axis, 0)
+			vg.sent(linux.EV_ABS, uint16(e.Axis), 0)
 		case virtual_device.AbsAxis:
-			vg.device.SendAbsoluteEvent(e.Axis, e.Min)
+			vg.device.SendAbsoluteEvent(e.Axis, triggerRest(e))
+			vg.sent(linux.EV_ABS, uint16(e.Axis), triggerRest(e))
 		default:
 			fmt.Println("Unknown event type")
 		}
@@ -228,58 +396,155 @@ func (vg *virtualGamepad) Release(button Button) {
 		return
 	}
 
+	if isDPadDirection(button) {
+		vg.setButton(button, false)
+		vg.apply(vg.state, button)
+		return
+	}
+
 	release(event)
+	vg.setButton(button, false)
 	vg.device.SyncReport()
 }
 
+func (vg *virtualGamepad) setButton(button Button, pressed bool) {
+	if vg.state.Buttons == nil {
+		vg.state.Buttons = map[Button]bool{}
+	}
+	if pressed {
+		vg.state.Buttons[button] = true
+	} else {
+		vg.state.setTrigger(button, 0)
+	}
+}
+
 func (vg *virtualGamepad) moveStick(stick *MappingStick, x, y float32) {
-	vg.device.SendAbsoluteEvent(stick.X.Axis, stick.X.Denormalize(x))
-	vg.device.SendAbsoluteEvent(stick.Y.Axis, stick.Y.Denormalize(y))
+	vg.sendAbs(&stick.X, x)
+	vg.sendAbs(&stick.Y, y)
 	vg.device.SyncReport()
 
 }
 
 func (vg *virtualGamepad) moveAxis(absAxis *virtual_device.AbsAxis, p float32) {
-	vg.device.SendAbsoluteEvent(absAxis.Axis, absAxis.Denormalize(p))
+	vg.sendAbs(absAxis, p)
 	vg.device.SyncReport()
 }
 
+// moveTransformedStick sends a stick position through its transform.
+// As the transform may mix both axes, each axis is sent when forced or when its value changed.
+func (vg *virtualGamepad) moveTransformedStick(stick *MappingStick, transform StickTransform, position Stick, sendX, sendY bool) {
+	x, y := transform.Apply(position.X, position.Y)
+	for _, item := range []struct {
+		axis  *virtual_device.AbsAxis
+		value float32
+		force bool
+	}{
+		{&stick.X, x, sendX},
+		{&stick.Y, y, sendY},
+	} {
+		value := item.axis.Denormalize(item.value)
+		if previous, exists := vg.values[output{linux.EV_ABS, uint16(item.axis.Axis)}]; item.force || !exists || previous != value {
+			vg.device.SendAbsoluteEvent(item.axis.Axis, value)
+			vg.sent(linux.EV_ABS, uint16(item.axis.Axis), value)
+		}
+	}
+	vg.device.SyncReport()
+}
+
+func (vg *virtualGamepad) sendAbs(absAxis *virtual_device.AbsAxis, p float32) {
+	value := absAxis.Denormalize(p)
+	vg.device.SendAbsoluteEvent(absAxis.Axis, value)
+	vg.sent(linux.EV_ABS, uint16(absAxis.Axis), value)
+}
+
 func (vg *virtualGamepad) MoveLeftStick(x, y float32) {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
+	vg.moveLeftStick(x, y)
+}
+
+func (vg *virtualGamepad) moveLeftStick(x, y float32) {
 	if vg.leftStick != nil {
+		vg.state.LeftStick = Stick{X: x, Y: y}
+		if vg.leftTransform != nil {
+			vg.moveTransformedStick(vg.leftStick, vg.leftTransform, vg.state.LeftStick, true, true)
+			return
+		}
 		vg.moveStick(vg.leftStick, x, y)
 	}
 }
 
 func (vg *virtualGamepad) MoveLeftStickX(x float32) {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
 	if vg.leftStick != nil {
+		vg.state.LeftStick.X = x
+		if vg.leftTransform != nil {
+			vg.moveTransformedStick(vg.leftStick, vg.leftTransform, vg.state.LeftStick, true, false)
+			return
+		}
 		vg.moveAxis(&vg.leftStick.X, x)
 	}
 }
 
 func (vg *virtualGamepad) MoveLeftStickY(y float32) {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
 	if vg.leftStick != nil {
+		vg.state.LeftStick.Y = y
+		if vg.leftTransform != nil {
+			vg.moveTransformedStick(vg.leftStick, vg.leftTransform, vg.state.LeftStick, false, true)
+			return
+		}
 		vg.moveAxis(&vg.leftStick.Y, y)
 	}
 }
 
 func (vg *virtualGamepad) MoveRightStick(x, y float32) {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
+	vg.moveRightStick(x, y)
+}
+
+func (vg *virtualGamepad) moveRightStick(x, y float32) {
 	if vg.rightStick != nil {
+		vg.state.RightStick = Stick{X: x, Y: y}
+		if vg.rightTransform != nil {
+			vg.moveTransformedStick(vg.rightStick, vg.rightTransform, vg.state.RightStick, true, true)
+			return
+		}
 		vg.moveStick(vg.rightStick, x, y)
 	}
 }
 
 func (vg *virtualGamepad) MoveRightStickX(x float32) {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
 	if vg.rightStick != nil {
+		vg.state.RightStick.X = x
+		if vg.rightTransform != nil {
+			vg.moveTransformedStick(vg.rightStick, vg.rightTransform, vg.state.RightStick, true, false)
+			return
+		}
 		vg.moveAxis(&vg.rightStick.X, x)
 	}
 }
 
 func (vg *virtualGamepad) MoveRightStickY(y float32) {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
 	if vg.rightStick != nil {
+		vg.state.RightStick.Y = y
+		if vg.rightTransform != nil {
+			vg.moveTransformedStick(vg.rightStick, vg.rightTransform, vg.state.RightStick, false, true)
+			return
+		}
 		vg.moveAxis(&vg.rightStick.Y, y)
 	}
 }
 
 func (vg *virtualGamepad) Send(evType, code uint16, value int32) {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
 	vg.device.Send(evType, code, value)
 }
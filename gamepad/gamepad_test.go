@@ -4,11 +4,11 @@ import (
 	"testing"
 
 	virtual_device "github.com/jbdemonte/virtual-device"
-	"github.com/jbdemonte/virtual-device/internal/testutil"
 	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
 )
 
-func newTestGamepad(mock *testutil.MockDevice) VirtualGamepad {
+func newTestGamepad(mock *vdtest.Device) VirtualGamepad {
 	return NewVirtualGamepadFactory().
 		WithDevice(mock).
 		WithDigital(MappingDigital{
@@ -28,7 +28,7 @@ func newTestGamepad(mock *testutil.MockDevice) VirtualGamepad {
 }
 
 func TestGamepad_PressSingleButton(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	gp := newTestGamepad(mock)
 
 	gp.Press(ButtonSouth)
@@ -47,7 +47,7 @@ func TestGamepad_PressSingleButton(t *testing.T) {
 }
 
 func TestGamepad_ReleaseSingleButton(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	gp := newTestGamepad(mock)
 
 	gp.Release(ButtonSouth)
@@ -62,7 +62,7 @@ func TestGamepad_ReleaseSingleButton(t *testing.T) {
 }
 
 func TestGamepad_PressCompositeButton(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	gp := newTestGamepad(mock)
 
 	gp.Press(ButtonUp)
@@ -83,7 +83,7 @@ func TestGamepad_PressCompositeButton(t *testing.T) {
 }
 
 func TestGamepad_ReleaseCompositeButton(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	gp := newTestGamepad(mock)
 
 	gp.Release(ButtonUp)
@@ -99,7 +99,7 @@ func TestGamepad_ReleaseCompositeButton(t *testing.T) {
 }
 
 func TestGamepad_PressAbsAxisButton(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	gp := newTestGamepad(mock)
 
 	// L2 mapped to AbsAxis{ABS_Z, min=0, max=255} — press sends Max
@@ -115,7 +115,7 @@ func TestGamepad_PressAbsAxisButton(t *testing.T) {
 }
 
 func TestGamepad_ReleaseAbsAxisButton(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	gp := newTestGamepad(mock)
 
 	// L2 release sends Min (0)
@@ -131,7 +131,7 @@ func TestGamepad_ReleaseAbsAxisButton(t *testing.T) {
 }
 
 func TestGamepad_UnmappedButton(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	gp := newTestGamepad(mock)
 
 	// ButtonEast is not mapped
@@ -142,7 +142,7 @@ func TestGamepad_UnmappedButton(t *testing.T) {
 }
 
 func TestGamepad_MoveLeftStick(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	gp := newTestGamepad(mock)
 
 	gp.MoveLeftStick(0, 0)
@@ -161,7 +161,7 @@ func TestGamepad_MoveLeftStick(t *testing.T) {
 }
 
 func TestGamepad_MoveLeftStick_FullRight(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	gp := newTestGamepad(mock)
 
 	gp.MoveLeftStickX(1)
@@ -176,7 +176,7 @@ func TestGamepad_MoveLeftStick_FullRight(t *testing.T) {
 }
 
 func TestGamepad_Init_ConfiguresDevice(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	_ = newTestGamepad(mock)
 
 	// init() should have called WithButtons, WithKeys, WithAbsAxes
@@ -187,3 +187,37 @@ func TestGamepad_Init_ConfiguresDevice(t *testing.T) {
 		t.Error("expected absolute axes to be configured")
 	}
 }
+
+func TestGamepad_SupportedButtons(t *testing.T) {
+	mock := vdtest.NewDevice()
+	gp := newTestGamepad(mock)
+
+	got := gp.SupportedButtons()
+	want := []Button{ButtonUp, ButtonNorth, ButtonSouth, ButtonL2}
+	if len(got) != len(want) {
+		t.Fatalf("SupportedButtons = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("SupportedButtons[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestGamepad_SharedCodeRegisteredOnce(t *testing.T) {
+	mock := vdtest.NewDevice()
+	gp := NewVirtualGamepadFactory().
+		WithDevice(mock).
+		WithDigital(MappingDigital{
+			ButtonR1: linux.BTN_TR,
+			ButtonSL: linux.BTN_TR,
+		}).
+		Create()
+
+	if len(mock.Buttons) != 1 {
+		t.Errorf("Buttons = %v, want a single BTN_TR", mock.Buttons)
+	}
+
+	gp.Press(ButtonSL)
+	mock.ExpectButtonPressed(t, linux.BTN_TR, true)
+}
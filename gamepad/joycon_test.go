@@ -0,0 +1,90 @@
+package gamepad
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+func newMockJoyConPair(mode JoyConMode) (JoyConPair, *vdtest.Device, *vdtest.Device, *vdtest.Device) {
+	left, leftDevice := withMock(NewJoyConL())
+	right, rightDevice := withMock(NewJoyConR())
+	combinedDevice := vdtest.NewDevice()
+	return newJoyConPair(mode, left, right, newJoyConCombined(combinedDevice)), leftDevice, rightDevice, combinedDevice
+}
+
+func TestJoyConCombined_Mapping(t *testing.T) {
+	device := vdtest.NewDevice()
+	gp := newJoyConCombined(device)
+
+	gp.Press(ButtonCapture)
+	gp.MoveRightStick(1, 0)
+
+	device.ExpectFrame(t, vdtest.Button(linux.BTN_Z, 1))
+	device.ExpectFrame(t, vdtest.Abs(linux.ABS_RX, 32767), vdtest.Abs(linux.ABS_RY, 0))
+	if len(device.AbsAxes) != 4 {
+		t.Errorf("expected both sticks, got %v", device.AbsAxes)
+	}
+}
+
+func TestJoyConPair_SetMode(t *testing.T) {
+	pair, left, right, combined := newMockJoyConPair(JoyConSplit)
+
+	if err := pair.Register(); err != nil {
+		t.Fatal(err)
+	}
+	if !left.Registered() || !right.Registered() || combined.Registered() {
+		t.Fatal("expected the split devices only")
+	}
+
+	if err := pair.SetMode(JoyConCombined); err != nil {
+		t.Fatal(err)
+	}
+	if left.Registered() || right.Registered() || !combined.Registered() {
+		t.Fatal("expected the combined device only")
+	}
+	if pair.Mode() != JoyConCombined {
+		t.Errorf("mode = %d", pair.Mode())
+	}
+
+	if err := pair.Unregister(); err != nil {
+		t.Fatal(err)
+	}
+	if combined.Registered() {
+		t.Error("expected the combined device to be unregistered")
+	}
+}
+
+func TestJoyConPair_SetModeUnregistered(t *testing.T) {
+	pair, left, _, combined := newMockJoyConPair(JoyConSplit)
+
+	if err := pair.SetMode(JoyConCombined); err != nil {
+		t.Fatal(err)
+	}
+	if left.Registered() || combined.Registered() {
+		t.Error("no device must be registered")
+	}
+	if err := pair.Register(); err != nil {
+		t.Fatal(err)
+	}
+	if !combined.Registered() || left.Registered() {
+		t.Error("expected the combined device only")
+	}
+}
+
+func TestJoyConPair_SetModeRollback(t *testing.T) {
+	pair, left, right, combined := newMockJoyConPair(JoyConSplit)
+	combined.FailRegister(errors.New("no uinput"))
+
+	if err := pair.Register(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pair.SetMode(JoyConCombined); err == nil {
+		t.Fatal("expected an error")
+	}
+	if pair.Mode() != JoyConSplit || !left.Registered() || !right.Registered() {
+		t.Error("expected the split devices to be registered back")
+	}
+}
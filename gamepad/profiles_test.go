@@ -0,0 +1,98 @@
+package gamepad
+
+import (
+	"testing"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/sdl"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+// withMock rebuilds a profile on a recording device, keeping its mappings.
+func withMock(gp VirtualGamepad) (VirtualGamepad, *vdtest.Device) {
+	vg := gp.(*virtualGamepad)
+	mock := vdtest.NewDevice()
+	factory := NewVirtualGamepadFactory().WithDevice(mock).WithDigital(vg.digital)
+	if vg.leftStick != nil {
+		factory.WithLeftStick(*vg.leftStick)
+	}
+	if vg.rightStick != nil {
+		factory.WithRightStick(*vg.rightStick)
+	}
+	return factory.Create(), mock
+}
+
+func TestProfiles_NamedButtons(t *testing.T) {
+	tests := []struct {
+		name   string
+		create func() VirtualGamepad
+		button Button
+		code   linux.Button
+	}{
+		{"SwitchPro capture", NewSwitchPro, ButtonCapture, linux.BTN_Z},
+		{"JoyConL capture", NewJoyConL, ButtonCapture, linux.BTN_Z},
+		{"JoyConL SL", NewJoyConL, ButtonSL, linux.BTN_TR},
+		{"JoyConR SR", NewJoyConR, ButtonSR, linux.BTN_TL2},
+		{"Elite2 paddle 1", NewXBoxOneElite2, ButtonPaddle1, linux.BTN_TRIGGER_HAPPY5},
+		{"Elite2 paddle 4", NewXBoxOneElite2, ButtonPaddle4, linux.BTN_TRIGGER_HAPPY8},
+		{"Stadia capture", NewStadia, ButtonShare, linux.BTN_TRIGGER_HAPPY2},
+		{"Stadia assistant", NewStadia, ButtonMisc1, linux.BTN_TRIGGER_HAPPY1},
+		{"PS5 touchpad", NewSonyPS5, ButtonTouchpad, linux.BTN_TRIGGER_HAPPY1},
+		{"PS5 mute", NewSonyPS5, ButtonMute, linux.BTN_TRIGGER_HAPPY2},
+		{"PS4 home", NewSonyPS4, ButtonHome, linux.BTN_MODE},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gp, mock := withMock(tt.create())
+			gp.Press(tt.button)
+			mock.ExpectButtonPressed(t, tt.code, true)
+		})
+	}
+}
+
+func TestProfiles_SupportedButtonsSkipReserved(t *testing.T) {
+	gp, _ := withMock(NewSN30Pro())
+	for _, button := range gp.SupportedButtons() {
+		if button >= buttonReserved1 {
+			t.Errorf("reserved button %d must not be listed", button)
+		}
+	}
+}
+
+// TestProfiles_ControllerType checks SDL detects the family of each profile from its IDs.
+func TestProfiles_ControllerType(t *testing.T) {
+	tests := []struct {
+		name   string
+		create func(device virtual_device.VirtualDevice) VirtualGamepad
+		want   sdl.ControllerType
+	}{
+		{"XBox360", newXBox360, sdl.ControllerXBox360},
+		{"XBoxOneS", newXBoxOneS, sdl.ControllerXBoxOne},
+		{"XBoxOneElite2", newXBoxOneElite2, sdl.ControllerXBoxOne},
+		{"SonyPS4", func(device virtual_device.VirtualDevice) VirtualGamepad {
+			return newSonyPS4(sonyPS4Identity(device), sonyPS4Digital())
+		}, sdl.ControllerPS4},
+		{"SonyPS5", func(device virtual_device.VirtualDevice) VirtualGamepad {
+			return newSonyPS5(sonyPS5Identity(device), sonyPS5Digital())
+		}, sdl.ControllerPS5},
+		{"SwitchPro", newSwitchPro, sdl.ControllerSwitchPro},
+		{"JoyConL", newJoyConL, sdl.ControllerJoyCon},
+		{"JoyConR", newJoyConR, sdl.ControllerJoyCon},
+		{"JoyConCombined", func(device virtual_device.VirtualDevice) VirtualGamepad {
+			return newJoyConCombined(joyConCombinedIdentity(device))
+		}, sdl.ControllerJoyCon},
+		{"Stadia", newStadia, sdl.ControllerStadia},
+		{"SaitekP2600", newSaitekP2600, sdl.ControllerGeneric},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := vdtest.NewDevice()
+			tt.create(mock)
+			if c, _ := sdl.LookupController(mock.Vendor, mock.Product); c.Type != tt.want {
+				t.Errorf("controller type = %v, want %v", c.Type, tt.want)
+			}
+		})
+	}
+}
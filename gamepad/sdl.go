@@ -0,0 +1,451 @@
+package gamepad
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/sdl"
+)
+
+// based on the joystick enumeration of SDL, https://github.com/libsdl-org/SDL/blob/release-2.30.x/src/joystick/linux/SDL_sysjoystick.c
+
+// sdlPlatform is the platform of the mappings: the indexes of a mapping depend on the joystick driver.
+const sdlPlatform = "Linux"
+
+// sdlButtons lists the gamepad inputs bound to a single logical button.
+var sdlButtons = []struct {
+	input  string
+	button Button
+}{
+	{sdl.InputA, ButtonSouth},
+	{sdl.InputB, ButtonEast},
+	{sdl.InputX, ButtonWest},
+	{sdl.InputY, ButtonNorth},
+	{sdl.InputBack, ButtonSelect},
+	{sdl.InputGuide, ButtonMode},
+	{sdl.InputStart, ButtonStart},
+	{sdl.InputLeftStick, ButtonL3},
+	{sdl.InputRightStick, ButtonR3},
+	{sdl.InputLeftShoulder, ButtonL1},
+	{sdl.InputRightShoulder, ButtonR1},
+	{sdl.InputLeftTrigger, ButtonL2},
+	{sdl.InputRightTrigger, ButtonR2},
+	{sdl.InputDPadUp, ButtonUp},
+	{sdl.InputDPadDown, ButtonDown},
+	{sdl.InputDPadLeft, ButtonLeft},
+	{sdl.InputDPadRight, ButtonRight},
+	{sdl.InputPaddle1, ButtonPaddle1},
+	{sdl.InputPaddle2, ButtonPaddle2},
+	{sdl.InputPaddle3, ButtonPaddle3},
+	{sdl.InputPaddle4, ButtonPaddle4},
+	{sdl.InputTouchpad, ButtonTouchpad},
+}
+
+// sdlMisc1 lists the buttons SDL reports as misc1, by priority.
+var sdlMisc1 = []Button{ButtonCapture, ButtonMute, ButtonMisc1}
+
+// sdlIndexes numbers the inputs of a device the way SDL enumerates them.
+type sdlIndexes struct {
+	buttons map[uint16]int
+	axes    map[linux.AbsoluteAxis]int
+	hats    map[linux.AbsoluteAxis]int // by ABS_HATxX
+}
+
+func newSDLIndexes(capabilities virtual_device.Capabilities) sdlIndexes {
+	indexes := sdlIndexes{
+		buttons: map[uint16]int{},
+		axes:    map[linux.AbsoluteAxis]int{},
+		hats:    map[linux.AbsoluteAxis]int{},
+	}
+
+	keys := make([]uint16, 0, len(capabilities.Keys))
+	for _, key := range capabilities.Keys {
+		if key < linux.KEY_MAX {
+			keys = append(keys, uint16(key))
+		}
+	}
+	// the joystick buttons first, then the keys below BTN_JOYSTICK
+	sort.Slice(keys, func(i, j int) bool {
+		ji, jj := keys[i] >= uint16(linux.BTN_JOYSTICK), keys[j] >= uint16(linux.BTN_JOYSTICK)
+		if ji != jj {
+			return ji
+		}
+		return keys[i] < keys[j]
+	})
+	for i, key := range keys {
+		indexes.buttons[key] = i
+	}
+
+	axes := append([]linux.AbsoluteAxis{}, capabilities.AbsAxes...)
+	sort.Slice(axes, func(i, j int) bool { return axes[i] < axes[j] })
+	hats := map[linux.AbsoluteAxis]bool{}
+	for _, axis := range axes {
+		if isHatAxis(axis) {
+			hats[linux.ABS_HAT0X+(axis-linux.ABS_HAT0X)&^1] = true
+		} else if axis < linux.ABS_MAX {
+			indexes.axes[axis] = len(indexes.axes)
+		}
+	}
+	for axis := linux.ABS_HAT0X; axis <= linux.ABS_HAT3X; axis += 2 {
+		if hats[axis] {
+			indexes.hats[axis] = len(indexes.hats)
+		}
+	}
+	return indexes
+}
+
+func isHatAxis(axis linux.AbsoluteAxis) bool {
+	return axis >= linux.ABS_HAT0X && axis <= linux.ABS_HAT3Y
+}
+
+// hatMask returns the SDL direction of a hat event, ABS_HATxY -1 being up.
+func hatMask(event HatEvent) int {
+	vertical := (event.Axis-linux.ABS_HAT0X)%2 == 1
+	switch {
+	case vertical && event.Value < 0:
+		return sdl.HatUp
+	case vertical:
+		return sdl.HatDown
+	case event.Value < 0:
+		return sdl.HatLeft
+	}
+	return sdl.HatRight
+}
+
+// binding returns the SDL binding of an event, the hats first, then the axes, then the buttons.
+func (indexes sdlIndexes) binding(event InputEvent) (sdl.Binding, bool) {
+	var hat, axis, button *sdl.Binding
+	visitEvents(event, func(event InputEvent) {
+		switch e := event.(type) {
+		case HatEvent:
+			if index, ok := indexes.hats[linux.ABS_HAT0X+(e.Axis-linux.ABS_HAT0X)&^1]; ok && hat == nil && e.Value != 0 {
+				hat = &sdl.Binding{Type: sdl.BindingHat, Index: index, HatMask: hatMask(e)}
+			}
+		case virtual_device.AbsAxis:
+			if index, ok := indexes.axes[e.Axis]; ok && axis == nil {
+				axis = &sdl.Binding{Type: sdl.BindingAxis, Index: index}
+				if triggerRest(e) > e.Min { // a trigger resting above its minimum only moves the upper half
+					axis.Range = sdl.AxisPositive
+				}
+			}
+		case linux.Button:
+			if index, ok := indexes.buttons[uint16(e)]; ok && button == nil {
+				button = &sdl.Binding{Type: sdl.BindingButton, Index: index}
+			}
+		case linux.Key:
+			if index, ok := indexes.buttons[uint16(e)]; ok && button == nil {
+				button = &sdl.Binding{Type: sdl.BindingButton, Index: index}
+			}
+		}
+	})
+	for _, binding := range []*sdl.Binding{hat, axis, button} {
+		if binding != nil {
+			return *binding, true
+		}
+	}
+	return sdl.Binding{}, false
+}
+
+// stickBinding returns the SDL binding of a stick axis, which uses its whole range.
+func (indexes sdlIndexes) stickBinding(axis virtual_device.AbsAxis) (sdl.Binding, bool) {
+	index, ok := indexes.axes[axis.Axis]
+	return sdl.Binding{Type: sdl.BindingAxis, Index: index}, ok
+}
+
+// SDLMapping returns the gamecontrollerdb.txt mapping of the gamepad.
+// The indexes are computed from the capabilities of the kernel device, so the gamepad must be registered.
+func (vg *virtualGamepad) SDLMapping() (sdl.Mapping, error) {
+	info, err := vg.device.DeviceInfo()
+	if err != nil {
+		return sdl.Mapping{}, err
+	}
+	indexes := newSDLIndexes(info.Capabilities)
+
+	mapping := sdl.Mapping{
+		GUID:     sdl.NewGUID(info.ID.BusType, info.ID.Vendor, info.ID.Product, info.ID.Version, info.Name),
+		Name:     info.Name,
+		Bindings: map[string]sdl.Binding{},
+		Platform: sdlPlatform,
+	}
+
+	for _, item := range sdlButtons {
+		if event, exists := vg.digital[item.button]; exists {
+			if binding, ok := indexes.binding(event); ok {
+				mapping.Bindings[item.input] = binding
+			}
+		}
+	}
+	for _, button := range sdlMisc1 {
+		if event, exists := vg.digital[button]; exists {
+			if binding, ok := indexes.binding(event); ok {
+				mapping.Bindings[sdl.InputMisc1] = binding
+				break
+			}
+		}
+	}
+
+	for _, stick := range []struct {
+		mapping *MappingStick
+		x, y    string
+	}{
+		{vg.leftStick, sdl.InputLeftX, sdl.InputLeftY},
+		{vg.rightStick, sdl.InputRightX, sdl.InputRightY},
+	} {
+		if stick.mapping == nil {
+			continue
+		}
+		if binding, ok := indexes.stickBinding(stick.mapping.X); ok {
+			mapping.Bindings[stick.x] = binding
+		}
+		if binding, ok := indexes.stickBinding(stick.mapping.Y); ok {
+			mapping.Bindings[stick.y] = binding
+		}
+	}
+
+	return mapping, nil
+}
+
+// NewFromSDLMapping creates a gamepad matching a line of gamecontrollerdb.txt,
+// so SDL applications using this mapping see the logical buttons at their place.
+// The identity comes from the GUID when it holds the vendor and product IDs, the name from the mapping.
+func NewFromSDLMapping(line string) (VirtualGamepad, error) {
+	mapping, err := sdl.ParseMapping(line)
+	if err != nil {
+		return nil, err
+	}
+	bus := mapping.GUID.Bus()
+	if bus == 0 {
+		bus = linux.BUS_VIRTUAL
+	}
+	device := virtual_device.
+		NewVirtualDevice().
+		WithBusType(bus).
+		WithName(mapping.Name)
+	if mapping.GUID.HasIDs() {
+		device.
+			WithVendor(mapping.GUID.Vendor()).
+			WithProduct(mapping.GUID.Product()).
+			WithVersion(mapping.GUID.Version())
+	}
+	return newFromSDLMapping(device, mapping)
+}
+
+func newFromSDLMapping(device virtual_device.VirtualDevice, mapping sdl.Mapping) (VirtualGamepad, error) {
+	if mapping.Platform != "" && mapping.Platform != sdlPlatform {
+		return nil, fmt.Errorf("unsupported SDL platform %q", mapping.Platform)
+	}
+	if mapping.Name == "" {
+		return nil, errors.New("the SDL mapping has no name")
+	}
+
+	builder := sdlBuilder{
+		digital: MappingDigital{},
+		buttons: map[int]bool{},
+		axes:    map[int]bool{},
+		hats:    map[int]bool{},
+	}
+
+	inputs := make(map[string]bool, len(mapping.Bindings))
+	for _, item := range sdlButtons {
+		if binding, exists := mapping.Bindings[item.input]; exists {
+			event, err := builder.digitalEvent(binding)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", item.input, err)
+			}
+			builder.digital[item.button] = event
+			inputs[item.input] = true
+		}
+	}
+	if binding, exists := mapping.Bindings[sdl.InputMisc1]; exists {
+		event, err := builder.digitalEvent(binding)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", sdl.InputMisc1, err)
+		}
+		builder.digital[ButtonCapture] = event
+		inputs[sdl.InputMisc1] = true
+	}
+
+	factory := NewVirtualGamepadFactory().WithDevice(device)
+	for _, stick := range []struct {
+		x, y      string
+		mapping   func(MappingStick) VirtualGamepadFactory
+		transform func(...StickTransform) VirtualGamepadFactory
+	}{
+		{sdl.InputLeftX, sdl.InputLeftY, factory.WithLeftStick, factory.WithLeftStickTransform},
+		{sdl.InputRightX, sdl.InputRightY, factory.WithRightStick, factory.WithRightStickTransform},
+	} {
+		x, hasX := mapping.Bindings[stick.x]
+		y, hasY := mapping.Bindings[stick.y]
+		if !hasX && !hasY {
+			continue
+		}
+		if !hasX || !hasY {
+			return nil, fmt.Errorf("%s and %s must be both bound", stick.x, stick.y)
+		}
+		axisX, err := builder.stickAxis(x)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", stick.x, err)
+		}
+		axisY, err := builder.stickAxis(y)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", stick.y, err)
+		}
+		stick.mapping(MappingStick{X: axisX, Y: axisY})
+		var transforms []StickTransform
+		if x.Inverted {
+			transforms = append(transforms, InvertX())
+		}
+		if y.Inverted {
+			transforms = append(transforms, InvertY())
+		}
+		if len(transforms) > 0 {
+			stick.transform(transforms...)
+		}
+		inputs[stick.x], inputs[stick.y] = true, true
+	}
+
+	for input := range mapping.Bindings {
+		if !inputs[input] {
+			return nil, fmt.Errorf("unsupported SDL input %q", input)
+		}
+	}
+
+	builder.fillGaps()
+	f := factory.WithDigital(builder.digital).(*virtualGamepadFactory)
+	f.extra = builder.extra
+	return f.Create(), nil
+}
+
+// sdlBuilder converts SDL bindings to input events, the indexes being allocated in the SDL enumeration order.
+type sdlBuilder struct {
+	digital MappingDigital
+	buttons map[int]bool
+	axes    map[int]bool
+	hats    map[int]bool
+	extra   []InputEvent // the inputs skipped by the mapping
+}
+
+// sdlButtonCode returns the code SDL numbers index: BTN_SOUTH to BTN_THUMBR, then the BTN_TRIGGER_HAPPY range.
+func sdlButtonCode(index int) (linux.Button, error) {
+	joystick := int(linux.BTN_THUMBR-linux.BTN_SOUTH) + 1
+	switch {
+	case index < joystick:
+		return linux.BTN_SOUTH + linux.Button(index), nil
+	case index < joystick+int(linux.BTN_TRIGGER_HAPPY40-linux.BTN_TRIGGER_HAPPY1)+1:
+		return linux.BTN_TRIGGER_HAPPY1 + linux.Button(index-joystick), nil
+	}
+	return 0, fmt.Errorf("button index %d out of range", index)
+}
+
+// sdlAxisCode returns the code SDL numbers index, the hats being skipped.
+func sdlAxisCode(index int) (linux.AbsoluteAxis, error) {
+	axis := linux.AbsoluteAxis(index)
+	if axis >= linux.ABS_HAT0X {
+		axis += linux.ABS_HAT3Y - linux.ABS_HAT0X + 1
+	}
+	if index < 0 || axis > linux.ABS_MISC {
+		return 0, fmt.Errorf("axis index %d out of range", index)
+	}
+	return axis, nil
+}
+
+func (b *sdlBuilder) digitalEvent(binding sdl.Binding) (InputEvent, error) {
+	switch binding.Type {
+	case sdl.BindingButton:
+		code, err := sdlButtonCode(binding.Index)
+		if err != nil {
+			return nil, err
+		}
+		b.buttons[binding.Index] = true
+		return code, nil
+	case sdl.BindingHat:
+		if binding.Index > 3 {
+			return nil, fmt.Errorf("hat index %d out of range", binding.Index)
+		}
+		axis := linux.ABS_HAT0X + linux.AbsoluteAxis(2*binding.Index)
+		var event HatEvent
+		switch binding.HatMask {
+		case sdl.HatUp:
+			event = HatEvent{Axis: axis + 1, Value: -1}
+		case sdl.HatDown:
+			event = HatEvent{Axis: axis + 1, Value: 1}
+		case sdl.HatLeft:
+			event = HatEvent{Axis: axis, Value: -1}
+		case sdl.HatRight:
+			event = HatEvent{Axis: axis, Value: 1}
+		default:
+			return nil, fmt.Errorf("unsupported hat mask %d", binding.HatMask)
+		}
+		b.hats[binding.Index] = true
+		return event, nil
+	}
+
+	if binding.Range == sdl.AxisNegative || binding.Inverted {
+		return nil, fmt.Errorf("unsupported axis binding %q", binding)
+	}
+	code, err := sdlAxisCode(binding.Index)
+	if err != nil {
+		return nil, err
+	}
+	b.axes[binding.Index] = true
+	if binding.Range == sdl.AxisPositive {
+		// SDL rescales the whole range and reads its upper half, so the trigger rests at the center
+		return virtual_device.AbsAxis{Axis: code, Min: -32768, Value: 0, Max: 32767}, nil
+	}
+	return virtual_device.AbsAxis{Axis: code, Min: 0, Max: 255}, nil
+}
+
+func (b *sdlBuilder) stickAxis(binding sdl.Binding) (virtual_device.AbsAxis, error) {
+	if binding.Type != sdl.BindingAxis || binding.Range != sdl.AxisFull {
+		return virtual_device.AbsAxis{}, fmt.Errorf("unsupported stick binding %q", binding)
+	}
+	code, err := sdlAxisCode(binding.Index)
+	if err != nil {
+		return virtual_device.AbsAxis{}, err
+	}
+	b.axes[binding.Index] = true
+	return virtual_device.AbsAxis{Axis: code, Min: -32768, Max: 32767}, nil
+}
+
+// fillGaps registers the inputs skipped by the mapping, as SDL numbers the inputs sequentially.
+func (b *sdlBuilder) fillGaps() {
+	for index := 0; index < maxIndex(b.buttons); index++ {
+		if !b.buttons[index] {
+			code, _ := sdlButtonCode(index)
+			b.bind(code)
+		}
+	}
+	for index := 0; index < maxIndex(b.axes); index++ {
+		if !b.axes[index] {
+			code, _ := sdlAxisCode(index)
+			b.bind(virtual_device.AbsAxis{Axis: code, Min: -32768, Max: 32767})
+		}
+	}
+	for index := 0; index < maxIndex(b.hats); index++ {
+		if !b.hats[index] {
+			axis := linux.ABS_HAT0X + linux.AbsoluteAxis(2*index)
+			b.bind([]InputEvent{
+				HatEvent{Axis: axis, Value: -1}, HatEvent{Axis: axis, Value: 1},
+				HatEvent{Axis: axis + 1, Value: -1}, HatEvent{Axis: axis + 1, Value: 1},
+			})
+		}
+	}
+}
+
+// bind registers an event without mapping it to a logical button.
+func (b *sdlBuilder) bind(event InputEvent) {
+	b.extra = append(b.extra, event)
+}
+
+func maxIndex(indexes map[int]bool) int {
+	result := 0
+	for index := range indexes {
+		if index+1 > result {
+			result = index + 1
+		}
+	}
+	return result
+}
@@ -0,0 +1,169 @@
+package gamepad
+
+import (
+	"testing"
+
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/sdl"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+func TestSDLMapping_XBox360(t *testing.T) {
+	gp, mock := withMock(NewXBox360())
+	mock.BusType = linux.BUS_USB
+	mock.Vendor = sdl.USB_VENDOR_MICROSOFT
+	mock.Product = sdl.USB_PRODUCT_XBOX360_XUSB_CONTROLLER
+	mock.Version = 0x107
+	mock.Name = "Xbox 360 Wireless Receiver (XBOX)"
+
+	mapping, err := gp.SDLMapping()
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapping.GUID = mapping.GUID.WithoutCRC()
+	want := "030000005e040000a102000007010000,Xbox 360 Wireless Receiver (XBOX)," +
+		"a:b0,b:b1,back:b6,dpdown:h0.4,dpleft:h0.8,dpright:h0.2,dpup:h0.1,guide:b8," +
+		"leftshoulder:b4,leftstick:b9,lefttrigger:a2,leftx:a0,lefty:a1," +
+		"rightshoulder:b5,rightstick:b10,righttrigger:a5,rightx:a3,righty:a4," +
+		"start:b7,x:b2,y:b3,platform:Linux,"
+	if got := mapping.String(); got != want {
+		t.Errorf("mapping:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestSDLMapping_Misc1(t *testing.T) {
+	tests := []struct {
+		name   string
+		create func() VirtualGamepad
+		want   string
+	}{
+		{"SwitchPro capture", NewSwitchPro, "b4"},
+		{"PS5 mute", NewSonyPS5, "b12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gp, _ := withMock(tt.create())
+			mapping, err := gp.SDLMapping()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := mapping.Bindings[sdl.InputMisc1].String(); got != tt.want {
+				t.Errorf("misc1 = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func newMockFromSDLMapping(t *testing.T, line string) (VirtualGamepad, *vdtest.Device) {
+	t.Helper()
+	mapping, err := sdl.ParseMapping(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock := vdtest.NewDevice()
+	mock.Name = mapping.Name
+	gp, err := newFromSDLMapping(mock, mapping)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return gp, mock
+}
+
+func TestNewFromSDLMapping_RoundTrip(t *testing.T) {
+	line := "05000000000000000000000000000000,Custom Pad," +
+		"a:b0,b:b2,back:b20,dpdown:h1.4,dpleft:h1.8,dpright:h1.2,dpup:h1.1," +
+		"lefttrigger:a4,leftx:a0,lefty:a1,misc1:b3,paddle1:b16,rightx:a2,righty:a3,x:b5,platform:Linux,"
+	gp, mock := newMockFromSDLMapping(t, line)
+
+	mapping, err := gp.SDLMapping()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := sdl.ParseMapping(line)
+	for input, binding := range want.Bindings {
+		if mapping.Bindings[input] != binding {
+			t.Errorf("%s = %s, want %s", input, mapping.Bindings[input], binding)
+		}
+	}
+	if len(mapping.Bindings) != len(want.Bindings) {
+		t.Errorf("got %d bindings, want %d", len(mapping.Bindings), len(want.Bindings))
+	}
+	if len(mock.Buttons) != 21 {
+		t.Errorf("got %d buttons, want 21 so b20 keeps its index", len(mock.Buttons))
+	}
+
+	gp.Press(ButtonSouth)
+	mock.ExpectButtonPressed(t, linux.BTN_SOUTH, true)
+	gp.Press(ButtonSelect)
+	mock.ExpectButtonPressed(t, linux.BTN_TRIGGER_HAPPY6, true)
+	gp.Press(ButtonUp)
+	if got := mock.State().AbsValue(linux.ABS_HAT1Y); got != -1 {
+		t.Errorf("ABS_HAT1Y = %d, want -1", got)
+	}
+}
+
+func TestNewFromSDLMapping_ManyGaps(t *testing.T) {
+	gp, mock := newMockFromSDLMapping(t, "03000000000000000000000000000000,Pad,a:b30,leftx:a6,lefty:a7,")
+
+	if len(mock.Buttons) != 31 || len(mock.AbsAxes) != 8 {
+		t.Errorf("got %d buttons and %d axes, want 31 and 8", len(mock.Buttons), len(mock.AbsAxes))
+	}
+	if buttons := gp.SupportedButtons(); len(buttons) != 1 || buttons[0] != ButtonSouth {
+		t.Errorf("SupportedButtons = %v, want the South button only", buttons)
+	}
+}
+
+func TestNewFromSDLMapping_InvertedAndHalfAxes(t *testing.T) {
+	gp, mock := newMockFromSDLMapping(t, "03000000000000000000000000000000,Pad,leftx:a0,lefty:a1~,righttrigger:+a2,")
+
+	gp.MoveLeftStick(0, 1)
+	if got := mock.State().AbsValue(linux.ABS_Y); got >= 0 {
+		t.Errorf("ABS_Y = %d, want the inverted position", got)
+	}
+	// the trigger moves the upper half of the axis, from the center
+	gp.PressAnalog(ButtonR2, 0.5)
+	if got := mock.State().AbsValue(linux.ABS_Z); got != 16383 {
+		t.Errorf("half pulled ABS_Z = %d, want 16383", got)
+	}
+	gp.PressAnalog(ButtonR2, 0)
+	if got := mock.State().AbsValue(linux.ABS_Z); got != 0 {
+		t.Errorf("released ABS_Z = %d, want 0", got)
+	}
+	gp.Press(ButtonR2)
+	if got := mock.State().AbsValue(linux.ABS_Z); got != 32767 {
+		t.Errorf("pressed ABS_Z = %d, want 32767", got)
+	}
+	gp.Release(ButtonR2)
+	if got := mock.State().AbsValue(linux.ABS_Z); got != 0 {
+		t.Errorf("released ABS_Z = %d, want 0", got)
+	}
+
+	mapping, err := gp.SDLMapping()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := mapping.Bindings[sdl.InputRightTrigger].String(); got != "+a2" {
+		t.Errorf("righttrigger = %s, want +a2", got)
+	}
+}
+
+func TestNewFromSDLMapping_Unsupported(t *testing.T) {
+	for _, line := range []string{
+		"03000000000000000000000000000000,,a:b0,",
+		"03000000000000000000000000000000,Pad,a:b0,platform:Windows,",
+		"03000000000000000000000000000000,Pad,a:-a2,",
+		"03000000000000000000000000000000,Pad,dpup:h0.3,",
+		"03000000000000000000000000000000,Pad,leftx:a0,",
+		"03000000000000000000000000000000,Pad,leftx:b0,lefty:a1,",
+		"03000000000000000000000000000000,Pad,+leftx:a0,",
+		"03000000000000000000000000000000,Pad,a:b99,",
+	} {
+		mapping, err := sdl.ParseMapping(line)
+		if err != nil {
+			t.Fatalf("ParseMapping(%q): %v", line, err)
+		}
+		if _, err := newFromSDLMapping(vdtest.NewDevice(), mapping); err == nil {
+			t.Errorf("%q must be rejected", line)
+		}
+	}
+}
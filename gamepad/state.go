@@ -0,0 +1,335 @@
+package gamepad
+
+import (
+	"fmt"
+	"sort"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// Stick is the normalized position of an analog stick, each axis between -1 and 1.
+type Stick struct {
+	X float32
+	Y float32
+}
+
+// Hat is the position of the D-pad, each axis being -1, 0 or 1 (Y is -1 when pointing up).
+type Hat struct {
+	X int8
+	Y int8
+}
+
+// GamepadState is a snapshot of all the logical inputs of a gamepad.
+type GamepadState struct {
+	Buttons      map[Button]bool
+	LeftStick    Stick
+	RightStick   Stick
+	LeftTrigger  float32 // 0 when released, 1 when fully pulled
+	RightTrigger float32 // 0 when released, 1 when fully pulled
+	Hat          Hat     // merged with ButtonUp, ButtonRight, ButtonDown and ButtonLeft
+
+	// Analog holds the position, between 0 and 1, of the pressure-sensitive buttons mapped on an axis.
+	// LeftTrigger and RightTrigger are used for ButtonL2 and ButtonR2.
+	Analog map[Button]float32
+}
+
+// IsPressed reports whether the button is pressed, either directly or through the hat.
+// The analog positions are ignored: the gamepad presses their digital codes from its WithTriggerThreshold.
+func (s GamepadState) IsPressed(button Button) bool {
+	return s.Buttons[button] || s.Hat.points(button)
+}
+
+// trigger returns the analog position of a button, between 0 and 1.
+func (s GamepadState) trigger(button Button) float32 {
+	if s.Buttons[button] {
+		return 1
+	}
+	switch button {
+	case ButtonL2:
+		return s.LeftTrigger
+	case ButtonR2:
+		return s.RightTrigger
+	}
+	return s.Analog[button]
+}
+
+// setTrigger stores the analog position of a button, releasing its digital state.
+func (s *GamepadState) setTrigger(button Button, value float32) {
+	delete(s.Buttons, button)
+	switch button {
+	case ButtonL2:
+		s.LeftTrigger = value
+	case ButtonR2:
+		s.RightTrigger = value
+	default:
+		if s.Analog == nil {
+			s.Analog = map[Button]float32{}
+		}
+		s.Analog[button] = value
+	}
+}
+
+func (s GamepadState) clone() GamepadState {
+	buttons := make(map[Button]bool, len(s.Buttons))
+	for button, pressed := range s.Buttons {
+		if pressed {
+			buttons[button] = true
+		}
+	}
+	s.Buttons = buttons
+	analog := make(map[Button]float32, len(s.Analog))
+	for button, value := range s.Analog {
+		if value > 0 {
+			analog[button] = value
+		}
+	}
+	s.Analog = analog
+	return s
+}
+
+// output identifies an event code, used to track the last value sent for it.
+type output struct {
+	evType linux.EventType
+	code   uint16
+}
+
+func (vg *virtualGamepad) sent(evType linux.EventType, code uint16, value int32) {
+	vg.values[output{evType, code}] = value
+}
+
+// initValues records the values the device starts with, so that the first SetState only sends differences.
+func (vg *virtualGamepad) initValues(buttons []linux.Button, keys []linux.Key, absoluteAxes []virtual_device.AbsAxis) {
+	vg.values = map[output]int32{}
+	for _, button := range buttons {
+		vg.sent(linux.EV_KEY, uint16(button), 0)
+	}
+	for _, key := range keys {
+		vg.sent(linux.EV_KEY, uint16(key), 0)
+	}
+	for _, axis := range absoluteAxes {
+		vg.sent(linux.EV_ABS, uint16(axis.Axis), axis.Value)
+	}
+}
+
+// SetState applies a full state, sending only the codes whose value changed, in a single frame.
+func (vg *virtualGamepad) SetState(state GamepadState) {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
+	vg.apply(state, 0)
+}
+
+// apply sends the differences with the current state, the codes of the forced button being sent even if unchanged.
+func (vg *virtualGamepad) apply(state GamepadState, forced Button) {
+	state = state.clone()
+
+	vg.dpad.Set(
+		state.IsPressed(ButtonUp),
+		state.IsPressed(ButtonRight),
+		state.IsPressed(ButtonDown),
+		state.IsPressed(ButtonLeft),
+	)
+	hat := vg.dpad.Hat()
+
+	buttons := make([]Button, 0, len(vg.digital))
+	for button := range vg.digital {
+		buttons = append(buttons, button)
+	}
+	sort.Slice(buttons, func(i, j int) bool { return buttons[i] < buttons[j] })
+
+	targets := map[output]int32{}
+	for _, button := range buttons {
+		pressed := vg.isPressed(state, button)
+		if isDPadDirection(button) {
+			pressed = hat.points(button)
+		}
+		visitEvents(vg.digital[button], func(event InputEvent) {
+			switch e := event.(type) {
+			case linux.Button:
+				if pressed {
+					targets[output{linux.EV_KEY, uint16(e)}] = 1
+				} else if _, exists := targets[output{linux.EV_KEY, uint16(e)}]; !exists {
+					targets[output{linux.EV_KEY, uint16(e)}] = 0
+				}
+			case linux.Key:
+				if pressed {
+					targets[output{linux.EV_KEY, uint16(e)}] = 1
+				} else if _, exists := targets[output{linux.EV_KEY, uint16(e)}]; !exists {
+					targets[output{linux.EV_KEY, uint16(e)}] = 0
+				}
+			case HatEvent:
+				key := output{linux.EV_ABS, uint16(e.Axis)}
+				if pressed {
+					targets[key] = clampHat(targets[key] + e.Value)
+				} else if _, exists := targets[key]; !exists {
+					targets[key] = 0
+				}
+			case virtual_device.AbsAxis:
+				targets[output{linux.EV_ABS, uint16(e.Axis)}] = denormalizeTrigger(e, state.trigger(button))
+			}
+		})
+	}
+
+	// the forced button goes first, each code is sent at most once per frame
+	sort.SliceStable(buttons, func(i, j int) bool { return buttons[i] == forced && buttons[j] != forced })
+	emitted := map[output]bool{}
+
+	changed := false
+	send := func(key output, value int32, force bool) {
+		if emitted[key] {
+			return
+		}
+		if previous, exists := vg.values[key]; !force && exists && previous == value {
+			return
+		}
+		emitted[key] = true
+		vg.device.Send(uint16(key.evType), key.code, value)
+		vg.sent(key.evType, key.code, value)
+		changed = true
+	}
+
+	for _, button := range buttons {
+		var scanCode *MSCScanCode
+		var outputs []output
+		visitEvents(vg.digital[button], func(event InputEvent) {
+			switch e := event.(type) {
+			case linux.Button:
+				outputs = append(outputs, output{linux.EV_KEY, uint16(e)})
+			case linux.Key:
+				outputs = append(outputs, output{linux.EV_KEY, uint16(e)})
+			case HatEvent:
+				outputs = append(outputs, output{linux.EV_ABS, uint16(e.Axis)})
+			case virtual_device.AbsAxis:
+				outputs = append(outputs, output{linux.EV_ABS, uint16(e.Axis)})
+			case MSCScanCode:
+				scanCode = &e
+			}
+		})
+		force := button == forced
+		if scanCode != nil && (force || vg.differs(outputs, targets)) {
+			vg.device.SendMiscEvent(linux.MSC_SCAN, int32(*scanCode))
+		}
+		for _, key := range outputs {
+			send(key, targets[key], force)
+		}
+	}
+
+	for _, item := range []struct {
+		mapping   *MappingStick
+		transform StickTransform
+		stick     Stick
+	}{
+		{vg.leftStick, vg.leftTransform, state.LeftStick},
+		{vg.rightStick, vg.rightTransform, state.RightStick},
+	} {
+		if item.mapping == nil {
+			continue
+		}
+		x, y := item.stick.X, item.stick.Y
+		if item.transform != nil {
+			x, y = item.transform.Apply(x, y)
+		}
+		send(output{linux.EV_ABS, uint16(item.mapping.X.Axis)}, item.mapping.X.Denormalize(x), false)
+		send(output{linux.EV_ABS, uint16(item.mapping.Y.Axis)}, item.mapping.Y.Denormalize(y), false)
+	}
+
+	vg.state = state
+	if changed || forced != 0 {
+		vg.device.SyncReport()
+	}
+}
+
+// isPressed reports whether the digital codes of a button are pressed, an analog position reaching the threshold counting as pressed.
+func (vg *virtualGamepad) isPressed(state GamepadState, button Button) bool {
+	if state.IsPressed(button) {
+		return true
+	}
+	value := state.trigger(button)
+	return value > 0 && value >= vg.triggerThreshold
+}
+
+// PressAnalog presses a button partially, value being between 0 (released) and 1 (fully pressed).
+// Axes are set proportionally, digital codes are pressed once the value reaches the trigger threshold.
+func (vg *virtualGamepad) PressAnalog(button Button, value float32) {
+	if _, exist := vg.digital[button]; !exist {
+		fmt.Printf("button not assigned (0x%x)\n", button)
+		return
+	}
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
+	state := vg.state.clone()
+	state.setTrigger(button, clampTrigger(value))
+	vg.apply(state, 0)
+}
+
+// MoveTrigger sets the analog position of both triggers, between 0 and 1.
+func (vg *virtualGamepad) MoveTrigger(left, right float32) {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
+	state := vg.state.clone()
+	state.setTrigger(ButtonL2, clampTrigger(left))
+	state.setTrigger(ButtonR2, clampTrigger(right))
+	vg.apply(state, 0)
+}
+
+// State returns the last state applied, including the changes made by Press, Release and the Move methods.
+func (vg *virtualGamepad) State() GamepadState {
+	vg.mu.Lock()
+	defer vg.mu.Unlock()
+	return vg.state.clone()
+}
+
+// differs reports whether one of the keys changes, which is when the kernel sends a scan code.
+func (vg *virtualGamepad) differs(outputs []output, targets map[output]int32) bool {
+	for _, key := range outputs {
+		if key.evType != linux.EV_KEY {
+			continue
+		}
+		if previous, exists := vg.values[key]; !exists || previous != targets[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// visitEvents calls fn on each event of a mapping, flattening the []InputEvent lists.
+func visitEvents(event InputEvent, fn func(event InputEvent)) {
+	if events, ok := event.([]InputEvent); ok {
+		for _, item := range events {
+			visitEvents(item, fn)
+		}
+		return
+	}
+	fn(event)
+}
+
+// denormalizeTrigger converts a trigger position between 0 and 1 to the axis range, 0 being the rest position of the axis.
+func denormalizeTrigger(axis virtual_device.AbsAxis, value float32) int32 {
+	rest := triggerRest(axis)
+	return int32(float32(rest) + clampTrigger(value)*float32(axis.Max-rest))
+}
+
+// triggerRest returns the position of a released trigger: its initial value, kept within its range.
+func triggerRest(axis virtual_device.AbsAxis) int32 {
+	return min(max(axis.Value, axis.Min), axis.Max)
+}
+
+func clampTrigger(value float32) float32 {
+	if value < 0 {
+		return 0
+	}
+	if value > 1 {
+		return 1
+	}
+	return value
+}
+
+func clampHat(value int32) int32 {
+	if value < -1 {
+		return -1
+	}
+	if value > 1 {
+		return 1
+	}
+	return value
+}
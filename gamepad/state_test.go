@@ -0,0 +1,110 @@
+package gamepad
+
+import (
+	"testing"
+
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+func TestGamepad_SetState_SingleFrame(t *testing.T) {
+	mock := vdtest.NewDevice()
+	gp := newTestGamepad(mock)
+	gp.Release(ButtonUp)
+	mock.Reset()
+
+	gp.SetState(GamepadState{
+		Buttons:     map[Button]bool{ButtonSouth: true},
+		LeftStick:   Stick{X: 1, Y: -1},
+		LeftTrigger: 1,
+		Hat:         Hat{Y: -1},
+	})
+
+	mock.ExpectFrame(t,
+		vdtest.Button(linux.BTN_TRIGGER_HAPPY3, 1),
+		vdtest.Abs(linux.ABS_HAT0Y, -1),
+		vdtest.Button(linux.BTN_SOUTH, 1),
+		vdtest.Abs(linux.ABS_Z, 255),
+		vdtest.Abs(linux.ABS_X, 32767),
+		vdtest.Abs(linux.ABS_Y, -32768),
+	)
+	mock.ExpectNoFrame(t)
+}
+
+func TestGamepad_SetState_OnlyChanges(t *testing.T) {
+	mock := vdtest.NewDevice()
+	gp := newTestGamepad(mock)
+
+	state := GamepadState{
+		Buttons:   map[Button]bool{ButtonSouth: true, ButtonNorth: true},
+		LeftStick: Stick{X: 1},
+	}
+	gp.SetState(state)
+	mock.Reset()
+
+	gp.SetState(state)
+	if len(mock.Events()) != 0 {
+		t.Fatalf("expected no events for an identical state, got %+v", mock.Events())
+	}
+
+	state.Buttons[ButtonNorth] = false
+	gp.SetState(state)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_NORTH, 0))
+}
+
+func TestGamepad_SetState_AfterPress(t *testing.T) {
+	mock := vdtest.NewDevice()
+	gp := newTestGamepad(mock)
+
+	gp.Press(ButtonSouth)
+	if !gp.State().IsPressed(ButtonSouth) {
+		t.Error("State must reflect Press")
+	}
+	mock.Reset()
+
+	gp.SetState(GamepadState{Buttons: map[Button]bool{ButtonSouth: true}, LeftStick: Stick{}})
+	for _, e := range mock.Events() {
+		if e.Code == uint16(linux.BTN_SOUTH) {
+			t.Fatalf("BTN_SOUTH already pressed, got %+v", e)
+		}
+	}
+}
+
+func TestGamepad_SetState_PartialTrigger(t *testing.T) {
+	mock := vdtest.NewDevice()
+	gp := newTestGamepad(mock)
+
+	gp.SetState(GamepadState{LeftTrigger: 0.5})
+	frame, _ := mock.NextFrame()
+	found := false
+	for _, e := range frame {
+		if e.Code == uint16(linux.ABS_Z) {
+			found = true
+			if e.Value != 127 {
+				t.Errorf("ABS_Z = %d, want 127", e.Value)
+			}
+		}
+	}
+	if !found {
+		t.Error("missing ABS_Z")
+	}
+}
+
+func TestGamepad_SetState_ScanCode(t *testing.T) {
+	mock := vdtest.NewDevice()
+	gp := NewVirtualGamepadFactory().
+		WithDevice(mock).
+		WithDigital(MappingDigital{
+			ButtonSouth: []InputEvent{MSCScanCode(90002), linux.BTN_EAST},
+		}).
+		Create()
+
+	gp.SetState(GamepadState{Buttons: map[Button]bool{ButtonSouth: true}})
+	mock.ExpectFrame(t,
+		vdtest.Misc(linux.MSC_SCAN, 90002),
+		vdtest.Button(linux.BTN_EAST, 1),
+	)
+
+	gp.SetState(GamepadState{Buttons: map[Button]bool{ButtonSouth: true}})
+	mock.ExpectNoFrame(t)
+}
@@ -0,0 +1,159 @@
+package gamepad
+
+import "math"
+
+// StickTransform rewrites a normalized stick position before it is sent to the kernel.
+type StickTransform interface {
+	Apply(x, y float32) (float32, float32)
+}
+
+// StickTransformFunc adapts a function to the StickTransform interface.
+type StickTransformFunc func(x, y float32) (float32, float32)
+
+func (f StickTransformFunc) Apply(x, y float32) (float32, float32) {
+	return f(x, y)
+}
+
+// StickChain applies the transforms in order.
+func StickChain(transforms ...StickTransform) StickTransform {
+	return StickTransformFunc(func(x, y float32) (float32, float32) {
+		for _, transform := range transforms {
+			x, y = transform.Apply(x, y)
+		}
+		return x, y
+	})
+}
+
+// Calibrate recenters and rescales a raw position: each axis becomes (value - center) / scale.
+// A zero scale leaves the axis unscaled.
+func Calibrate(centerX, centerY, scaleX, scaleY float32) StickTransform {
+	if scaleX == 0 {
+		scaleX = 1
+	}
+	if scaleY == 0 {
+		scaleY = 1
+	}
+	return StickTransformFunc(func(x, y float32) (float32, float32) {
+		return clampAxis((x - centerX) / scaleX), clampAxis((y - centerY) / scaleY)
+	})
+}
+
+// RadialDeadzone ignores the positions whose distance to the center is below inner,
+// and rescales the others so that the output still covers the full range.
+func RadialDeadzone(inner float32) StickTransform {
+	return radial(func(m float64) float64 {
+		if m <= float64(inner) {
+			return 0
+		}
+		return (m - float64(inner)) / (1 - float64(inner))
+	})
+}
+
+// AxialDeadzone ignores each axis independently while it is below inner, rescaling the remaining range.
+func AxialDeadzone(inner float32) StickTransform {
+	deadzone := func(v float32) float32 {
+		m := float32(math.Abs(float64(v)))
+		if m <= inner {
+			return 0
+		}
+		return float32(math.Copysign(float64((m-inner)/(1-inner)), float64(v)))
+	}
+	return StickTransformFunc(func(x, y float32) (float32, float32) {
+		return clampAxis(deadzone(x)), clampAxis(deadzone(y))
+	})
+}
+
+// AntiDeadzone compensates the deadzone of the consumer: any position off-center starts at outer.
+func AntiDeadzone(outer float32) StickTransform {
+	return radial(func(m float64) float64 {
+		if m == 0 {
+			return 0
+		}
+		return float64(outer) + m*(1-float64(outer))
+	})
+}
+
+// LinearCurve multiplies the distance to the center by sensitivity.
+func LinearCurve(sensitivity float32) StickTransform {
+	return radial(func(m float64) float64 {
+		return m * float64(sensitivity)
+	})
+}
+
+// ExponentialCurve raises the distance to the center to the given power, exponents above 1 giving more precision near the center.
+func ExponentialCurve(exponent float32) StickTransform {
+	return radial(func(m float64) float64 {
+		return math.Pow(m, float64(exponent))
+	})
+}
+
+// TableCurve maps the distance to the center through a lookup table of evenly spaced points between 0 and 1,
+// interpolating linearly between them. It needs at least two points.
+func TableCurve(points ...float32) StickTransform {
+	if len(points) < 2 {
+		return StickTransformFunc(func(x, y float32) (float32, float32) {
+			return x, y
+		})
+	}
+	return radial(func(m float64) float64 {
+		position := m * float64(len(points)-1)
+		index := int(position)
+		if index >= len(points)-1 {
+			return float64(points[len(points)-1])
+		}
+		ratio := position - float64(index)
+		return float64(points[index]) + ratio*float64(points[index+1]-points[index])
+	})
+}
+
+// InvertX flips the horizontal axis.
+func InvertX() StickTransform {
+	return StickTransformFunc(func(x, y float32) (float32, float32) {
+		return -x, y
+	})
+}
+
+// InvertY flips the vertical axis.
+func InvertY() StickTransform {
+	return StickTransformFunc(func(x, y float32) (float32, float32) {
+		return x, -y
+	})
+}
+
+// CircleToSquare stretches a circular gate to the square range of the axes,
+// so that the diagonals reach the corners as they do on square-gated sticks.
+func CircleToSquare() StickTransform {
+	return StickTransformFunc(func(x, y float32) (float32, float32) {
+		u, v := float64(x), float64(y)
+		if m := math.Hypot(u, v); m > 1 {
+			u, v = u/m, v/m
+		}
+		u2, v2 := u*u, v*v
+		sqrt8 := 2 * math.Sqrt2
+		sx := 0.5*math.Sqrt(math.Max(0, 2+u2-v2+sqrt8*u)) - 0.5*math.Sqrt(math.Max(0, 2+u2-v2-sqrt8*u))
+		sy := 0.5*math.Sqrt(math.Max(0, 2-u2+v2+sqrt8*v)) - 0.5*math.Sqrt(math.Max(0, 2-u2+v2-sqrt8*v))
+		return clampAxis(float32(sx)), clampAxis(float32(sy))
+	})
+}
+
+// radial applies fn to the distance to the center, keeping the direction.
+func radial(fn func(m float64) float64) StickTransform {
+	return StickTransformFunc(func(x, y float32) (float32, float32) {
+		m := math.Hypot(float64(x), float64(y))
+		if m == 0 {
+			return 0, 0
+		}
+		scaled := math.Min(1, math.Max(0, fn(math.Min(1, m))))
+		return float32(float64(x) / m * scaled), float32(float64(y) / m * scaled)
+	})
+}
+
+func clampAxis(value float32) float32 {
+	if value < -1 {
+		return -1
+	}
+	if value > 1 {
+		return 1
+	}
+	return value
+}
@@ -0,0 +1,111 @@
+package gamepad
+
+import (
+	"math"
+	"testing"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+func near(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 1e-3
+}
+
+func TestRadialDeadzone(t *testing.T) {
+	dz := RadialDeadzone(0.2)
+
+	if x, y := dz.Apply(0.1, 0.1); x != 0 || y != 0 {
+		t.Errorf("inside deadzone = (%v, %v), want (0, 0)", x, y)
+	}
+	if x, y := dz.Apply(0.6, 0); !near(x, 0.5) || y != 0 {
+		t.Errorf("Apply(0.6, 0) = (%v, %v), want (0.5, 0)", x, y)
+	}
+	if x, _ := dz.Apply(1, 0); !near(x, 1) {
+		t.Errorf("Apply(1, 0) = %v, want full range", x)
+	}
+}
+
+func TestAxialDeadzone(t *testing.T) {
+	dz := AxialDeadzone(0.2)
+
+	if x, y := dz.Apply(0.1, -0.6); x != 0 || !near(y, -0.5) {
+		t.Errorf("Apply(0.1, -0.6) = (%v, %v), want (0, -0.5)", x, y)
+	}
+}
+
+func TestAntiDeadzone(t *testing.T) {
+	adz := AntiDeadzone(0.25)
+
+	if x, y := adz.Apply(0, 0); x != 0 || y != 0 {
+		t.Errorf("center = (%v, %v), want (0, 0)", x, y)
+	}
+	if x, _ := adz.Apply(0.01, 0); x < 0.25 {
+		t.Errorf("Apply(0.01, 0) = %v, want at least 0.25", x)
+	}
+}
+
+func TestCurves(t *testing.T) {
+	if x, _ := ExponentialCurve(2).Apply(0.5, 0); !near(x, 0.25) {
+		t.Errorf("ExponentialCurve(2) = %v, want 0.25", x)
+	}
+	if x, _ := LinearCurve(2).Apply(0.75, 0); !near(x, 1) {
+		t.Errorf("LinearCurve(2) = %v, want clamped to 1", x)
+	}
+	table := TableCurve(0, 0.1, 1)
+	if x, _ := table.Apply(0.25, 0); !near(x, 0.05) {
+		t.Errorf("TableCurve at 0.25 = %v, want 0.05", x)
+	}
+	if x, _ := table.Apply(0.75, 0); !near(x, 0.55) {
+		t.Errorf("TableCurve at 0.75 = %v, want 0.55", x)
+	}
+}
+
+func TestCircleToSquare(t *testing.T) {
+	d := float32(math.Sqrt2 / 2)
+	if x, y := CircleToSquare().Apply(d, -d); !near(x, 1) || !near(y, -1) {
+		t.Errorf("diagonal = (%v, %v), want (1, -1)", x, y)
+	}
+	if x, y := CircleToSquare().Apply(1, 0); !near(x, 1) || !near(y, 0) {
+		t.Errorf("edge = (%v, %v), want (1, 0)", x, y)
+	}
+}
+
+func TestCalibrateAndInvert(t *testing.T) {
+	chain := StickChain(Calibrate(0.1, 0, 0.9, 1), InvertY())
+	if x, y := chain.Apply(1, 0.5); !near(x, 1) || !near(y, -0.5) {
+		t.Errorf("chain = (%v, %v), want (1, -0.5)", x, y)
+	}
+}
+
+func TestCalibrate_ZeroScale(t *testing.T) {
+	if x, y := Calibrate(0.1, -0.2, 0, 0).Apply(0.5, 0.3); !near(x, 0.4) || !near(y, 0.5) {
+		t.Errorf("calibrated = (%v, %v), want (0.4, 0.5)", x, y)
+	}
+}
+
+func TestGamepad_StickTransform(t *testing.T) {
+	mock := vdtest.NewDevice()
+	gp := NewVirtualGamepadFactory().
+		WithDevice(mock).
+		WithLeftStick(MappingStick{
+			X: virtual_device.AbsAxis{Axis: linux.ABS_X, Min: -100, Max: 100},
+			Y: virtual_device.AbsAxis{Axis: linux.ABS_Y, Min: -100, Max: 100},
+		}).
+		WithLeftStickTransform(RadialDeadzone(0.2), InvertX()).
+		Create()
+
+	gp.MoveLeftStick(0.1, 0)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_X, 0), vdtest.Abs(linux.ABS_Y, 0))
+
+	gp.MoveLeftStickX(1)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_X, -100))
+
+	gp.SetState(GamepadState{LeftStick: Stick{X: 0.6}})
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_X, -50))
+
+	if gp.State().LeftStick.X != 0.6 {
+		t.Errorf("State must keep the raw position, got %v", gp.State().LeftStick.X)
+	}
+}
@@ -0,0 +1,78 @@
+package gamepad
+
+import (
+	"testing"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+func newTestTriggerGamepad(mock *vdtest.Device, threshold float32) VirtualGamepad {
+	return NewVirtualGamepadFactory().
+		WithDevice(mock).
+		WithDigital(MappingDigital{
+			ButtonL2: []InputEvent{MSCScanCode(90009), virtual_device.AbsAxis{Axis: linux.ABS_BRAKE, Min: 0, Max: 255}, linux.BTN_TL2},
+			ButtonR2: []InputEvent{virtual_device.AbsAxis{Axis: linux.ABS_GAS, Min: 0, Max: 255}, linux.BTN_TR2},
+		}).
+		WithTriggerThreshold(threshold).
+		Create()
+}
+
+func TestGamepad_PressAnalog_BelowThreshold(t *testing.T) {
+	mock := vdtest.NewDevice()
+	gp := newTestTriggerGamepad(mock, DefaultTriggerThreshold)
+
+	gp.PressAnalog(ButtonL2, 0.2)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_BRAKE, 51))
+	mock.ExpectButtonPressed(t, linux.BTN_TL2, false)
+}
+
+func TestGamepad_PressAnalog_CrossThreshold(t *testing.T) {
+	mock := vdtest.NewDevice()
+	gp := newTestTriggerGamepad(mock, DefaultTriggerThreshold)
+
+	gp.PressAnalog(ButtonL2, 0.2)
+	gp.PressAnalog(ButtonL2, 0.6)
+	gp.PressAnalog(ButtonL2, 0)
+
+	mock.NextFrame()
+	mock.ExpectFrame(t,
+		vdtest.Misc(linux.MSC_SCAN, 90009),
+		vdtest.Abs(linux.ABS_BRAKE, 153),
+		vdtest.Button(linux.BTN_TL2, 1),
+	)
+	mock.ExpectFrame(t,
+		vdtest.Misc(linux.MSC_SCAN, 90009),
+		vdtest.Abs(linux.ABS_BRAKE, 0),
+		vdtest.Button(linux.BTN_TL2, 0),
+	)
+}
+
+func TestGamepad_MoveTrigger(t *testing.T) {
+	mock := vdtest.NewDevice()
+	gp := newTestTriggerGamepad(mock, 0.1)
+
+	gp.MoveTrigger(0, 1)
+	mock.ExpectFrame(t,
+		vdtest.Abs(linux.ABS_GAS, 255),
+		vdtest.Button(linux.BTN_TR2, 1),
+	)
+
+	state := gp.State()
+	if state.RightTrigger != 1 || state.LeftTrigger != 0 {
+		t.Errorf("State triggers = %v/%v, want 0/1", state.LeftTrigger, state.RightTrigger)
+	}
+}
+
+func TestGamepad_Release_ResetsAnalog(t *testing.T) {
+	mock := vdtest.NewDevice()
+	gp := newTestTriggerGamepad(mock, DefaultTriggerThreshold)
+
+	gp.PressAnalog(ButtonR2, 0.8)
+	gp.Release(ButtonR2)
+	if gp.State().RightTrigger != 0 {
+		t.Errorf("RightTrigger = %v after Release, want 0", gp.State().RightTrigger)
+	}
+	mock.ExpectButtonPressed(t, linux.BTN_TR2, false)
+}
@@ -55,3 +55,16 @@ func minMax(values []int32) (int32, int32, error) {
 	}
 	return minValue, maxValue, nil
 }
+
+// unique removes the duplicated codes, keeping the first occurrence, as several logical buttons may share a code.
+func unique[T comparable](values []T) []T {
+	seen := make(map[T]bool, len(values))
+	result := make([]T, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	return result
+}
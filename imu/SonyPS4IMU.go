@@ -0,0 +1,23 @@
+package imu
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/sdl"
+)
+
+// NewSonyPS4IMU creates a virtual IMU device emulating the "Motion Sensors" node of a DualShock 4.
+func NewSonyPS4IMU() VirtualIMU {
+	return NewVirtualIMUFactory().
+		WithDevice(
+			virtual_device.
+				NewVirtualDevice().
+				WithBusType(linux.BUS_USB).
+				WithVendor(sdl.USB_VENDOR_SONY).
+				WithProduct(sdl.USB_PRODUCT_SONY_DS4_SLIM).
+				WithVersion(0x8111).
+				WithName("Sony Interactive Entertainment Wireless Controller Motion Sensors"),
+		).
+		WithAxes(PlayStationAxes()).
+		Create()
+}
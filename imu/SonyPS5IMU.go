@@ -0,0 +1,23 @@
+package imu
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/sdl"
+)
+
+// NewSonyPS5IMU creates a virtual IMU device emulating the "Motion Sensors" node of a DualSense.
+func NewSonyPS5IMU() VirtualIMU {
+	return NewVirtualIMUFactory().
+		WithDevice(
+			virtual_device.
+				NewVirtualDevice().
+				WithBusType(linux.BUS_USB).
+				WithVendor(sdl.USB_VENDOR_SONY).
+				WithProduct(sdl.USB_PRODUCT_SONY_DS5).
+				WithVersion(0x8111).
+				WithName("Sony Interactive Entertainment DualSense Wireless Controller Motion Sensors"),
+		).
+		WithAxes(PlayStationAxes()).
+		Create()
+}
@@ -0,0 +1,23 @@
+package imu
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/sdl"
+)
+
+// NewSwitchProIMU creates a virtual IMU device emulating the accelerometer and gyroscope of a Nintendo Switch Pro controller.
+func NewSwitchProIMU() VirtualIMU {
+	return NewVirtualIMUFactory().
+		WithDevice(
+			virtual_device.
+				NewVirtualDevice().
+				WithBusType(linux.BUS_USB).
+				WithVendor(sdl.USB_VENDOR_NINTENDO).
+				WithProduct(sdl.USB_PRODUCT_NINTENDO_SWITCH_PRO).
+				WithVersion(0x8111).
+				WithName("Nintendo Co., Ltd. Pro Controller (IMU)"),
+		).
+		WithAxes(NintendoAxes()).
+		Create()
+}
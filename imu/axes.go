@@ -0,0 +1,36 @@
+package imu
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// PlayStationAxes returns the motion axes of the DualShock 4 and DualSense, as reported by hid-playstation.
+func PlayStationAxes() []virtual_device.AbsAxis {
+	return []virtual_device.AbsAxis{
+		// accelerometer, 8192 units per g
+		{Axis: linux.ABS_X, Min: -32768, Value: 0, Max: 32768, Fuzz: 16, Resolution: 8192},
+		{Axis: linux.ABS_Y, Min: -32768, Value: 0, Max: 32768, Fuzz: 16, Resolution: 8192},
+		{Axis: linux.ABS_Z, Min: -32768, Value: 0, Max: 32768, Fuzz: 16, Resolution: 8192},
+
+		// gyroscope, 1024 units per degree per second
+		{Axis: linux.ABS_RX, Min: -2097152, Value: 0, Max: 2097152, Fuzz: 16, Resolution: 1024},
+		{Axis: linux.ABS_RY, Min: -2097152, Value: 0, Max: 2097152, Fuzz: 16, Resolution: 1024},
+		{Axis: linux.ABS_RZ, Min: -2097152, Value: 0, Max: 2097152, Fuzz: 16, Resolution: 1024},
+	}
+}
+
+// NintendoAxes returns the motion axes of the Joy-Cons and the Switch Pro controller, as reported by hid-nintendo.
+func NintendoAxes() []virtual_device.AbsAxis {
+	return []virtual_device.AbsAxis{
+		// accelerometer that measures linear acceleration on three axes: X, Y, and Z
+		{Axis: linux.ABS_X, Min: -32767, Value: 0, Max: 32767, Fuzz: 10, Resolution: 4096},
+		{Axis: linux.ABS_Y, Min: -32767, Value: 0, Max: 32767, Fuzz: 10, Resolution: 4096},
+		{Axis: linux.ABS_Z, Min: -32767, Value: 0, Max: 32767, Fuzz: 10, Resolution: 4096},
+
+		// gyroscope that measures rotational velocity on three axes: RX, RY, and RZ
+		{Axis: linux.ABS_RX, Min: -32767000, Value: 0, Max: 32767000, Fuzz: 10, Resolution: 14247},
+		{Axis: linux.ABS_RY, Min: -32767000, Value: 0, Max: 32767000, Fuzz: 10, Resolution: 14247},
+		{Axis: linux.ABS_RZ, Min: -32767000, Value: 0, Max: 32767000, Fuzz: 10, Resolution: 14247},
+	}
+}
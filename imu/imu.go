@@ -0,0 +1,156 @@
+package imu
+
+import (
+	"math"
+	"time"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/clock"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// StandardGravity is the acceleration of gravity in m/s², the unit of the accelerometer resolutions being g.
+const StandardGravity = 9.80665
+
+// VirtualIMU is a high-level virtual motion sensor: an accelerometer on ABS_X, ABS_Y and ABS_Z
+// and a gyroscope on ABS_RX, ABS_RY and ABS_RZ.
+type VirtualIMU interface {
+	Register() error
+	Unregister() error
+
+	// SetMotion reports an acceleration in m/s² and an angular velocity in degrees per second, in a single frame.
+	SetMotion(accel [3]float64, gyro [3]float64)
+	// SetOrientation reports the gravity measured by the accelerometer in the given orientation,
+	// along with an angular velocity in degrees per second.
+	SetOrientation(orientation Quaternion, angularVelocity [3]float64)
+
+	Send(evType, code uint16, value int32)
+
+	EventPath() string
+	DeviceInfo() (virtual_device.DeviceInfo, error)
+}
+
+// VirtualIMUFactory configures and creates VirtualIMU instances.
+type VirtualIMUFactory interface {
+	WithDevice(device virtual_device.VirtualDevice) VirtualIMUFactory
+	WithAxes(absoluteAxes []virtual_device.AbsAxis) VirtualIMUFactory
+	WithClock(clock clock.Clock) VirtualIMUFactory
+	Create() VirtualIMU
+}
+
+// NewVirtualIMUFactory returns a new factory for building virtual IMUs.
+func NewVirtualIMUFactory() VirtualIMUFactory {
+	return &virtualIMUFactory{}
+}
+
+type virtualIMUFactory struct {
+	device virtual_device.VirtualDevice
+	axes   []virtual_device.AbsAxis
+	clock  clock.Clock
+}
+
+func (f *virtualIMUFactory) WithDevice(device virtual_device.VirtualDevice) VirtualIMUFactory {
+	f.device = device
+	return f
+}
+
+func (f *virtualIMUFactory) WithAxes(axes []virtual_device.AbsAxis) VirtualIMUFactory {
+	f.axes = axes
+	return f
+}
+
+func (f *virtualIMUFactory) WithClock(clock clock.Clock) VirtualIMUFactory {
+	f.clock = clock
+	return f
+}
+
+func (f *virtualIMUFactory) Create() VirtualIMU {
+	c := f.clock
+	if c == nil {
+		c = clock.New()
+	}
+
+	f.device.WithAbsAxes(f.axes)
+	f.device.WithMiscEvents([]linux.MiscEvent{linux.MSC_TIMESTAMP})
+	f.device.WithProperties([]linux.InputProp{linux.INPUT_PROP_ACCELEROMETER})
+
+	axes := map[linux.AbsoluteAxis]virtual_device.AbsAxis{}
+	for _, axis := range f.axes {
+		axes[axis.Axis] = axis
+	}
+
+	return &virtualIMU{
+		device: f.device,
+		axes:   axes,
+		clock:  c,
+	}
+}
+
+var (
+	accelerometerAxes = [3]linux.AbsoluteAxis{linux.ABS_X, linux.ABS_Y, linux.ABS_Z}
+	gyroscopeAxes     = [3]linux.AbsoluteAxis{linux.ABS_RX, linux.ABS_RY, linux.ABS_RZ}
+)
+
+type virtualIMU struct {
+	device  virtual_device.VirtualDevice
+	axes    map[linux.AbsoluteAxis]virtual_device.AbsAxis
+	clock   clock.Clock
+	started bool
+	start   time.Time
+}
+
+func (vi *virtualIMU) Register() error {
+	return vi.device.Register()
+}
+
+func (vi *virtualIMU) Unregister() error {
+	return vi.device.Unregister()
+}
+
+func (vi *virtualIMU) SetMotion(accel [3]float64, gyro [3]float64) {
+	vi.device.SendMiscEvent(linux.MSC_TIMESTAMP, vi.timestamp())
+	for i, axis := range accelerometerAxes {
+		vi.sendScaled(axis, accel[i]/StandardGravity)
+	}
+	for i, axis := range gyroscopeAxes {
+		vi.sendScaled(axis, gyro[i])
+	}
+	vi.device.SyncReport()
+}
+
+func (vi *virtualIMU) SetOrientation(orientation Quaternion, angularVelocity [3]float64) {
+	vi.SetMotion(orientation.Gravity(), angularVelocity)
+}
+
+// timestamp returns the microseconds elapsed since the first report, wrapping around like the hardware counters.
+func (vi *virtualIMU) timestamp() int32 {
+	now := vi.clock.Now()
+	if !vi.started {
+		vi.started = true
+		vi.start = now
+	}
+	return int32(uint32(now.Sub(vi.start).Microseconds()))
+}
+
+// sendScaled converts a physical value using the resolution of the axis (units per g or per degree per second).
+func (vi *virtualIMU) sendScaled(code linux.AbsoluteAxis, value float64) {
+	axis, exists := vi.axes[code]
+	if !exists {
+		return
+	}
+	raw := math.Round(value * float64(axis.Resolution))
+	raw = math.Max(float64(axis.Min), math.Min(float64(axis.Max), raw))
+	vi.device.SendAbsoluteEvent(code, int32(raw))
+}
+
+func (vi *virtualIMU) Send(evType, code uint16, value int32) {
+	vi.device.Send(evType, code, value)
+}
+
+func (vi *virtualIMU) EventPath() string {
+	return vi.device.EventPath()
+}
+
+func (vi *virtualIMU) DeviceInfo() (virtual_device.DeviceInfo, error) {
+	return vi.device.DeviceInfo()
+}
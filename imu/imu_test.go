@@ -0,0 +1,107 @@
+package imu
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jbdemonte/virtual-device/clock"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+func newMockIMU() (VirtualIMU, *vdtest.Device, *clock.Fake) {
+	fake := clock.NewFake(time.Unix(0, 0))
+	device := vdtest.NewDevice()
+	vi := NewVirtualIMUFactory().WithDevice(device).WithAxes(PlayStationAxes()).WithClock(fake).Create()
+	return vi, device, fake
+}
+
+func TestVirtualIMU_Create(t *testing.T) {
+	_, device, _ := newMockIMU()
+
+	if len(device.AbsAxes) != 6 {
+		t.Errorf("axes = %v", device.AbsAxes)
+	}
+	if len(device.MiscEvents) != 1 || device.MiscEvents[0] != linux.MSC_TIMESTAMP {
+		t.Errorf("misc events = %v", device.MiscEvents)
+	}
+	if len(device.Properties) != 1 || device.Properties[0] != linux.INPUT_PROP_ACCELEROMETER {
+		t.Errorf("properties = %v", device.Properties)
+	}
+}
+
+func TestVirtualIMU_SetMotion(t *testing.T) {
+	vi, device, _ := newMockIMU()
+
+	vi.SetMotion([3]float64{0, StandardGravity, -StandardGravity / 2}, [3]float64{10, -90, 0.5})
+
+	device.ExpectFrame(t,
+		vdtest.Misc(linux.MSC_TIMESTAMP, 0),
+		vdtest.Abs(linux.ABS_X, 0),
+		vdtest.Abs(linux.ABS_Y, 8192),
+		vdtest.Abs(linux.ABS_Z, -4096),
+		vdtest.Abs(linux.ABS_RX, 10240),
+		vdtest.Abs(linux.ABS_RY, -92160),
+		vdtest.Abs(linux.ABS_RZ, 512),
+	)
+}
+
+func TestVirtualIMU_Clamp(t *testing.T) {
+	vi, device, _ := newMockIMU()
+
+	vi.SetMotion([3]float64{100 * StandardGravity, 0, 0}, [3]float64{0, 0, -5000})
+
+	device.ExpectFrame(t,
+		vdtest.Misc(linux.MSC_TIMESTAMP, 0),
+		vdtest.Abs(linux.ABS_X, 32768),
+		vdtest.Abs(linux.ABS_Y, 0),
+		vdtest.Abs(linux.ABS_Z, 0),
+		vdtest.Abs(linux.ABS_RX, 0),
+		vdtest.Abs(linux.ABS_RY, 0),
+		vdtest.Abs(linux.ABS_RZ, -2097152),
+	)
+}
+
+func TestVirtualIMU_Timestamp(t *testing.T) {
+	vi, device, fake := newMockIMU()
+
+	vi.SetMotion([3]float64{}, [3]float64{})
+	fake.Advance(4 * time.Millisecond)
+	vi.SetMotion([3]float64{}, [3]float64{})
+	fake.Advance(1500 * time.Microsecond)
+	vi.SetMotion([3]float64{}, [3]float64{})
+
+	for _, want := range []int32{0, 4000, 5500} {
+		device.ExpectFrameContaining(t, vdtest.Misc(linux.MSC_TIMESTAMP, want))
+	}
+}
+
+func TestVirtualIMU_SetOrientation(t *testing.T) {
+	vi, device, _ := newMockIMU()
+
+	// tilted forward by 90 degrees around X: the gravity moves from Y to -Z
+	vi.SetOrientation(QuaternionFromAxisAngle([3]float64{1, 0, 0}, 90), [3]float64{0, 0, 0})
+
+	device.ExpectFrameContaining(t,
+		vdtest.Abs(linux.ABS_X, 0),
+		vdtest.Abs(linux.ABS_Y, 0),
+		vdtest.Abs(linux.ABS_Z, -8192),
+	)
+}
+
+func TestQuaternion_Rotate(t *testing.T) {
+	q := QuaternionFromAxisAngle([3]float64{0, 0, 1}, 90)
+	got := q.Rotate([3]float64{1, 0, 0})
+	want := [3]float64{0, 1, 0}
+	for i := range got {
+		if math.Abs(got[i]-want[i]) > 1e-9 {
+			t.Fatalf("Rotate = %v, want %v", got, want)
+		}
+	}
+
+	gravity := IdentityQuaternion.Gravity()
+	if gravity != [3]float64{0, StandardGravity, 0} {
+		t.Errorf("Gravity = %v", gravity)
+	}
+}
@@ -0,0 +1,60 @@
+package imu
+
+import "math"
+
+// Quaternion is a rotation from the frame of the device to the world frame.
+// The identity is the device lying flat, its Y axis pointing up.
+type Quaternion struct {
+	W, X, Y, Z float64
+}
+
+// IdentityQuaternion is the device lying flat.
+var IdentityQuaternion = Quaternion{W: 1}
+
+// QuaternionFromAxisAngle returns the rotation of the given angle, in degrees, around an axis.
+func QuaternionFromAxisAngle(axis [3]float64, degrees float64) Quaternion {
+	norm := math.Sqrt(axis[0]*axis[0] + axis[1]*axis[1] + axis[2]*axis[2])
+	if norm == 0 {
+		return IdentityQuaternion
+	}
+	half := degrees * math.Pi / 360
+	s := math.Sin(half) / norm
+	return Quaternion{W: math.Cos(half), X: axis[0] * s, Y: axis[1] * s, Z: axis[2] * s}
+}
+
+// Normalize returns the quaternion scaled to a unit length, the identity if it is zero.
+func (q Quaternion) Normalize() Quaternion {
+	norm := math.Sqrt(q.W*q.W + q.X*q.X + q.Y*q.Y + q.Z*q.Z)
+	if norm == 0 {
+		return IdentityQuaternion
+	}
+	return Quaternion{W: q.W / norm, X: q.X / norm, Y: q.Y / norm, Z: q.Z / norm}
+}
+
+// Mul returns the rotation q applied after r.
+func (q Quaternion) Mul(r Quaternion) Quaternion {
+	return Quaternion{
+		W: q.W*r.W - q.X*r.X - q.Y*r.Y - q.Z*r.Z,
+		X: q.W*r.X + q.X*r.W + q.Y*r.Z - q.Z*r.Y,
+		Y: q.W*r.Y - q.X*r.Z + q.Y*r.W + q.Z*r.X,
+		Z: q.W*r.Z + q.X*r.Y - q.Y*r.X + q.Z*r.W,
+	}
+}
+
+// Conjugate returns the inverse rotation of a unit quaternion.
+func (q Quaternion) Conjugate() Quaternion {
+	return Quaternion{W: q.W, X: -q.X, Y: -q.Y, Z: -q.Z}
+}
+
+// Rotate applies the rotation to a vector.
+func (q Quaternion) Rotate(v [3]float64) [3]float64 {
+	q = q.Normalize()
+	r := q.Mul(Quaternion{X: v[0], Y: v[1], Z: v[2]}).Mul(q.Conjugate())
+	return [3]float64{r.X, r.Y, r.Z}
+}
+
+// Gravity returns, in m/s², what an accelerometer at rest measures in this orientation:
+// the reaction to gravity, pointing up, expressed in the frame of the device.
+func (q Quaternion) Gravity() [3]float64 {
+	return q.Conjugate().Rotate([3]float64{0, StandardGravity, 0})
+}
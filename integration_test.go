@@ -106,6 +106,110 @@ func TestIntegration_DoubleRegister(t *testing.T) {
 	}
 }
 
+func TestIntegration_DeviceInfo(t *testing.T) {
+	vd := NewVirtualDevice().
+		WithBusType(linux.BUS_USB).
+		WithVendor(0xDEAD).
+		WithProduct(0xBEEF).
+		WithVersion(0x01).
+		WithName("test-device-info").
+		WithKeys([]linux.Key{linux.KEY_A}).
+		WithRelAxes([]linux.RelativeAxis{linux.REL_X, linux.REL_Y})
+
+	if err := vd.Register(); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	defer vd.Unregister()
+
+	info, err := vd.DeviceInfo()
+	if err != nil {
+		t.Fatalf("DeviceInfo: %v", err)
+	}
+
+	if info.EventPath != vd.EventPath() {
+		t.Errorf("EventPath = %q, want %q", info.EventPath, vd.EventPath())
+	}
+	if info.Name != "test-device-info" {
+		t.Errorf("Name = %q, want test-device-info", info.Name)
+	}
+	if info.ID.Vendor != 0xDEAD || info.ID.Product != 0xBEEF {
+		t.Errorf("ID = %+v", info.ID)
+	}
+	if !info.Capabilities.HasKey(linux.KEY_A) {
+		t.Error("KEY_A not reported by the kernel")
+	}
+	if !info.Capabilities.HasRelAxis(linux.REL_Y) {
+		t.Error("REL_Y not reported by the kernel")
+	}
+}
+
+func TestIntegration_PhysAndSwitches(t *testing.T) {
+	vd := NewVirtualDevice().
+		WithName("test-phys-switches").
+		WithPhys("usb-virtual-test/input0").
+		WithKeys([]linux.Key{linux.KEY_A}).
+		WithSwitches([]linux.SwitchEvent{linux.SW_HEADPHONE_INSERT, linux.SW_MICROPHONE_INSERT})
+
+	if err := vd.Register(); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	defer vd.Unregister()
+
+	info, err := vd.DeviceInfo()
+	if err != nil {
+		t.Fatalf("DeviceInfo: %v", err)
+	}
+	if info.Phys != "usb-virtual-test/input0" {
+		t.Errorf("Phys = %q, want usb-virtual-test/input0", info.Phys)
+	}
+	if !info.Capabilities.HasEvent(linux.EV_SW) || len(info.Capabilities.Switches) != 2 {
+		t.Errorf("Switches = %v", info.Capabilities.Switches)
+	}
+}
+
+func TestIntegration_ForceFeedback(t *testing.T) {
+	requests := make(chan ForceFeedbackEvent, 8)
+	vd := NewVirtualDevice().
+		WithName("test-force-feedback").
+		WithAbsAxes([]AbsAxis{{Axis: linux.ABS_X, Max: 65535}}).
+		WithForceFeedback([]linux.FFEffectType{linux.FF_CONSTANT}, 4).
+		WithForceFeedbackHandler(func(event ForceFeedbackEvent) { requests <- event })
+
+	if err := vd.Register(); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	defer vd.Unregister()
+
+	f, err := os.OpenFile(vd.EventPath(), os.O_RDWR, 0)
+	if err != nil {
+		t.Fatalf("open event file: %v", err)
+	}
+	defer f.Close()
+
+	effect := linux.FFEffect{Type: uint16(linux.FF_CONSTANT), ID: -1, Direction: 0x4000}
+	effect.SetConstant(linux.FFConstantEffect{Level: 0x4000})
+	if err := ioctl(f, linux.EVIOCSFF(), uintptr(unsafe.Pointer(&effect))); err != nil {
+		t.Fatalf("EVIOCSFF: %v", err)
+	}
+	upload := <-requests
+	if upload.Type != FFUpload || upload.Effect.Constant().Level != 0x4000 || upload.EffectID != effect.ID {
+		t.Errorf("unexpected upload %+v, effect ID %d", upload, effect.ID)
+	}
+
+	play := linux.InputEvent{Type: uint16(linux.EV_FF), Code: uint16(effect.ID), Value: 1}
+	if _, err := f.Write((*[unsafe.Sizeof(play)]byte)(unsafe.Pointer(&play))[:]); err != nil {
+		t.Fatalf("write play: %v", err)
+	}
+	select {
+	case event := <-requests:
+		if event.Type != FFPlay || event.EffectID != effect.ID {
+			t.Errorf("unexpected request %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no play request received")
+	}
+}
+
 func readEvents(t *testing.T, f *os.File) []linux.InputEvent {
 	t.Helper()
 
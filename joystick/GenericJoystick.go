@@ -0,0 +1,34 @@
+package joystick
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+func NewGenericJoystick() VirtualJoystick {
+	return newGenericJoystick(
+		virtual_device.NewVirtualDevice().
+			WithBusType(linux.BUS_USB).
+			WithVendor(0xDEAD).
+			WithProduct(0xF11E).
+			WithVersion(0x01).
+			WithName("Generic Flight Stick"),
+	)
+}
+
+func newGenericJoystick(device virtual_device.VirtualDevice) VirtualJoystick {
+	return NewVirtualJoystickFactory().
+		WithDevice(device).
+		WithAxes(
+			MappingAxes{
+				AxisX:        virtual_device.AbsAxis{Axis: linux.ABS_X, Min: -32768, Max: 32767, Flat: 128},
+				AxisY:        virtual_device.AbsAxis{Axis: linux.ABS_Y, Min: -32768, Max: 32767, Flat: 128},
+				AxisTwist:    virtual_device.AbsAxis{Axis: linux.ABS_RZ, Min: -32768, Max: 32767, Flat: 128},
+				AxisThrottle: virtual_device.AbsAxis{Axis: linux.ABS_THROTTLE, Min: 0, Max: 255, IsUnidirectional: true},
+				AxisRudder:   virtual_device.AbsAxis{Axis: linux.ABS_RUDDER, Min: -32768, Max: 32767},
+			},
+		).
+		WithButtons(ButtonCodes(32)).
+		WithHats(4).
+		Create()
+}
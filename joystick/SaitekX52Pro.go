@@ -0,0 +1,40 @@
+package joystick
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/sdl"
+)
+
+// NewSaitekX52Pro creates the X52 Pro, whose stick and throttle are enumerated as a single device.
+func NewSaitekX52Pro() VirtualJoystick {
+	return newSaitekX52Pro(
+		virtual_device.NewVirtualDevice().
+			WithBusType(linux.BUS_USB).
+			WithVendor(sdl.USB_VENDOR_SAITEK).
+			WithProduct(0x0762).
+			WithVersion(0x111).
+			WithName("Saitek Saitek X52 Pro Flight Control System"),
+	)
+}
+
+func newSaitekX52Pro(device virtual_device.VirtualDevice) VirtualJoystick {
+	return NewVirtualJoystickFactory().
+		WithDevice(device).
+		WithAxes(
+			MappingAxes{
+				AxisX:        virtual_device.AbsAxis{Axis: linux.ABS_X, Min: 0, Value: 512, Max: 1023},
+				AxisY:        virtual_device.AbsAxis{Axis: linux.ABS_Y, Min: 0, Value: 512, Max: 1023},
+				AxisTwist:    virtual_device.AbsAxis{Axis: linux.ABS_RZ, Min: 0, Value: 512, Max: 1023},
+				AxisThrottle: virtual_device.AbsAxis{Axis: linux.ABS_Z, Min: 0, Max: 255, IsUnidirectional: true},
+				AxisRotary1:  virtual_device.AbsAxis{Axis: linux.ABS_RX, Min: 0, Max: 255, IsUnidirectional: true},
+				AxisRotary2:  virtual_device.AbsAxis{Axis: linux.ABS_RY, Min: 0, Max: 255, IsUnidirectional: true},
+				AxisSlider:   virtual_device.AbsAxis{Axis: linux.ABS_THROTTLE, Min: 0, Max: 255, IsUnidirectional: true},
+				AxisSlewX:    virtual_device.AbsAxis{Axis: linux.ABS_MISC, Min: 0, Value: 8, Max: 15},     // mouse nub of the throttle
+				AxisSlewY:    virtual_device.AbsAxis{Axis: linux.ABS_MISC + 1, Min: 0, Value: 8, Max: 15}, // unnamed code, after ABS_MISC
+			},
+		).
+		WithButtons(ButtonCodes(39)).
+		WithHats(1).
+		Create()
+}
@@ -0,0 +1,55 @@
+package joystick
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/sdl"
+)
+
+// NewThrustmasterWarthog creates the HOTAS Warthog, its stick and its dual throttle being two USB devices.
+func NewThrustmasterWarthog() HOTAS {
+	return newThrustmasterWarthog(
+		virtual_device.NewVirtualDevice().
+			WithBusType(linux.BUS_USB).
+			WithVendor(sdl.USB_VENDOR_THRUSTMASTER).
+			WithProduct(0x0402).
+			WithVersion(0x111).
+			WithName("Thrustmaster HOTAS Warthog Joystick"),
+		virtual_device.NewVirtualDevice().
+			WithBusType(linux.BUS_USB).
+			WithVendor(sdl.USB_VENDOR_THRUSTMASTER).
+			WithProduct(0x0404).
+			WithVersion(0x111).
+			WithName("Thrustmaster Throttle - HOTAS Warthog"),
+	)
+}
+
+func newThrustmasterWarthog(stick, throttle virtual_device.VirtualDevice) HOTAS {
+	return newHOTAS(
+		NewVirtualJoystickFactory().
+			WithDevice(stick).
+			WithAxes(
+				MappingAxes{
+					AxisX: virtual_device.AbsAxis{Axis: linux.ABS_X, Min: 0, Value: 32768, Max: 65535},
+					AxisY: virtual_device.AbsAxis{Axis: linux.ABS_Y, Min: 0, Value: 32768, Max: 65535},
+				},
+			).
+			WithButtons(ButtonCodes(19)).
+			WithHats(1).
+			Create(),
+		NewVirtualJoystickFactory().
+			WithDevice(throttle).
+			WithAxes(
+				MappingAxes{
+					AxisSlewX:     virtual_device.AbsAxis{Axis: linux.ABS_X, Min: 0, Value: 512, Max: 1023},
+					AxisSlewY:     virtual_device.AbsAxis{Axis: linux.ABS_Y, Min: 0, Value: 512, Max: 1023},
+					AxisThrottle:  virtual_device.AbsAxis{Axis: linux.ABS_Z, Min: 0, Max: 16383, IsUnidirectional: true},       // right lever
+					AxisThrottle2: virtual_device.AbsAxis{Axis: linux.ABS_RZ, Min: 0, Max: 16383, IsUnidirectional: true},      // left lever
+					AxisSlider:    virtual_device.AbsAxis{Axis: linux.ABS_THROTTLE, Min: 0, Max: 1023, IsUnidirectional: true}, // friction
+				},
+			).
+			WithButtons(ButtonCodes(32)).
+			WithHats(1).
+			Create(),
+	)
+}
@@ -0,0 +1,26 @@
+package joystick
+
+// Axis identifies a logical axis of a flight stick or a throttle.
+type Axis int
+
+const (
+	AxisX         Axis = iota + 1 // Stick roll
+	AxisY                         // Stick pitch
+	AxisTwist                     // Stick yaw, usually ABS_RZ
+	AxisThrottle                  // Main throttle lever, usually ABS_THROTTLE
+	AxisThrottle2                 // Second lever of a split throttle
+	AxisRudder                    // Rudder pedals
+	AxisSlider
+	AxisSlewX // Mini-stick of the throttle
+	AxisSlewY
+	AxisRotary1
+	AxisRotary2
+)
+
+// Button is the number of a button, starting at 1 (the trigger), in the order of the codes given to WithButtons.
+type Button int
+
+const ButtonTrigger Button = 1
+
+// MaxHats is the number of hats a device can report, from ABS_HAT0 to ABS_HAT3.
+const MaxHats = 4
@@ -0,0 +1,46 @@
+package joystick
+
+import "errors"
+
+// HOTAS is a flight stick and a throttle sold together but enumerated as two devices,
+// like the Thrustmaster HOTAS Warthog.
+type HOTAS interface {
+	// Register creates both devices, none of them is left registered if one fails.
+	Register() error
+	Unregister() error
+
+	Stick() VirtualJoystick
+	Throttle() VirtualJoystick
+}
+
+type hotas struct {
+	stick    VirtualJoystick
+	throttle VirtualJoystick
+}
+
+func newHOTAS(stick, throttle VirtualJoystick) HOTAS {
+	return &hotas{stick: stick, throttle: throttle}
+}
+
+func (h *hotas) Register() error {
+	if err := h.stick.Register(); err != nil {
+		return err
+	}
+	if err := h.throttle.Register(); err != nil {
+		return errors.Join(err, h.stick.Unregister())
+	}
+	return nil
+}
+
+// Unregister removes both devices, returning all the errors.
+func (h *hotas) Unregister() error {
+	return errors.Join(h.throttle.Unregister(), h.stick.Unregister())
+}
+
+func (h *hotas) Stick() VirtualJoystick {
+	return h.stick
+}
+
+func (h *hotas) Throttle() VirtualJoystick {
+	return h.throttle
+}
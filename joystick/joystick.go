@@ -0,0 +1,245 @@
+package joystick
+
+import (
+	"fmt"
+	"sort"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// VirtualJoystick is a high-level virtual flight stick or throttle, with numbered buttons and up to four hats.
+type VirtualJoystick interface {
+	Register() error
+	Unregister() error
+
+	// MoveStick moves AxisX and AxisY, from -1 to 1, in a single report.
+	MoveStick(x, y float32)
+	Twist(value float32)
+	Throttle(value float32)
+	Rudder(value float32)
+	// SetAxis moves any axis, from -1 to 1, or from 0 to 1 when the axis is unidirectional.
+	SetAxis(axis Axis, value float32)
+	SupportedAxes() []Axis
+
+	Press(button Button)
+	Release(button Button)
+	ButtonCount() int
+
+	// MoveHat sets a hat, from 0 to HatCount()-1, to -1, 0 or 1 on each axis, y being -1 up.
+	MoveHat(hat int, x, y int32)
+	HatCount() int
+
+	Send(evType, code uint16, value int32)
+
+	EventPath() string
+	DeviceInfo() (virtual_device.DeviceInfo, error)
+}
+
+// VirtualJoystickFactory configures and creates VirtualJoystick instances.
+type VirtualJoystickFactory interface {
+	WithDevice(device virtual_device.VirtualDevice) VirtualJoystickFactory
+	WithAxes(mapping MappingAxes) VirtualJoystickFactory
+	WithButtons(codes []linux.Button) VirtualJoystickFactory
+	WithHats(count int) VirtualJoystickFactory
+	Create() VirtualJoystick
+}
+
+type virtualJoystickFactory struct {
+	device  virtual_device.VirtualDevice
+	axes    MappingAxes
+	buttons []linux.Button
+	hats    int
+}
+
+// NewVirtualJoystickFactory returns a new factory for building virtual joysticks.
+func NewVirtualJoystickFactory() VirtualJoystickFactory {
+	return &virtualJoystickFactory{}
+}
+
+func (f *virtualJoystickFactory) WithDevice(device virtual_device.VirtualDevice) VirtualJoystickFactory {
+	f.device = device
+	return f
+}
+
+func (f *virtualJoystickFactory) WithAxes(mapping MappingAxes) VirtualJoystickFactory {
+	f.axes = mapping
+	return f
+}
+
+// WithButtons sets the codes of the buttons, Button(1) being the first one (see ButtonCodes).
+func (f *virtualJoystickFactory) WithButtons(codes []linux.Button) VirtualJoystickFactory {
+	f.buttons = codes
+	return f
+}
+
+// WithHats sets the number of hats, clamped to MaxHats.
+func (f *virtualJoystickFactory) WithHats(count int) VirtualJoystickFactory {
+	if count > MaxHats {
+		count = MaxHats
+	}
+	f.hats = count
+	return f
+}
+
+func (f *virtualJoystickFactory) Create() VirtualJoystick {
+	vj := &virtualJoystick{
+		device:  f.device,
+		axes:    f.axes,
+		buttons: f.buttons,
+		hats:    f.hats,
+	}
+	vj.init()
+	return vj
+}
+
+type virtualJoystick struct {
+	device  virtual_device.VirtualDevice
+	axes    MappingAxes
+	buttons []linux.Button
+	hats    int
+}
+
+func (vj *virtualJoystick) init() {
+	axes := make([]virtual_device.AbsAxis, 0, len(vj.axes)+2*vj.hats)
+	for _, axis := range vj.axes {
+		axes = append(axes, axis)
+	}
+	for i := 0; i < vj.hats; i++ {
+		x, y := hatAxes(i)
+		axes = append(axes,
+			virtual_device.AbsAxis{Axis: x, Min: -1, Max: 1},
+			virtual_device.AbsAxis{Axis: y, Min: -1, Max: 1},
+		)
+	}
+	sort.Slice(axes, func(i, j int) bool { return axes[i].Axis < axes[j].Axis })
+
+	vj.device.WithButtons(vj.buttons)
+	vj.device.WithAbsAxes(axes)
+}
+
+func hatAxes(hat int) (linux.AbsoluteAxis, linux.AbsoluteAxis) {
+	x := linux.ABS_HAT0X + linux.AbsoluteAxis(2*hat)
+	return x, x + 1
+}
+
+func (vj *virtualJoystick) Register() error {
+	return vj.device.Register()
+}
+
+func (vj *virtualJoystick) Unregister() error {
+	return vj.device.Unregister()
+}
+
+func (vj *virtualJoystick) EventPath() string {
+	return vj.device.EventPath()
+}
+
+func (vj *virtualJoystick) DeviceInfo() (virtual_device.DeviceInfo, error) {
+	return vj.device.DeviceInfo()
+}
+
+func (vj *virtualJoystick) Send(evType, code uint16, value int32) {
+	vj.device.Send(evType, code, value)
+}
+
+func (vj *virtualJoystick) sendAxis(axis Axis, value float32) bool {
+	absAxis, exist := vj.axes[axis]
+	if !exist {
+		fmt.Printf("axis not assigned (%d)\n", axis)
+		return false
+	}
+	vj.device.SendAbsoluteEvent(absAxis.Axis, absAxis.Denormalize(value))
+	return true
+}
+
+func (vj *virtualJoystick) SetAxis(axis Axis, value float32) {
+	if vj.sendAxis(axis, value) {
+		vj.device.SyncReport()
+	}
+}
+
+func (vj *virtualJoystick) MoveStick(x, y float32) {
+	sentX := vj.sendAxis(AxisX, x)
+	sentY := vj.sendAxis(AxisY, y)
+	if sentX || sentY {
+		vj.device.SyncReport()
+	}
+}
+
+func (vj *virtualJoystick) Twist(value float32) {
+	vj.SetAxis(AxisTwist, value)
+}
+
+func (vj *virtualJoystick) Throttle(value float32) {
+	vj.SetAxis(AxisThrottle, value)
+}
+
+func (vj *virtualJoystick) Rudder(value float32) {
+	vj.SetAxis(AxisRudder, value)
+}
+
+// SupportedAxes returns the logical axes mapped by the joystick, in the order of their constants.
+func (vj *virtualJoystick) SupportedAxes() []Axis {
+	axes := make([]Axis, 0, len(vj.axes))
+	for axis := range vj.axes {
+		axes = append(axes, axis)
+	}
+	sort.Slice(axes, func(i, j int) bool { return axes[i] < axes[j] })
+	return axes
+}
+
+func (vj *virtualJoystick) code(button Button) (linux.Button, bool) {
+	if button < 1 || int(button) > len(vj.buttons) {
+		fmt.Printf("button not assigned (%d)\n", button)
+		return 0, false
+	}
+	return vj.buttons[button-1], true
+}
+
+func (vj *virtualJoystick) Press(button Button) {
+	code, ok := vj.code(button)
+	if !ok {
+		return
+	}
+	vj.device.PressButton(code)
+	vj.device.SyncReport()
+}
+
+func (vj *virtualJoystick) Release(button Button) {
+	code, ok := vj.code(button)
+	if !ok {
+		return
+	}
+	vj.device.ReleaseButton(code)
+	vj.device.SyncReport()
+}
+
+func (vj *virtualJoystick) ButtonCount() int {
+	return len(vj.buttons)
+}
+
+func (vj *virtualJoystick) MoveHat(hat int, x, y int32) {
+	if hat < 0 || hat >= vj.hats {
+		fmt.Printf("hat not assigned (%d)\n", hat)
+		return
+	}
+	xAxis, yAxis := hatAxes(hat)
+	vj.device.SendAbsoluteEvent(xAxis, clampHat(x))
+	vj.device.SendAbsoluteEvent(yAxis, clampHat(y))
+	vj.device.SyncReport()
+}
+
+func clampHat(value int32) int32 {
+	if value < 0 {
+		return -1
+	}
+	if value > 0 {
+		return 1
+	}
+	return 0
+}
+
+func (vj *virtualJoystick) HatCount() int {
+	return vj.hats
+}
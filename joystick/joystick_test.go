@@ -0,0 +1,133 @@
+package joystick
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+func TestButtonCodes(t *testing.T) {
+	codes := ButtonCodes(60)
+	if len(codes) != 56 {
+		t.Fatalf("len = %d, want 56", len(codes))
+	}
+	if codes[0] != linux.BTN_TRIGGER || codes[11] != linux.BTN_BASE6 || codes[15] != linux.BTN_DEAD {
+		t.Errorf("unexpected first codes %v", codes[:16])
+	}
+	if codes[16] != linux.BTN_TRIGGER_HAPPY1 || codes[55] != linux.BTN_TRIGGER_HAPPY40 {
+		t.Errorf("unexpected extra codes %v", codes[16:])
+	}
+}
+
+func TestJoystick_Capabilities(t *testing.T) {
+	mock := vdtest.NewDevice()
+	js := newGenericJoystick(mock)
+
+	if js.ButtonCount() != 32 || len(mock.Buttons) != 32 {
+		t.Errorf("buttons = %d, registered %d", js.ButtonCount(), len(mock.Buttons))
+	}
+	if js.HatCount() != 4 {
+		t.Errorf("HatCount = %d", js.HatCount())
+	}
+	// 5 axes and 4 hats, sorted by code
+	if len(mock.AbsAxes) != 13 || mock.AbsAxes[12].Axis != linux.ABS_HAT3Y {
+		t.Errorf("AbsAxes = %+v", mock.AbsAxes)
+	}
+	axes := js.SupportedAxes()
+	if len(axes) != 5 || axes[0] != AxisX || axes[4] != AxisRudder {
+		t.Errorf("SupportedAxes = %v", axes)
+	}
+}
+
+func TestJoystick_Axes(t *testing.T) {
+	mock := vdtest.NewDevice()
+	js := newGenericJoystick(mock)
+
+	js.MoveStick(-1, 1)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_X, -32768), vdtest.Abs(linux.ABS_Y, 32767))
+	js.Twist(1)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_RZ, 32767))
+	js.Throttle(1)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_THROTTLE, 255))
+	js.Throttle(0)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_THROTTLE, 0))
+	js.Rudder(-1)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_RUDDER, -32768))
+
+	js.SetAxis(AxisSlewX, 1)
+	mock.ExpectNoFrame(t)
+}
+
+func TestJoystick_Buttons(t *testing.T) {
+	mock := vdtest.NewDevice()
+	js := newGenericJoystick(mock)
+
+	js.Press(ButtonTrigger)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_TRIGGER, 1))
+	js.Press(32)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_TRIGGER_HAPPY16, 1))
+	js.Release(32)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_TRIGGER_HAPPY16, 0))
+
+	js.Press(0)
+	js.Press(33)
+	mock.ExpectNoFrame(t)
+}
+
+func TestJoystick_Hats(t *testing.T) {
+	mock := vdtest.NewDevice()
+	js := newGenericJoystick(mock)
+
+	js.MoveHat(0, 1, -1)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_HAT0X, 1), vdtest.Abs(linux.ABS_HAT0Y, -1))
+	js.MoveHat(3, -5, 0)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_HAT3X, -1), vdtest.Abs(linux.ABS_HAT3Y, 0))
+
+	js.MoveHat(4, 1, 0)
+	mock.ExpectNoFrame(t)
+}
+
+func TestHOTAS_Warthog(t *testing.T) {
+	stick, throttle := vdtest.NewDevice(), vdtest.NewDevice()
+	h := newThrustmasterWarthog(stick, throttle)
+
+	if err := h.Register(); err != nil {
+		t.Fatal(err)
+	}
+	if !stick.Registered() || !throttle.Registered() {
+		t.Fatal("both devices should be registered")
+	}
+
+	h.Stick().MoveStick(1, 0)
+	stick.ExpectFrame(t, vdtest.Abs(linux.ABS_X, 65535), vdtest.Abs(linux.ABS_Y, 32767))
+	h.Throttle().Throttle(1)
+	h.Throttle().SetAxis(AxisThrottle2, 0.5)
+	throttle.ExpectFrame(t, vdtest.Abs(linux.ABS_Z, 16383))
+	throttle.ExpectFrame(t, vdtest.Abs(linux.ABS_RZ, 8191))
+	if h.Throttle().ButtonCount() != 32 || h.Stick().ButtonCount() != 19 {
+		t.Errorf("buttons = %d and %d", h.Stick().ButtonCount(), h.Throttle().ButtonCount())
+	}
+
+	if err := h.Unregister(); err != nil {
+		t.Fatal(err)
+	}
+	if stick.Registered() || throttle.Registered() {
+		t.Fatal("both devices should be unregistered")
+	}
+}
+
+func TestHOTAS_RegisterRollback(t *testing.T) {
+	stick, throttle := vdtest.NewDevice(), vdtest.NewDevice()
+	h := newThrustmasterWarthog(stick, throttle)
+
+	failure := errors.New("no uinput")
+	throttle.FailRegister(failure)
+	if err := h.Register(); !errors.Is(err, failure) {
+		t.Fatalf("Register = %v, want %v", err, failure)
+	}
+	if stick.Registered() {
+		t.Error("stick left registered")
+	}
+}
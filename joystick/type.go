@@ -0,0 +1,27 @@
+package joystick
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// MappingAxes maps the logical axes to their absolute axes.
+// An axis flagged IsUnidirectional (e.g. a throttle) is driven from 0 to 1, the others from -1 to 1.
+type MappingAxes map[Axis]virtual_device.AbsAxis
+
+// ButtonCodes returns the codes hid-input gives to the first count buttons of a joystick:
+// BTN_TRIGGER to BTN_DEAD, then BTN_TRIGGER_HAPPY1 to BTN_TRIGGER_HAPPY40, 56 buttons at most.
+func ButtonCodes(count int) []linux.Button {
+	codes := make([]linux.Button, 0, count)
+	for i := 0; i < count; i++ {
+		code := linux.BTN_JOYSTICK + linux.Button(i)
+		if code > linux.BTN_DEAD {
+			code = linux.BTN_TRIGGER_HAPPY1 + code - linux.BTN_DEAD - 1
+		}
+		if code > linux.BTN_TRIGGER_HAPPY40 {
+			break
+		}
+		codes = append(codes, code)
+	}
+	return codes
+}
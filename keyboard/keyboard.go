@@ -1,10 +1,14 @@
 package keyboard
 
 import (
+	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/action"
+	"github.com/jbdemonte/virtual-device/clock"
 	"github.com/jbdemonte/virtual-device/linux"
 )
 
@@ -12,11 +16,14 @@ import (
 type VirtualKeyboard interface {
 	Register() error
 	Type(content string)
+	TypeContext(ctx context.Context, content string) error
+	TypeAsync(ctx context.Context, content string) action.Handle
 	Unregister() error
 
 	PressKey(key linux.Key)
 	ReleaseKey(key linux.Key)
 	TapKey(key linux.Key)
+	TapKeyContext(ctx context.Context, key linux.Key) error
 	SetLed(led linux.Led, state bool)
 	SendMiscEvent(event linux.MiscEvent, value int32)
 	SyncReport()
@@ -24,6 +31,7 @@ type VirtualKeyboard interface {
 	Send(evType, code uint16, value int32)
 
 	EventPath() string
+	DeviceInfo() (virtual_device.DeviceInfo, error)
 }
 
 // VirtualKeyboardFactory configures and creates VirtualKeyboard instances.
@@ -35,6 +43,7 @@ type VirtualKeyboardFactory interface {
 	WithMiscEvents(events []linux.MiscEvent) VirtualKeyboardFactory
 	WithRepeat(delay, period int32) VirtualKeyboardFactory
 	WithKeyMap(keymap KeyMap) VirtualKeyboardFactory
+	WithClock(clock clock.Clock) VirtualKeyboardFactory
 	Create() VirtualKeyboard
 }
 
@@ -53,6 +62,7 @@ type virtualKeyboardFactory struct {
 	repeat      *Repeat
 	keymap      KeyMap
 	tapDuration time.Duration
+	clock       clock.Clock
 }
 
 func (f *virtualKeyboardFactory) WithDevice(device virtual_device.VirtualDevice) VirtualKeyboardFactory {
@@ -90,16 +100,27 @@ func (f *virtualKeyboardFactory) WithKeyMap(keymap KeyMap) VirtualKeyboardFactor
 	return f
 }
 
+func (f *virtualKeyboardFactory) WithClock(clock clock.Clock) VirtualKeyboardFactory {
+	f.clock = clock
+	return f
+}
+
 func (f *virtualKeyboardFactory) Create() VirtualKeyboard {
 	tapDuration := f.tapDuration
 	if tapDuration < 0 {
 		tapDuration = 20 * time.Millisecond
 	}
 
+	c := f.clock
+	if c == nil {
+		c = clock.New()
+	}
+
 	vk := &virtualKeyboard{
 		device:      f.device,
 		keymap:      f.keymap,
 		tapDuration: tapDuration,
+		clock:       c,
 	}
 	if f.repeat != nil {
 		vk.device.WithRepeat(f.repeat.delay, f.repeat.period)
@@ -115,7 +136,9 @@ func (f *virtualKeyboardFactory) Create() VirtualKeyboard {
 type virtualKeyboard struct {
 	device      virtual_device.VirtualDevice
 	keymap      KeyMap
+	keymapOnce  sync.Once // the layout is detected once, on the first Type, even when typed concurrently
 	tapDuration time.Duration
+	clock       clock.Clock
 }
 
 func (vk *virtualKeyboard) Register() error {
@@ -135,11 +158,20 @@ func (vk *virtualKeyboard) ReleaseKey(key linux.Key) {
 }
 
 func (vk *virtualKeyboard) TapKey(key linux.Key) {
+	_ = vk.TapKeyContext(context.Background(), key)
+}
+
+// TapKeyContext taps a key, releasing it right away if ctx is done while it is held.
+func (vk *virtualKeyboard) TapKeyContext(ctx context.Context, key linux.Key) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	vk.device.PressKey(key)
 	vk.device.SyncReport()
-	time.Sleep(vk.tapDuration)
+	err := clock.SleepContext(ctx, vk.clock, vk.tapDuration)
 	vk.device.ReleaseKey(key)
 	vk.device.SyncReport()
+	return err
 }
 
 func (vk *virtualKeyboard) SetLed(led linux.Led, state bool) {
@@ -155,10 +187,32 @@ func (vk *virtualKeyboard) SyncReport() {
 }
 
 func (vk *virtualKeyboard) Type(content string) {
-	if vk.keymap == nil {
-		vk.keymap = getKeymap()
-	}
+	_ = vk.typeContext(context.Background(), content, func() {})
+}
+
+// TypeContext types content, stopping between two characters or while a key is held when ctx is done.
+// The keys and modifiers pressed at that time are released before it returns ctx.Err().
+func (vk *virtualKeyboard) TypeContext(ctx context.Context, content string) error {
+	return vk.typeContext(ctx, content, func() {})
+}
+
+// TypeAsync types content in the background, one progress step per character.
+func (vk *virtualKeyboard) TypeAsync(ctx context.Context, content string) action.Handle {
+	return action.Start(ctx, len([]rune(content)), func(ctx context.Context, step func()) error {
+		return vk.typeContext(ctx, content, step)
+	})
+}
+
+func (vk *virtualKeyboard) typeContext(ctx context.Context, content string, step func()) error {
+	vk.keymapOnce.Do(func() {
+		if vk.keymap == nil {
+			vk.keymap = getKeymap()
+		}
+	})
 	for _, char := range content {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if mapping, ok := vk.keymap[char]; ok {
 			if mapping.shiftRequired {
 				vk.device.PressKey(linux.KEY_LEFTSHIFT)
@@ -171,7 +225,7 @@ func (vk *virtualKeyboard) Type(content string) {
 
 			vk.device.SyncReport()
 
-			time.Sleep(vk.tapDuration)
+			err := clock.SleepContext(ctx, vk.clock, vk.tapDuration)
 
 			vk.device.ReleaseKey(mapping.keyCode)
 
@@ -184,11 +238,21 @@ func (vk *virtualKeyboard) Type(content string) {
 
 			vk.device.SyncReport()
 
-			time.Sleep(vk.tapDuration)
+			if err != nil {
+				return err
+			}
+
+			step()
+
+			if err := clock.SleepContext(ctx, vk.clock, vk.tapDuration); err != nil {
+				return err
+			}
 		} else {
 			fmt.Printf("Warning: Character '%c' is not mapped\n", char)
+			step()
 		}
 	}
+	return nil
 }
 
 func (vk *virtualKeyboard) Send(evType, code uint16, value int32) {
@@ -198,3 +262,7 @@ func (vk *virtualKeyboard) Send(evType, code uint16, value int32) {
 func (vk *virtualKeyboard) EventPath() string {
 	return vk.device.EventPath()
 }
+
+func (vk *virtualKeyboard) DeviceInfo() (virtual_device.DeviceInfo, error) {
+	return vk.device.DeviceInfo()
+}
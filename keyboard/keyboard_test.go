@@ -1,13 +1,17 @@
 package keyboard
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
-	"github.com/jbdemonte/virtual-device/internal/testutil"
+	"github.com/jbdemonte/virtual-device/clock"
 	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
 )
 
-func newTestKeyboard(mock *testutil.MockDevice, keymap KeyMap) VirtualKeyboard {
+func newTestKeyboard(mock *vdtest.Device, keymap KeyMap) VirtualKeyboard {
 	return NewVirtualKeyboardFactory().
 		WithDevice(mock).
 		WithTapDuration(0).
@@ -16,7 +20,7 @@ func newTestKeyboard(mock *testutil.MockDevice, keymap KeyMap) VirtualKeyboard {
 }
 
 func TestKeyboard_TypePlainChar(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	kb := newTestKeyboard(mock, qwertyKeyMap)
 
 	kb.Type("a")
@@ -35,7 +39,7 @@ func TestKeyboard_TypePlainChar(t *testing.T) {
 }
 
 func TestKeyboard_TypeShiftedChar(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	kb := newTestKeyboard(mock, qwertyKeyMap)
 
 	kb.Type("A")
@@ -60,7 +64,7 @@ func TestKeyboard_TypeShiftedChar(t *testing.T) {
 }
 
 func TestKeyboard_TypeAltGrChar(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	// Use azerty keymap where '[' requires AltGr
 	kb := newTestKeyboard(mock, azertyKeyMap)
 
@@ -77,7 +81,7 @@ func TestKeyboard_TypeAltGrChar(t *testing.T) {
 }
 
 func TestKeyboard_TypeUnmappedChar(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	// Use a minimal keymap with no mappings
 	kb := newTestKeyboard(mock, KeyMap{})
 
@@ -90,7 +94,7 @@ func TestKeyboard_TypeUnmappedChar(t *testing.T) {
 }
 
 func TestKeyboard_TypeMultipleChars(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	kb := newTestKeyboard(mock, qwertyKeyMap)
 
 	kb.Type("ab")
@@ -103,7 +107,7 @@ func TestKeyboard_TypeMultipleChars(t *testing.T) {
 }
 
 func TestKeyboard_TapKey(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	kb := newTestKeyboard(mock, qwertyKeyMap)
 
 	kb.TapKey(linux.KEY_ENTER)
@@ -120,3 +124,102 @@ func TestKeyboard_TapKey(t *testing.T) {
 		t.Errorf("event[2] = %+v, want KEY_ENTER release", events[2])
 	}
 }
+
+func TestKeyboard_Type_FakeClock(t *testing.T) {
+	start := time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	mock := vdtest.NewDevice().SetClock(fake)
+	kb := NewVirtualKeyboardFactory().
+		WithDevice(mock).
+		WithKeyMap(qwertyKeyMap).
+		WithClock(fake).
+		Create()
+
+	done := make(chan struct{})
+	go func() {
+		kb.Type("ab")
+		close(done)
+	}()
+
+	// each char: press, tap duration, release, tap duration
+	for i := 0; i < 4; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(20 * time.Millisecond)
+	}
+	<-done
+
+	mock.ExpectKeyTap(t, linux.KEY_A)
+	mock.ExpectKeyTap(t, linux.KEY_B)
+
+	events := mock.Events()
+	last := events[len(events)-1]
+	if got := last.Time.Sub(start); got != 60*time.Millisecond {
+		t.Errorf("last event at %v, want 60ms", got)
+	}
+}
+
+func TestKeyboard_TypeContext_CancelReleasesModifiers(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	mock := vdtest.NewDevice()
+	kb := NewVirtualKeyboardFactory().
+		WithDevice(mock).
+		WithKeyMap(qwertyKeyMap).
+		WithClock(fake).
+		Create()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- kb.TypeContext(ctx, "Hello")
+	}()
+
+	// cancel while the shifted H is held
+	fake.BlockUntil(1)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("TypeContext = %v, want context.Canceled", err)
+	}
+
+	state := mock.State()
+	if state.IsKeyPressed(linux.KEY_H) || state.IsKeyPressed(linux.KEY_LEFTSHIFT) {
+		t.Error("keys must be released on cancel")
+	}
+	if mock.State().IsKeyPressed(linux.KEY_E) {
+		t.Error("typing must stop on cancel")
+	}
+	for _, e := range mock.Events() {
+		if e.Code == uint16(linux.KEY_E) {
+			t.Fatal("KEY_E must not be sent after cancel")
+		}
+	}
+}
+
+func TestKeyboard_TypeAsync_Progress(t *testing.T) {
+	mock := vdtest.NewDevice()
+	kb := newTestKeyboard(mock, qwertyKeyMap)
+
+	h := kb.TypeAsync(context.Background(), "abc")
+	if err := h.Wait(); err != nil {
+		t.Fatalf("Wait = %v, want nil", err)
+	}
+	if done, total := h.Progress(); done != 3 || total != 3 {
+		t.Errorf("Progress = %d/%d, want 3/3", done, total)
+	}
+	mock.ExpectKeyTap(t, linux.KEY_A)
+	mock.ExpectKeyTap(t, linux.KEY_B)
+	mock.ExpectKeyTap(t, linux.KEY_C)
+}
+
+func TestKeyboard_TypeAsync_ConcurrentKeymap(t *testing.T) {
+	mock := vdtest.NewDevice()
+	kb := newTestKeyboard(mock, nil) // the layout is detected by the first Type, run with -race
+
+	first := kb.TypeAsync(context.Background(), "a")
+	second := kb.TypeAsync(context.Background(), "b")
+	if err := errors.Join(first.Wait(), second.Wait()); err != nil {
+		t.Fatalf("Wait = %v, want nil", err)
+	}
+	if len(mock.Events()) != 8 {
+		t.Errorf("got %d events, want 2 taps", len(mock.Events()))
+	}
+}
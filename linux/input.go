@@ -309,14 +309,58 @@ type FFRumbleEffect struct {
 
 // struct ff_effect
 // In C, the union U overlaps all members in the same memory.
-// We emulate this with a byte array sized to the largest member ([2]FFConditionEffect = 24 bytes).
+// We emulate this with a byte array sized to the largest member, ff_periodic_effect and its custom_data pointer,
+// the union being aligned on that pointer.
 type FFEffect struct {
 	Type      uint16
 	ID        int16
 	Direction uint16
 	Trigger   FFTrigger
 	Replay    FFReplay
-	U         [24]byte
+	_         uint16
+	U         [24 + unsafe.Sizeof(uintptr(0))]byte
+}
+
+func (e *FFEffect) Constant() FFConstantEffect {
+	return *(*FFConstantEffect)(unsafe.Pointer(&e.U[0]))
+}
+
+func (e *FFEffect) SetConstant(effect FFConstantEffect) {
+	*(*FFConstantEffect)(unsafe.Pointer(&e.U[0])) = effect
+}
+
+func (e *FFEffect) Ramp() FFRampEffect {
+	return *(*FFRampEffect)(unsafe.Pointer(&e.U[0]))
+}
+
+func (e *FFEffect) SetRamp(effect FFRampEffect) {
+	*(*FFRampEffect)(unsafe.Pointer(&e.U[0])) = effect
+}
+
+// Condition returns the parameters of the X and Y axes of a spring, friction, damper or inertia effect.
+func (e *FFEffect) Condition() [2]FFConditionEffect {
+	return *(*[2]FFConditionEffect)(unsafe.Pointer(&e.U[0]))
+}
+
+func (e *FFEffect) SetCondition(effect [2]FFConditionEffect) {
+	*(*[2]FFConditionEffect)(unsafe.Pointer(&e.U[0])) = effect
+}
+
+// Periodic returns the periodic effect, CustomData pointing to the memory of the application when read from uinput.
+func (e *FFEffect) Periodic() FFPeriodicEffect {
+	return *(*FFPeriodicEffect)(unsafe.Pointer(&e.U[0]))
+}
+
+func (e *FFEffect) SetPeriodic(effect FFPeriodicEffect) {
+	*(*FFPeriodicEffect)(unsafe.Pointer(&e.U[0])) = effect
+}
+
+func (e *FFEffect) Rumble() FFRumbleEffect {
+	return *(*FFRumbleEffect)(unsafe.Pointer(&e.U[0]))
+}
+
+func (e *FFEffect) SetRumble(effect FFRumbleEffect) {
+	*(*FFRumbleEffect)(unsafe.Pointer(&e.U[0])) = effect
 }
 
 // FFEffectType identifies the type of a force feedback effect.
@@ -58,3 +58,54 @@ func TestIOC_Direction(t *testing.T) {
 		t.Errorf("expected direction bits %d, got %d", _IOC_READ, dirBits)
 	}
 }
+
+func TestUI_SET_PHYS(t *testing.T) {
+	// UI_SET_PHYS = _IOW('U', 108, char*), the size being the one of a pointer
+	var p *byte
+	want := _IOW('U', 108, p)
+	if uintptr(UI_SET_PHYS) != want {
+		t.Errorf("UI_SET_PHYS = 0x%x, want 0x%x", UI_SET_PHYS, want)
+	}
+}
+
+func TestUI_FF_Requests(t *testing.T) {
+	if unsafe.Sizeof(uintptr(0)) != 8 {
+		t.Skip("the expected values are the ones of 64-bit platforms")
+	}
+	// sizeof(struct ff_effect) = 48, sizeof(struct uinput_ff_upload) = 104, sizeof(struct uinput_ff_erase) = 12
+	if size := unsafe.Sizeof(FFEffect{}); size != 48 {
+		t.Errorf("sizeof(FFEffect) = %d, want 48", size)
+	}
+	if offset := unsafe.Offsetof(FFEffect{}.U); offset != 16 {
+		t.Errorf("offsetof(FFEffect.U) = %d, want 16", offset)
+	}
+	tests := []struct {
+		name      string
+		got, want uintptr
+	}{
+		{"UI_BEGIN_FF_UPLOAD", UI_BEGIN_FF_UPLOAD, 0xc06855c8},
+		{"UI_END_FF_UPLOAD", UI_END_FF_UPLOAD, 0x406855c9},
+		{"UI_BEGIN_FF_ERASE", UI_BEGIN_FF_ERASE, 0xc00c55ca},
+		{"UI_END_FF_ERASE", UI_END_FF_ERASE, 0x400c55cb},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = 0x%x, want 0x%x", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestFFEffect_Union(t *testing.T) {
+	var effect FFEffect
+	effect.SetCondition([2]FFConditionEffect{{RightCoeff: 100, LeftCoeff: -100, Center: 5}, {Deadband: 7}})
+	if got := effect.Condition(); got[0].RightCoeff != 100 || got[0].LeftCoeff != -100 || got[0].Center != 5 || got[1].Deadband != 7 {
+		t.Errorf("Condition() = %+v", got)
+	}
+	effect.SetConstant(FFConstantEffect{Level: -1234, Envelope: FFEnvelope{AttackLength: 10}})
+	if got := effect.Constant(); got.Level != -1234 || got.Envelope.AttackLength != 10 {
+		t.Errorf("Constant() = %+v", got)
+	}
+	if got := effect.Rumble(); got.StrongMagnitude != uint16(0xffff&-1234) {
+		t.Errorf("the union members must overlap, Rumble() = %+v", got)
+	}
+}
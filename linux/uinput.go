@@ -1,5 +1,7 @@
 package linux
 
+import "unsafe"
+
 // FROM https://github.com/torvalds/linux/blob/master/include/uapi/linux/uinput.h
 
 const (
@@ -22,11 +24,40 @@ const (
 	UI_SET_LEDBIT  = UINPUT_IOCTL_BASE_NUMERIC + 105
 	UI_SET_SNDBIT  = UINPUT_IOCTL_BASE_NUMERIC + 106
 	UI_SET_FFBIT   = UINPUT_IOCTL_BASE_NUMERIC + 107
-	UI_SET_PHYS    = UINPUT_IOCTL_BASE_NUMERIC + 108
+	UI_SET_PHYS    = _IOC_WRITE<<_IOC_DIRSHIFT | UINPUT_IOCTL_BASE<<_IOC_TYPESHIFT | 108<<_IOC_NRSHIFT | unsafe.Sizeof(uintptr(0))<<_IOC_SIZESHIFT // _IOW(UINPUT_IOCTL_BASE, 108, char*)
 	UI_SET_SWBIT   = UINPUT_IOCTL_BASE_NUMERIC + 109
 	UI_SET_PROPBIT = UINPUT_IOCTL_BASE_NUMERIC + 110
 )
 
+// Requests of the kernel, read from the uinput file with the EV_UINPUT type.
+const (
+	EV_UINPUT    EventType = 0x0101
+	UI_FF_UPLOAD           = 1
+	UI_FF_ERASE            = 2
+)
+
+// UInputFFUpload is the uinput_ff_upload struct, exchanged to answer an UI_FF_UPLOAD request.
+type UInputFFUpload struct {
+	RequestID uint32
+	Retval    int32
+	Effect    FFEffect
+	Old       FFEffect
+}
+
+// UInputFFErase is the uinput_ff_erase struct, exchanged to answer an UI_FF_ERASE request.
+type UInputFFErase struct {
+	RequestID uint32
+	Retval    int32
+	EffectID  uint32
+}
+
+var (
+	UI_BEGIN_FF_UPLOAD = _IOWR(UINPUT_IOCTL_BASE, 200, UInputFFUpload{})
+	UI_END_FF_UPLOAD   = _IOW(UINPUT_IOCTL_BASE, 201, UInputFFUpload{})
+	UI_BEGIN_FF_ERASE  = _IOWR(UINPUT_IOCTL_BASE, 202, UInputFFErase{})
+	UI_END_FF_ERASE    = _IOW(UINPUT_IOCTL_BASE, 203, UInputFFErase{})
+)
+
 const UINPUT_MAX_NAME_SIZE = 80
 
 // UInputUserDev is the uinput_user_dev struct from linux/uinput.h.
@@ -1,9 +1,12 @@
 package mouse
 
 import (
+	"context"
 	"time"
 
 	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/action"
+	"github.com/jbdemonte/virtual-device/clock"
 	"github.com/jbdemonte/virtual-device/linux"
 )
 
@@ -30,6 +33,11 @@ type VirtualMouse interface {
 	Click(btn linux.Button)
 	DoubleClick(btn linux.Button)
 
+	ClickContext(ctx context.Context, btn linux.Button) error
+	DoubleClickContext(ctx context.Context, btn linux.Button) error
+	ClickAsync(ctx context.Context, btn linux.Button) action.Handle
+	DoubleClickAsync(ctx context.Context, btn linux.Button) action.Handle
+
 	ClickLeft()
 	ClickRight()
 	ClickMiddle()
@@ -41,6 +49,7 @@ type VirtualMouse interface {
 	Send(evType, code uint16, value int32)
 
 	EventPath() string
+	DeviceInfo() (virtual_device.DeviceInfo, error)
 }
 
 // VirtualMouseFactory configures and creates VirtualMouse instances.
@@ -50,6 +59,7 @@ type VirtualMouseFactory interface {
 	WithDoubleClickDelay(delay int) VirtualMouseFactory
 	WithHighResStepVertical(step int32) VirtualMouseFactory
 	WithHighResStepHorizontal(step int32) VirtualMouseFactory
+	WithClock(clock clock.Clock) VirtualMouseFactory
 	Create() VirtualMouse
 }
 
@@ -67,6 +77,7 @@ type virtualMouseFactory struct {
 	highResStepHorizontal int32
 	clickDelay            int
 	doubleClickDelay      int
+	clock                 clock.Clock
 }
 
 func (f *virtualMouseFactory) WithDevice(device virtual_device.VirtualDevice) VirtualMouseFactory {
@@ -94,6 +105,11 @@ func (f *virtualMouseFactory) WithHighResStepHorizontal(step int32) VirtualMouse
 	return f
 }
 
+func (f *virtualMouseFactory) WithClock(clock clock.Clock) VirtualMouseFactory {
+	f.clock = clock
+	return f
+}
+
 func (f *virtualMouseFactory) Create() VirtualMouse {
 	clickDelay := f.clickDelay
 	if clickDelay < 0 {
@@ -104,12 +120,19 @@ func (f *virtualMouseFactory) Create() VirtualMouse {
 	if doubleClickDelay < 0 {
 		doubleClickDelay = 250
 	}
+
+	c := f.clock
+	if c == nil {
+		c = clock.New()
+	}
+
 	return &virtualMouse{
 		device:                f.device,
 		clickDelay:            clickDelay,
 		doubleClickDelay:      doubleClickDelay,
 		highResStepVertical:   f.highResStepVertical,
 		highResStepHorizontal: f.highResStepHorizontal,
+		clock:                 c,
 	}
 }
 
@@ -119,6 +142,7 @@ type virtualMouse struct {
 	highResStepHorizontal int32
 	clickDelay            int
 	doubleClickDelay      int
+	clock                 clock.Clock
 }
 
 func (vm *virtualMouse) Register() error {
@@ -188,15 +212,60 @@ func (vm *virtualMouse) ScrollRight() {
 }
 
 func (vm *virtualMouse) Click(btn linux.Button) {
+	_ = vm.ClickContext(context.Background(), btn)
+}
+
+func (vm *virtualMouse) DoubleClick(btn linux.Button) {
+	_ = vm.DoubleClickContext(context.Background(), btn)
+}
+
+// ClickContext clicks a button, releasing it right away if ctx is done while it is held.
+func (vm *virtualMouse) ClickContext(ctx context.Context, btn linux.Button) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	vm.ButtonPress(btn)
-	time.Sleep(time.Millisecond * time.Duration(vm.clickDelay))
+	err := clock.SleepContext(ctx, vm.clock, time.Millisecond*time.Duration(vm.clickDelay))
 	vm.ButtonRelease(btn)
+	return err
 }
 
-func (vm *virtualMouse) DoubleClick(btn linux.Button) {
-	vm.Click(btn)
-	time.Sleep(time.Millisecond * time.Duration(vm.doubleClickDelay))
-	vm.Click(btn)
+// DoubleClickContext double-clicks a button, stopping as soon as ctx is done.
+func (vm *virtualMouse) DoubleClickContext(ctx context.Context, btn linux.Button) error {
+	return vm.doubleClick(ctx, btn, func() {})
+}
+
+// ClickAsync clicks a button in the background.
+func (vm *virtualMouse) ClickAsync(ctx context.Context, btn linux.Button) action.Handle {
+	return action.Start(ctx, 1, func(ctx context.Context, step func()) error {
+		if err := vm.ClickContext(ctx, btn); err != nil {
+			return err
+		}
+		step()
+		return nil
+	})
+}
+
+// DoubleClickAsync double-clicks a button in the background, one progress step per click.
+func (vm *virtualMouse) DoubleClickAsync(ctx context.Context, btn linux.Button) action.Handle {
+	return action.Start(ctx, 2, func(ctx context.Context, step func()) error {
+		return vm.doubleClick(ctx, btn, step)
+	})
+}
+
+func (vm *virtualMouse) doubleClick(ctx context.Context, btn linux.Button, step func()) error {
+	if err := vm.ClickContext(ctx, btn); err != nil {
+		return err
+	}
+	step()
+	if err := clock.SleepContext(ctx, vm.clock, time.Millisecond*time.Duration(vm.doubleClickDelay)); err != nil {
+		return err
+	}
+	if err := vm.ClickContext(ctx, btn); err != nil {
+		return err
+	}
+	step()
+	return nil
 }
 
 func (vm *virtualMouse) ClickLeft() {
@@ -230,3 +299,7 @@ func (vm *virtualMouse) Send(evType, code uint16, value int32) {
 func (vm *virtualMouse) EventPath() string {
 	return vm.device.EventPath()
 }
+
+func (vm *virtualMouse) DeviceInfo() (virtual_device.DeviceInfo, error) {
+	return vm.device.DeviceInfo()
+}
@@ -1,13 +1,17 @@
 package mouse
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
-	"github.com/jbdemonte/virtual-device/internal/testutil"
+	"github.com/jbdemonte/virtual-device/clock"
 	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
 )
 
-func newTestMouse(mock *testutil.MockDevice) VirtualMouse {
+func newTestMouse(mock *vdtest.Device) VirtualMouse {
 	return NewVirtualMouseFactory().
 		WithDevice(mock).
 		WithClickDelay(0).
@@ -15,7 +19,7 @@ func newTestMouse(mock *testutil.MockDevice) VirtualMouse {
 		Create()
 }
 
-func newTestMouseWithHighRes(mock *testutil.MockDevice, vStep int32) VirtualMouse {
+func newTestMouseWithHighRes(mock *vdtest.Device, vStep int32) VirtualMouse {
 	return NewVirtualMouseFactory().
 		WithDevice(mock).
 		WithClickDelay(0).
@@ -25,7 +29,7 @@ func newTestMouseWithHighRes(mock *testutil.MockDevice, vStep int32) VirtualMous
 }
 
 func TestMouse_Move(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	m := newTestMouse(mock)
 
 	m.Move(10, -5)
@@ -44,7 +48,7 @@ func TestMouse_Move(t *testing.T) {
 }
 
 func TestMouse_ScrollVertical_NoHighRes(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	m := newTestMouse(mock)
 
 	m.ScrollVertical(1)
@@ -60,7 +64,7 @@ func TestMouse_ScrollVertical_NoHighRes(t *testing.T) {
 }
 
 func TestMouse_ScrollVertical_WithHighRes(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	m := newTestMouseWithHighRes(mock, 120)
 
 	m.ScrollVertical(1)
@@ -76,7 +80,7 @@ func TestMouse_ScrollVertical_WithHighRes(t *testing.T) {
 }
 
 func TestMouse_ButtonPress(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	m := newTestMouse(mock)
 
 	m.ButtonPress(linux.BTN_LEFT)
@@ -92,7 +96,7 @@ func TestMouse_ButtonPress(t *testing.T) {
 }
 
 func TestMouse_Click(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	m := newTestMouse(mock)
 
 	m.Click(linux.BTN_LEFT)
@@ -111,7 +115,7 @@ func TestMouse_Click(t *testing.T) {
 }
 
 func TestMouse_DoubleClick(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	m := newTestMouse(mock)
 
 	m.DoubleClick(linux.BTN_LEFT)
@@ -123,8 +127,59 @@ func TestMouse_DoubleClick(t *testing.T) {
 	}
 }
 
+func TestMouse_DoubleClick_FakeClock(t *testing.T) {
+	start := time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	mock := vdtest.NewDevice().SetClock(fake)
+	m := NewVirtualMouseFactory().WithDevice(mock).WithClock(fake).Create()
+
+	done := make(chan struct{})
+	go func() {
+		m.DoubleClick(linux.BTN_LEFT)
+		close(done)
+	}()
+
+	for _, d := range []time.Duration{50 * time.Millisecond, 250 * time.Millisecond, 50 * time.Millisecond} {
+		fake.BlockUntil(1)
+		fake.Advance(d)
+	}
+	<-done
+
+	events := mock.Events()
+	if len(events) != 8 {
+		t.Fatalf("expected 8 events, got %d", len(events))
+	}
+	// press, release (+50ms), press (+250ms), release (+50ms)
+	wantOffsets := []time.Duration{0, 50, 300, 350}
+	for i, offset := range wantOffsets {
+		got := events[i*2].Time.Sub(start)
+		if got != offset*time.Millisecond {
+			t.Errorf("event[%d] at %v, want %v", i*2, got, offset*time.Millisecond)
+		}
+	}
+}
+
+func TestMouse_ClickAsync_CancelReleasesButton(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	mock := vdtest.NewDevice()
+	m := NewVirtualMouseFactory().WithDevice(mock).WithClock(fake).Create()
+
+	h := m.DoubleClickAsync(context.Background(), linux.BTN_LEFT)
+	fake.BlockUntil(1)
+	h.Cancel()
+	if err := h.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait = %v, want context.Canceled", err)
+	}
+	if done, total := h.Progress(); done != 0 || total != 2 {
+		t.Errorf("Progress = %d/%d, want 0/2", done, total)
+	}
+
+	mock.ExpectButtonTap(t, linux.BTN_LEFT)
+	mock.ExpectNoFrame(t)
+}
+
 func TestMouse_MoveX(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	m := newTestMouse(mock)
 
 	m.MoveX(42)
@@ -139,7 +194,7 @@ func TestMouse_MoveX(t *testing.T) {
 }
 
 func TestMouse_ScrollDown(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	m := newTestMouse(mock)
 
 	m.ScrollDown()
@@ -0,0 +1,67 @@
+//go:build integration
+
+package remapper
+
+import (
+	"testing"
+	"time"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/evdev"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/utils"
+)
+
+func TestIntegration_RemapKey(t *testing.T) {
+	physical := virtual_device.NewVirtualDevice().
+		WithName("test-remapper-source").
+		WithKeys([]linux.Key{linux.KEY_A})
+
+	if err := physical.Register(); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	defer physical.Unregister()
+
+	if err := utils.WaitForEventFile(physical.EventPath(), 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRemapperFactory().
+		WithSourcePath(physical.EventPath()).
+		WithName("test-remapper-output").
+		WithTransforms(RemapKey(linux.KEY_A, linux.KEY_B)).
+		Create()
+
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer r.Stop()
+
+	if err := utils.WaitForEventFile(r.EventPath(), 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := evdev.Open(r.EventPath())
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer output.Close()
+
+	if output.Name() != "test-remapper-output" {
+		t.Errorf("Name = %q, want test-remapper-output", output.Name())
+	}
+	if !output.Capabilities().HasKey(linux.KEY_B) {
+		t.Error("KEY_B not declared by the remapped device")
+	}
+
+	physical.PressKey(linux.KEY_A)
+	physical.SyncReport()
+
+	events, err := output.ReadEvents()
+	if err != nil {
+		t.Fatalf("ReadEvents: %v", err)
+	}
+	if events[0].Type != uint16(linux.EV_KEY) || events[0].Code != uint16(linux.KEY_B) || events[0].Value != 1 {
+		t.Errorf("event[0] = %+v, want KEY_B press", events[0])
+	}
+}
@@ -0,0 +1,223 @@
+package remapper
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/evdev"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// Remapper grabs a physical evdev device and re-emits its events, transformed, through a virtual device.
+type Remapper interface {
+	Start() error
+	Stop() error
+
+	// Source returns the grabbed device, nil before Start.
+	Source() evdev.InputDevice
+	// Device returns the virtual device, nil before Start.
+	Device() virtual_device.VirtualDevice
+
+	EventPath() string
+}
+
+// RemapperFactory configures and creates Remapper instances.
+type RemapperFactory interface {
+	WithSourcePath(path string) RemapperFactory
+	WithSource(source evdev.InputDevice) RemapperFactory
+	WithDevice(device virtual_device.VirtualDevice) RemapperFactory
+	WithName(name string) RemapperFactory
+	WithTransforms(transforms ...Transform) RemapperFactory
+	WithoutGrab() RemapperFactory
+	Create() Remapper
+}
+
+// NewRemapperFactory returns a new factory for building remappers.
+func NewRemapperFactory() RemapperFactory {
+	return &remapperFactory{grab: true}
+}
+
+type remapperFactory struct {
+	sourcePath string
+	source     evdev.InputDevice
+	device     virtual_device.VirtualDevice
+	name       string
+	transforms []Transform
+	grab       bool
+}
+
+func (f *remapperFactory) WithSourcePath(path string) RemapperFactory {
+	f.sourcePath = path
+	return f
+}
+
+func (f *remapperFactory) WithSource(source evdev.InputDevice) RemapperFactory {
+	f.source = source
+	return f
+}
+
+func (f *remapperFactory) WithDevice(device virtual_device.VirtualDevice) RemapperFactory {
+	f.device = device
+	return f
+}
+
+func (f *remapperFactory) WithName(name string) RemapperFactory {
+	f.name = name
+	return f
+}
+
+func (f *remapperFactory) WithTransforms(transforms ...Transform) RemapperFactory {
+	f.transforms = append(f.transforms, transforms...)
+	return f
+}
+
+func (f *remapperFactory) WithoutGrab() RemapperFactory {
+	f.grab = false
+	return f
+}
+
+func (f *remapperFactory) Create() Remapper {
+	return &remapper{
+		sourcePath: f.sourcePath,
+		source:     f.source,
+		device:     f.device,
+		name:       f.name,
+		pipeline:   Chain(f.transforms...),
+		grab:       f.grab,
+	}
+}
+
+type remapper struct {
+	sourcePath string
+	source     evdev.InputDevice
+	device     virtual_device.VirtualDevice
+	name       string
+	pipeline   Transform
+	grab       bool
+	closed     bool // an injected source closed by Stop, which can not be read again
+
+	mu      sync.Mutex
+	running bool
+	done    chan struct{}
+}
+
+func (r *remapper) Source() evdev.InputDevice {
+	return r.source
+}
+
+func (r *remapper) Device() virtual_device.VirtualDevice {
+	return r.device
+}
+
+func (r *remapper) EventPath() string {
+	if r.device == nil {
+		return ""
+	}
+	return r.device.EventPath()
+}
+
+func (r *remapper) Start() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		return nil
+	}
+
+	if r.closed {
+		return fmt.Errorf("%s was closed by Stop, the remapper needs a fresh source", r.source.Path())
+	}
+
+	if r.source == nil {
+		if r.sourcePath == "" {
+			return errors.New("no source device configured")
+		}
+		source, err := evdev.Open(r.sourcePath)
+		if err != nil {
+			return err
+		}
+		r.source = source
+	}
+
+	spec := NewSpec(r.source)
+	if r.name != "" {
+		spec.Name = r.name
+	}
+	if extender, ok := r.pipeline.(Extender); ok {
+		extender.Extend(spec)
+	}
+
+	if r.device == nil {
+		r.device = spec.Device()
+	} else {
+		spec.Configure(r.device)
+	}
+
+	if r.grab {
+		if err := r.source.Grab(); err != nil {
+			return err
+		}
+	}
+
+	if err := r.device.Register(); err != nil {
+		_ = r.source.Ungrab()
+		return err
+	}
+
+	r.running = true
+	r.done = make(chan struct{})
+	go r.loop(r.source, r.device, r.done)
+
+	return nil
+}
+
+func (r *remapper) loop(source evdev.InputDevice, device virtual_device.VirtualDevice, done chan struct{}) {
+	defer close(done)
+
+	emit := func(e Event) {
+		device.Send(uint16(e.Type), e.Code, e.Value)
+	}
+
+	for {
+		events, err := source.ReadEvents()
+		if err != nil {
+			if !errors.Is(err, evdev.ErrClosed) {
+				fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", source.Path(), err)
+			}
+			return
+		}
+		for _, event := range events {
+			if event.Type == uint16(linux.EV_SYN) && event.Code == uint16(linux.SYN_DROPPED) {
+				continue
+			}
+			r.pipeline.Apply(Event{Type: linux.EventType(event.Type), Code: event.Code, Value: event.Value}, emit)
+		}
+	}
+}
+
+// Stop releases the grab, closes the source device and unregisters the virtual device. A source set by
+// path is opened again by the next Start, while an injected source stays closed: Start then fails.
+func (r *remapper) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.running {
+		return nil
+	}
+	r.running = false
+
+	closeErr := r.source.Close()
+	<-r.done
+	if r.sourcePath != "" {
+		r.source = nil // a closed source can not be reused, Start opens sourcePath again
+	} else {
+		r.closed = true
+	}
+
+	unregisterErr := r.device.Unregister()
+
+	return errors.Join(closeErr, unregisterErr)
+}
@@ -0,0 +1,193 @@
+package remapper
+
+import (
+	"testing"
+	"time"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/evdev"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+type fakeSource struct {
+	events  chan []linux.InputEvent
+	grabbed bool
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{events: make(chan []linux.InputEvent, 16)}
+}
+
+func (s *fakeSource) Path() string { return "/dev/input/event42" }
+func (s *fakeSource) Name() string { return "Fake Gamepad" }
+func (s *fakeSource) Phys() string { return "" }
+func (s *fakeSource) Uniq() string { return "" }
+func (s *fakeSource) ID() linux.InputID {
+	return linux.InputID{BusType: linux.BUS_USB, Vendor: 1, Product: 2}
+}
+
+func (s *fakeSource) Capabilities() virtual_device.Capabilities {
+	return virtual_device.Capabilities{
+		Keys:    []linux.Key{linux.Key(linux.BTN_SOUTH), linux.Key(linux.BTN_EAST)},
+		AbsAxes: []linux.AbsoluteAxis{linux.ABS_X},
+	}
+}
+
+func (s *fakeSource) AbsAxes() []virtual_device.AbsAxis {
+	return []virtual_device.AbsAxis{{Axis: linux.ABS_X, Min: -32768, Max: 32767}}
+}
+
+func (s *fakeSource) Grab() error   { s.grabbed = true; return nil }
+func (s *fakeSource) Ungrab() error { s.grabbed = false; return nil }
+
+func (s *fakeSource) ReadEvents() ([]linux.InputEvent, error) {
+	events, ok := <-s.events
+	if !ok {
+		return nil, evdev.ErrClosed
+	}
+	return events, nil
+}
+
+func (s *fakeSource) Close() error {
+	s.grabbed = false
+	close(s.events)
+	return nil
+}
+
+func waitForEvents(t *testing.T, mock *vdtest.Device, count int) []vdtest.Event {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if events := mock.Events(); len(events) >= count {
+			return events
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d events, got %d", count, len(mock.Events()))
+	return nil
+}
+
+func TestRemapper_ClonesAndTransforms(t *testing.T) {
+	source := newFakeSource()
+	mock := vdtest.NewDevice()
+
+	r := NewRemapperFactory().
+		WithSource(source).
+		WithDevice(mock).
+		WithTransforms(RemapKey(linux.BTN_SOUTH, linux.KEY_ENTER)).
+		Create()
+
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if !source.grabbed {
+		t.Error("expected source to be grabbed")
+	}
+
+	// the clone keeps the source capabilities and adds the remapped key
+	if len(mock.Keys) != 3 || mock.Keys[2] != linux.KEY_ENTER {
+		t.Errorf("keys = %v, want source keys + KEY_ENTER", mock.Keys)
+	}
+	if len(mock.AbsAxes) != 1 || mock.AbsAxes[0].Min != -32768 {
+		t.Errorf("abs axes = %+v, want cloned ABS_X", mock.AbsAxes)
+	}
+
+	source.events <- []linux.InputEvent{
+		{Type: uint16(linux.EV_KEY), Code: uint16(linux.BTN_SOUTH), Value: 1},
+		{Type: uint16(linux.EV_SYN), Code: uint16(linux.SYN_REPORT)},
+	}
+
+	events := waitForEvents(t, mock, 2)
+	if events[0].EvType != uint16(linux.EV_KEY) || events[0].Code != uint16(linux.KEY_ENTER) || events[0].Value != 1 {
+		t.Errorf("event[0] = %+v, want KEY_ENTER press", events[0])
+	}
+	if events[1].EvType != uint16(linux.EV_SYN) {
+		t.Errorf("event[1] = %+v, want SYN_REPORT", events[1])
+	}
+
+	if err := r.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if source.grabbed {
+		t.Error("expected source to be released")
+	}
+}
+
+func TestRemapper_DropsSynDropped(t *testing.T) {
+	source := newFakeSource()
+	mock := vdtest.NewDevice()
+
+	r := NewRemapperFactory().WithSource(source).WithDevice(mock).WithoutGrab().Create()
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if source.grabbed {
+		t.Error("expected source not to be grabbed")
+	}
+
+	source.events <- []linux.InputEvent{
+		{Type: uint16(linux.EV_SYN), Code: uint16(linux.SYN_DROPPED)},
+		{Type: uint16(linux.EV_ABS), Code: uint16(linux.ABS_X), Value: 42},
+	}
+
+	events := waitForEvents(t, mock, 1)
+	if events[0].EvType != uint16(linux.EV_ABS) || events[0].Value != 42 {
+		t.Errorf("event[0] = %+v, want ABS_X=42", events[0])
+	}
+
+	if err := r.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+}
+
+func TestRemapper_NoSource(t *testing.T) {
+	if err := NewRemapperFactory().Create().Start(); err == nil {
+		t.Error("expected error without source")
+	}
+}
+
+func TestRemapper_RestartWithClosedSource(t *testing.T) {
+	mock := vdtest.NewDevice()
+	r := NewRemapperFactory().WithSource(newFakeSource()).WithDevice(mock).WithoutGrab().Create()
+
+	if err := r.Start(); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	if err := r.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	if err := r.Start(); err == nil {
+		t.Fatal("Start should fail with a source closed by Stop")
+	}
+	if mock.Registered() {
+		t.Error("the virtual device must not be registered by a failed Start")
+	}
+}
+
+type switchSource struct {
+	*fakeSource
+}
+
+func (s switchSource) Phys() string { return "usb-0000:00:14.0-1/input0" }
+
+func (s switchSource) Capabilities() virtual_device.Capabilities {
+	caps := s.fakeSource.Capabilities()
+	caps.Switches = []linux.SwitchEvent{linux.SW_LID}
+	return caps
+}
+
+func TestSpec_ClonesSwitchesAndPhys(t *testing.T) {
+	spec := NewSpec(switchSource{newFakeSource()})
+	mock := vdtest.NewDevice()
+	spec.Configure(mock)
+	if len(mock.Switches) != 1 || mock.Switches[0] != linux.SW_LID {
+		t.Errorf("switches = %v, want SW_LID", mock.Switches)
+	}
+	if mock.Phys != "" {
+		t.Errorf("Configure must leave the identity untouched, phys = %q", mock.Phys)
+	}
+	if spec.Phys != "usb-0000:00:14.0-1/input0" {
+		t.Errorf("phys = %q, want the source phys", spec.Phys)
+	}
+}
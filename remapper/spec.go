@@ -0,0 +1,90 @@
+package remapper
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/evdev"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// Spec is the configuration of the virtual device cloned from the grabbed one.
+type Spec struct {
+	Name       string
+	Phys       string
+	ID         linux.InputID
+	Keys       []linux.Key // keys and buttons share the same bitmap
+	RelAxes    []linux.RelativeAxis
+	AbsAxes    []virtual_device.AbsAxis
+	MiscEvents []linux.MiscEvent
+	LEDs       []linux.Led
+	Switches   []linux.SwitchEvent
+	Properties []linux.InputProp
+}
+
+// NewSpec clones the identity and capabilities of a source device.
+func NewSpec(source evdev.InputDevice) *Spec {
+	caps := source.Capabilities()
+	return &Spec{
+		Name:       source.Name(),
+		Phys:       source.Phys(),
+		ID:         source.ID(),
+		Keys:       append([]linux.Key{}, caps.Keys...),
+		RelAxes:    append([]linux.RelativeAxis{}, caps.RelAxes...),
+		AbsAxes:    append([]virtual_device.AbsAxis{}, source.AbsAxes()...),
+		MiscEvents: append([]linux.MiscEvent{}, caps.MiscEvents...),
+		LEDs:       append([]linux.Led{}, caps.LEDs...),
+		Switches:   append([]linux.SwitchEvent{}, caps.Switches...),
+		Properties: append([]linux.InputProp{}, caps.Properties...),
+	}
+}
+
+func (s *Spec) addKey(key linux.Key) {
+	for _, k := range s.Keys {
+		if k == key {
+			return
+		}
+	}
+	s.Keys = append(s.Keys, key)
+}
+
+func (s *Spec) addAbsAxis(axis virtual_device.AbsAxis) {
+	for _, a := range s.AbsAxes {
+		if a.Axis == axis.Axis {
+			return
+		}
+	}
+	s.AbsAxes = append(s.AbsAxes, axis)
+}
+
+// Configure applies the capabilities (not the identity) to an existing virtual device.
+func (s *Spec) Configure(device virtual_device.VirtualDevice) virtual_device.VirtualDevice {
+	device.WithKeys(s.Keys)
+	if len(s.RelAxes) > 0 {
+		device.WithRelAxes(s.RelAxes)
+	}
+	if len(s.AbsAxes) > 0 {
+		device.WithAbsAxes(s.AbsAxes)
+	}
+	if len(s.MiscEvents) > 0 {
+		device.WithMiscEvents(s.MiscEvents)
+	}
+	if len(s.Switches) > 0 {
+		device.WithSwitches(s.Switches)
+	}
+	device.WithLEDs(s.LEDs)
+	device.WithProperties(s.Properties)
+	return device
+}
+
+// Device creates a virtual device with the identity and capabilities of the spec.
+func (s *Spec) Device() virtual_device.VirtualDevice {
+	return s.Configure(
+		virtual_device.
+			NewVirtualDevice().
+			WithBusType(s.ID.BusType).
+			WithVendor(s.ID.Vendor).
+			WithProduct(s.ID.Product).
+			WithVersion(s.ID.Version).
+			WithName(s.Name).
+			WithPhys(s.Phys),
+	)
+}
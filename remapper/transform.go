@@ -0,0 +1,223 @@
+package remapper
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// Event is an input event flowing through the transform pipeline.
+type Event struct {
+	Type  linux.EventType
+	Code  uint16
+	Value int32
+}
+
+// Transform rewrites one event into zero, one or several events passed to emit.
+type Transform interface {
+	Apply(event Event, emit func(Event))
+}
+
+// Extender is implemented by transforms emitting codes the grabbed device does not declare,
+// so that the virtual device is created with them.
+type Extender interface {
+	Extend(spec *Spec)
+}
+
+// TransformFunc adapts a plain function to the Transform interface.
+type TransformFunc func(event Event, emit func(Event))
+
+func (f TransformFunc) Apply(event Event, emit func(Event)) {
+	f(event, emit)
+}
+
+type keyCode interface {
+	linux.Key | linux.Button
+}
+
+const (
+	valueReleased = 0
+	valuePressed  = 1
+)
+
+type chain []Transform
+
+// Chain runs transforms in sequence: every event emitted by a transform is fed to the next one.
+func Chain(transforms ...Transform) Transform {
+	return chain(transforms)
+}
+
+func (c chain) Apply(event Event, emit func(Event)) {
+	c.apply(0, event, emit)
+}
+
+func (c chain) apply(index int, event Event, emit func(Event)) {
+	if index == len(c) {
+		emit(event)
+		return
+	}
+	c[index].Apply(event, func(e Event) {
+		c.apply(index+1, e, emit)
+	})
+}
+
+func (c chain) Extend(spec *Spec) {
+	for _, transform := range c {
+		if extender, ok := transform.(Extender); ok {
+			extender.Extend(spec)
+		}
+	}
+}
+
+type remapKey struct {
+	from uint16
+	to   uint16
+}
+
+// RemapKey replaces a key or button by another one (e.g. BTN_SOUTH to KEY_ENTER).
+func RemapKey[F, T keyCode](from F, to T) Transform {
+	return &remapKey{from: uint16(from), to: uint16(to)}
+}
+
+func (t *remapKey) Apply(event Event, emit func(Event)) {
+	if event.Type == linux.EV_KEY && event.Code == t.from {
+		event.Code = t.to
+	}
+	emit(event)
+}
+
+func (t *remapKey) Extend(spec *Spec) {
+	spec.addKey(linux.Key(t.to))
+}
+
+type buttonToAxis struct {
+	button uint16
+	axis   virtual_device.AbsAxis
+	value  float32
+}
+
+// ButtonToAxis moves an absolute axis to value (normalized, see AbsAxis.Denormalize) while the
+// button is held, and back to the center (or Min for unidirectional axes) on release.
+func ButtonToAxis[T keyCode](button T, axis virtual_device.AbsAxis, value float32) Transform {
+	return &buttonToAxis{button: uint16(button), axis: axis, value: value}
+}
+
+func (t *buttonToAxis) Apply(event Event, emit func(Event)) {
+	if event.Type != linux.EV_KEY || event.Code != t.button {
+		emit(event)
+		return
+	}
+	switch event.Value {
+	case valuePressed:
+		emit(Event{Type: linux.EV_ABS, Code: uint16(t.axis.Axis), Value: t.axis.Denormalize(t.value)})
+	case valueReleased:
+		emit(Event{Type: linux.EV_ABS, Code: uint16(t.axis.Axis), Value: t.axis.Denormalize(0)})
+	}
+}
+
+func (t *buttonToAxis) Extend(spec *Spec) {
+	spec.addAbsAxis(t.axis)
+}
+
+type invertAxis struct {
+	axis virtual_device.AbsAxis
+}
+
+// InvertAxis mirrors an absolute axis within its [Min, Max] range.
+// The range is usually taken from the grabbed device, see Remapper.Source.
+func InvertAxis(axis virtual_device.AbsAxis) Transform {
+	return &invertAxis{axis: axis}
+}
+
+func (t *invertAxis) Apply(event Event, emit func(Event)) {
+	if event.Type == linux.EV_ABS && event.Code == uint16(t.axis.Axis) {
+		event.Value = t.axis.Min + t.axis.Max - event.Value
+	}
+	emit(event)
+}
+
+type layer struct {
+	modifier  uint16
+	transform Transform
+	active    bool
+	held      map[uint16]bool
+}
+
+// Layer applies transforms only while the modifier key is held. The modifier itself is swallowed.
+// Keys pressed inside the layer are released through the layer even if the modifier is released first.
+func Layer[T keyCode](modifier T, transforms ...Transform) Transform {
+	return &layer{
+		modifier:  uint16(modifier),
+		transform: Chain(transforms...),
+		held:      map[uint16]bool{},
+	}
+}
+
+func (t *layer) Apply(event Event, emit func(Event)) {
+	if event.Type == linux.EV_KEY && event.Code == t.modifier {
+		t.active = event.Value != valueReleased
+		return
+	}
+
+	if event.Type == linux.EV_KEY {
+		if t.active && event.Value == valuePressed {
+			t.held[event.Code] = true
+		}
+		if t.held[event.Code] {
+			if event.Value == valueReleased {
+				delete(t.held, event.Code)
+			}
+			t.transform.Apply(event, emit)
+			return
+		}
+		emit(event)
+		return
+	}
+
+	if t.active && event.Type != linux.EV_SYN {
+		t.transform.Apply(event, emit)
+		return
+	}
+	emit(event)
+}
+
+func (t *layer) Extend(spec *Spec) {
+	if extender, ok := t.transform.(Extender); ok {
+		extender.Extend(spec)
+	}
+}
+
+type macro struct {
+	trigger uint16
+	keys    []uint16
+}
+
+// Macro taps the keys in sequence when trigger is pressed. The trigger itself is swallowed.
+func Macro[T, K keyCode](trigger T, keys ...K) Transform {
+	codes := make([]uint16, len(keys))
+	for i, key := range keys {
+		codes[i] = uint16(key)
+	}
+	return &macro{trigger: uint16(trigger), keys: codes}
+}
+
+func (t *macro) Apply(event Event, emit func(Event)) {
+	if event.Type != linux.EV_KEY || event.Code != t.trigger {
+		emit(event)
+		return
+	}
+	if event.Value != valuePressed {
+		return
+	}
+	for _, key := range t.keys {
+		emit(Event{Type: linux.EV_KEY, Code: key, Value: valuePressed})
+		emit(Event{Type: linux.EV_SYN, Code: uint16(linux.SYN_REPORT)})
+		emit(Event{Type: linux.EV_KEY, Code: key, Value: valueReleased})
+		emit(Event{Type: linux.EV_SYN, Code: uint16(linux.SYN_REPORT)})
+	}
+}
+
+func (t *macro) Extend(spec *Spec) {
+	for _, key := range t.keys {
+		spec.addKey(linux.Key(key))
+	}
+}
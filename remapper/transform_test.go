@@ -0,0 +1,153 @@
+package remapper
+
+import (
+	"reflect"
+	"testing"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+func key(code linux.Key, value int32) Event {
+	return Event{Type: linux.EV_KEY, Code: uint16(code), Value: value}
+}
+
+func button(code linux.Button, value int32) Event {
+	return Event{Type: linux.EV_KEY, Code: uint16(code), Value: value}
+}
+
+func abs(axis linux.AbsoluteAxis, value int32) Event {
+	return Event{Type: linux.EV_ABS, Code: uint16(axis), Value: value}
+}
+
+var syn = Event{Type: linux.EV_SYN, Code: uint16(linux.SYN_REPORT)}
+
+func run(transform Transform, events ...Event) []Event {
+	var out []Event
+	for _, event := range events {
+		transform.Apply(event, func(e Event) {
+			out = append(out, e)
+		})
+	}
+	return out
+}
+
+func TestRemapKey(t *testing.T) {
+	got := run(RemapKey(linux.BTN_SOUTH, linux.KEY_ENTER), button(linux.BTN_SOUTH, 1), syn, button(linux.BTN_EAST, 1))
+	want := []Event{key(linux.KEY_ENTER, 1), syn, button(linux.BTN_EAST, 1)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestButtonToAxis(t *testing.T) {
+	axis := virtual_device.AbsAxis{Axis: linux.ABS_Z, Min: 0, Max: 255, IsUnidirectional: true}
+	got := run(ButtonToAxis(linux.BTN_TL2, axis, 1), button(linux.BTN_TL2, 1), button(linux.BTN_TL2, 0))
+	want := []Event{abs(linux.ABS_Z, 255), abs(linux.ABS_Z, 0)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestInvertAxis(t *testing.T) {
+	axis := virtual_device.AbsAxis{Axis: linux.ABS_Y, Min: -32768, Max: 32767}
+	got := run(InvertAxis(axis), abs(linux.ABS_Y, -32768), abs(linux.ABS_Y, 100), abs(linux.ABS_X, 100))
+	want := []Event{abs(linux.ABS_Y, 32767), abs(linux.ABS_Y, -101), abs(linux.ABS_X, 100)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestChain_FeedsNextTransform(t *testing.T) {
+	transform := Chain(
+		RemapKey(linux.KEY_A, linux.KEY_B),
+		RemapKey(linux.KEY_B, linux.KEY_C),
+	)
+	got := run(transform, key(linux.KEY_A, 1))
+	want := []Event{key(linux.KEY_C, 1)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestLayer_ActiveOnlyWhileHeld(t *testing.T) {
+	transform := Layer(linux.KEY_CAPSLOCK, RemapKey(linux.KEY_H, linux.KEY_LEFT))
+
+	got := run(transform,
+		key(linux.KEY_H, 1), key(linux.KEY_H, 0),
+		key(linux.KEY_CAPSLOCK, 1),
+		key(linux.KEY_H, 1), key(linux.KEY_H, 0),
+		key(linux.KEY_CAPSLOCK, 0),
+		key(linux.KEY_H, 1),
+	)
+	want := []Event{
+		key(linux.KEY_H, 1), key(linux.KEY_H, 0),
+		key(linux.KEY_LEFT, 1), key(linux.KEY_LEFT, 0),
+		key(linux.KEY_H, 1),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestLayer_ReleaseAfterModifier(t *testing.T) {
+	transform := Layer(linux.KEY_CAPSLOCK, RemapKey(linux.KEY_H, linux.KEY_LEFT))
+
+	got := run(transform,
+		key(linux.KEY_CAPSLOCK, 1),
+		key(linux.KEY_H, 1),
+		key(linux.KEY_CAPSLOCK, 0),
+		key(linux.KEY_H, 0),
+	)
+	want := []Event{key(linux.KEY_LEFT, 1), key(linux.KEY_LEFT, 0)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestLayer_KeyPressedBeforeModifier(t *testing.T) {
+	transform := Layer(linux.KEY_CAPSLOCK, RemapKey(linux.KEY_H, linux.KEY_LEFT))
+
+	got := run(transform,
+		key(linux.KEY_H, 1),
+		key(linux.KEY_CAPSLOCK, 1),
+		key(linux.KEY_H, 0),
+	)
+	want := []Event{key(linux.KEY_H, 1), key(linux.KEY_H, 0)}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestMacro(t *testing.T) {
+	transform := Macro(linux.BTN_TRIGGER_HAPPY1, linux.KEY_H, linux.KEY_I)
+
+	got := run(transform, button(linux.BTN_TRIGGER_HAPPY1, 1), button(linux.BTN_TRIGGER_HAPPY1, 0))
+	want := []Event{
+		key(linux.KEY_H, 1), syn, key(linux.KEY_H, 0), syn,
+		key(linux.KEY_I, 1), syn, key(linux.KEY_I, 0), syn,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestExtend(t *testing.T) {
+	spec := &Spec{Keys: []linux.Key{linux.KEY_A}}
+	trigger := virtual_device.AbsAxis{Axis: linux.ABS_Z, Max: 255, IsUnidirectional: true}
+
+	transform := Chain(
+		RemapKey(linux.KEY_B, linux.KEY_A),
+		Layer(linux.KEY_CAPSLOCK, RemapKey(linux.KEY_H, linux.KEY_LEFT)),
+		ButtonToAxis(linux.BTN_TL2, trigger, 1),
+	)
+	transform.(Extender).Extend(spec)
+
+	wantKeys := []linux.Key{linux.KEY_A, linux.KEY_LEFT}
+	if !reflect.DeepEqual(spec.Keys, wantKeys) {
+		t.Errorf("keys = %v, want %v", spec.Keys, wantKeys)
+	}
+	if len(spec.AbsAxes) != 1 || spec.AbsAxes[0].Axis != linux.ABS_Z {
+		t.Errorf("abs axes = %+v, want ABS_Z", spec.AbsAxes)
+	}
+}
@@ -0,0 +1,171 @@
+package sdl
+
+// based on https://github.com/libsdl-org/SDL/blob/release-2.30.x/src/joystick/controller_list.h
+
+// ControllerType is the family SDL detects from the vendor and product IDs of a controller.
+type ControllerType int
+
+const (
+	ControllerGeneric ControllerType = iota
+	ControllerXBox360
+	ControllerXBoxOne
+	ControllerPS4
+	ControllerPS5
+	ControllerSwitchPro
+	ControllerJoyCon
+	ControllerStadia
+)
+
+func (t ControllerType) String() string {
+	switch t {
+	case ControllerXBox360:
+		return "Xbox360"
+	case ControllerXBoxOne:
+		return "XboxOne"
+	case ControllerPS4:
+		return "PS4"
+	case ControllerPS5:
+		return "PS5"
+	case ControllerSwitchPro:
+		return "SwitchPro"
+	case ControllerJoyCon:
+		return "JoyCon"
+	case ControllerStadia:
+		return "Stadia"
+	}
+	return "Generic"
+}
+
+// FaceLayout describes the labels of the face buttons, as SDL uses them to name the buttons.
+type FaceLayout int
+
+const (
+	FaceLayoutUnknown     FaceLayout = iota
+	FaceLayoutXBox                   // A bottom, B right, X left, Y top
+	FaceLayoutPlayStation            // Cross bottom, Circle right, Square left, Triangle top
+	FaceLayoutNintendo               // B bottom, A right, Y left, X top
+)
+
+func (l FaceLayout) String() string {
+	switch l {
+	case FaceLayoutXBox:
+		return "Xbox"
+	case FaceLayoutPlayStation:
+		return "PlayStation"
+	case FaceLayoutNintendo:
+		return "Nintendo"
+	}
+	return "Unknown"
+}
+
+// Layout returns the face buttons layout of the family, FaceLayoutUnknown for the generic controllers.
+func (t ControllerType) Layout() FaceLayout {
+	switch t {
+	case ControllerXBox360, ControllerXBoxOne, ControllerStadia:
+		return FaceLayoutXBox
+	case ControllerPS4, ControllerPS5:
+		return FaceLayoutPlayStation
+	case ControllerSwitchPro, ControllerJoyCon:
+		return FaceLayoutNintendo
+	}
+	return FaceLayoutUnknown
+}
+
+// Controller is an entry of the controller table.
+type Controller struct {
+	Vendor  Vendor
+	Product Product
+	Type    ControllerType
+	Name    string
+}
+
+// Layout returns the face buttons layout of the controller.
+func (c Controller) Layout() FaceLayout {
+	return c.Type.Layout()
+}
+
+type controllerID struct {
+	vendor  Vendor
+	product Product
+}
+
+var controllers = map[controllerID]Controller{}
+
+func init() {
+	for _, c := range []Controller{
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX360_WIRED_CONTROLLER, ControllerXBox360, "Xbox 360 Controller"},
+		{USB_VENDOR_MICROSOFT, 0x028f, ControllerXBox360, "Xbox 360 Wireless Controller"},
+		{USB_VENDOR_MICROSOFT, 0x0291, ControllerXBox360, "Xbox 360 Wireless Receiver"},
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX360_XUSB_CONTROLLER, ControllerXBox360, "Xbox 360 Controller"},
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX360_WIRELESS_RECEIVER, ControllerXBox360, "Xbox 360 Wireless Receiver"},
+		{USB_VENDOR_ASTRO, USB_PRODUCT_ASTRO_C40_XBOX360, ControllerXBox360, "ASTRO C40"},
+		{USB_VENDOR_LOGITECH, 0xc21d, ControllerXBox360, "Logitech Gamepad F310"},
+		{USB_VENDOR_LOGITECH, 0xc21e, ControllerXBox360, "Logitech Gamepad F510"},
+		{USB_VENDOR_LOGITECH, 0xc21f, ControllerXBox360, "Logitech Gamepad F710"},
+
+		{USB_VENDOR_MICROSOFT, 0x02d1, ControllerXBoxOne, "Xbox One Controller"},
+		{USB_VENDOR_MICROSOFT, 0x02dd, ControllerXBoxOne, "Xbox One Controller"},
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX_ONE_S_REV1_BLUETOOTH, ControllerXBoxOne, "Xbox One S Controller"},
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX_ONE_ELITE_SERIES_1, ControllerXBoxOne, "Xbox One Elite Controller"},
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX_ONE_S, ControllerXBoxOne, "Xbox One S Controller"},
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX_ONE_S_REV2_BLUETOOTH, ControllerXBoxOne, "Xbox One S Controller"},
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX_ONE_XBOXGIP_CONTROLLER, ControllerXBoxOne, "Xbox One Controller"},
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX_ONE_ELITE_SERIES_2, ControllerXBoxOne, "Xbox One Elite 2 Controller"},
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX_ONE_ELITE_SERIES_2_BLUETOOTH, ControllerXBoxOne, "Xbox One Elite 2 Controller"},
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX_ONE_ELITE_SERIES_2_BLE, ControllerXBoxOne, "Xbox One Elite 2 Controller"},
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX_ONE_ADAPTIVE, ControllerXBoxOne, "Xbox Adaptive Controller"},
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX_ONE_ADAPTIVE_BLUETOOTH, ControllerXBoxOne, "Xbox Adaptive Controller"},
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX_ONE_ADAPTIVE_BLE, ControllerXBoxOne, "Xbox Adaptive Controller"},
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX_SERIES_X, ControllerXBoxOne, "Xbox Series X Controller"},
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX_SERIES_X_BLE, ControllerXBoxOne, "Xbox Series X Controller"},
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX_ONE_S_REV2_BLE, ControllerXBoxOne, "Xbox One S Controller"},
+		{USB_VENDOR_PDP, USB_PRODUCT_XBOX_SERIES_X_VICTRIX_GAMBIT, ControllerXBoxOne, "PDP Victrix Gambit"},
+		{USB_VENDOR_PDP, USB_PRODUCT_XBOX_SERIES_X_PDP_BLUE, ControllerXBoxOne, "PDP Xbox Series X Controller"},
+		{USB_VENDOR_PDP, USB_PRODUCT_XBOX_SERIES_X_PDP_AFTERGLOW, ControllerXBoxOne, "PDP Afterglow Xbox Series X Controller"},
+		{USB_VENDOR_POWERA_ALT, USB_PRODUCT_XBOX_SERIES_X_POWERA_FUSION_PRO2, ControllerXBoxOne, "PowerA Fusion Pro 2"},
+		{USB_VENDOR_POWERA_ALT, USB_PRODUCT_XBOX_SERIES_X_POWERA_SPECTRA, ControllerXBoxOne, "PowerA Spectra"},
+		{USB_VENDOR_HORI, USB_PRODUCT_HORI_HORIPAD_PRO_SERIES_X, ControllerXBoxOne, "HORIPAD Pro"},
+		{USB_VENDOR_HORI, USB_PRODUCT_HORI_FIGHTING_COMMANDER_OCTA_SERIES_X, ControllerXBoxOne, "HORI Fighting Commander OCTA"},
+		{USB_VENDOR_8BITDO, USB_PRODUCT_8BITDO_XBOX_CONTROLLER1, ControllerXBoxOne, "8BitDo Ultimate Wired Controller"},
+		{USB_VENDOR_TURTLE_BEACH, USB_PRODUCT_TURTLE_BEACH_SERIES_X_REACT_R, ControllerXBoxOne, "Turtle Beach REACT-R"},
+		{USB_VENDOR_TURTLE_BEACH, USB_PRODUCT_TURTLE_BEACH_SERIES_X_RECON, ControllerXBoxOne, "Turtle Beach Recon"},
+
+		{USB_VENDOR_SONY, USB_PRODUCT_SONY_DS4, ControllerPS4, "PS4 Controller"},
+		{USB_VENDOR_SONY, USB_PRODUCT_SONY_DS4_STRIKEPAD, ControllerPS4, "PS4 Controller"},
+		{USB_VENDOR_SONY, USB_PRODUCT_SONY_DS4_SLIM, ControllerPS4, "PS4 Controller"},
+		{USB_VENDOR_SONY, USB_PRODUCT_SONY_DS4_DONGLE, ControllerPS4, "PS4 Controller"},
+		{USB_VENDOR_HORI, USB_PRODUCT_HORI_FIGHTING_STICK_ALPHA_PS4, ControllerPS4, "HORI Fighting Stick α"},
+		{USB_VENDOR_NACON, USB_PRODUCT_NACON_REVOLUTION_5_PRO_PS4_WIRELESS, ControllerPS4, "Nacon Revolution 5 Pro"},
+		{USB_VENDOR_NACON, USB_PRODUCT_NACON_REVOLUTION_5_PRO_PS4_WIRED, ControllerPS4, "Nacon Revolution 5 Pro"},
+		{USB_VENDOR_RAZER, USB_PRODUCT_RAZER_RAIJU, ControllerPS4, "Razer Raiju"},
+		{USB_VENDOR_RAZER, USB_PRODUCT_RAZER_PANTHERA, ControllerPS4, "Razer Panthera"},
+		{USB_VENDOR_RAZER, USB_PRODUCT_RAZER_PANTHERA_EVO, ControllerPS4, "Razer Panthera Evo"},
+
+		{USB_VENDOR_SONY, USB_PRODUCT_SONY_DS5, ControllerPS5, "PS5 Controller"},
+		{USB_VENDOR_SONY, USB_PRODUCT_SONY_DS5_EDGE, ControllerPS5, "DualSense Edge"},
+		{USB_VENDOR_HORI, USB_PRODUCT_HORI_FIGHTING_STICK_ALPHA_PS5, ControllerPS5, "HORI Fighting Stick α"},
+		{USB_VENDOR_NACON, USB_PRODUCT_NACON_REVOLUTION_5_PRO_PS5_WIRELESS, ControllerPS5, "Nacon Revolution 5 Pro"},
+		{USB_VENDOR_NACON, USB_PRODUCT_NACON_REVOLUTION_5_PRO_PS5_WIRED, ControllerPS5, "Nacon Revolution 5 Pro"},
+		{USB_VENDOR_RAZER, USB_PRODUCT_RAZER_WOLVERINE_V2_PRO_PS5_WIRED, ControllerPS5, "Razer Wolverine V2 Pro"},
+		{USB_VENDOR_RAZER, USB_PRODUCT_RAZER_WOLVERINE_V2_PRO_PS5_WIRELESS, ControllerPS5, "Razer Wolverine V2 Pro"},
+
+		{USB_VENDOR_NINTENDO, USB_PRODUCT_NINTENDO_SWITCH_PRO, ControllerSwitchPro, "Nintendo Switch Pro Controller"},
+		{USB_VENDOR_NINTENDO, USB_PRODUCT_NINTENDO_SWITCH_JOYCON_LEFT, ControllerJoyCon, "Nintendo Switch Joy-Con (L)"},
+		{USB_VENDOR_NINTENDO, USB_PRODUCT_NINTENDO_SWITCH_JOYCON_RIGHT, ControllerJoyCon, "Nintendo Switch Joy-Con (R)"},
+		{USB_VENDOR_NINTENDO, USB_PRODUCT_NINTENDO_SWITCH_JOYCON_PAIR, ControllerJoyCon, "Nintendo Switch Joy-Con Pair"},
+		{USB_VENDOR_NINTENDO, USB_PRODUCT_NINTENDO_SWITCH_JOYCON_GRIP, ControllerJoyCon, "Nintendo Switch Joy-Con Grip"},
+
+		{USB_VENDOR_GOOGLE, USB_PRODUCT_GOOGLE_STADIA_CONTROLLER, ControllerStadia, "Google Stadia Controller"},
+	} {
+		controllers[controllerID{c.Vendor, c.Product}] = c
+	}
+}
+
+// LookupController returns the table entry of a controller.
+// An unknown controller is returned as ControllerGeneric, without name, and false.
+func LookupController(vendor Vendor, product Product) (Controller, bool) {
+	if c, ok := controllers[controllerID{vendor, product}]; ok {
+		return c, true
+	}
+	return Controller{Vendor: vendor, Product: product, Type: ControllerGeneric}, false
+}
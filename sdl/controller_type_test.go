@@ -0,0 +1,39 @@
+package sdl
+
+import "testing"
+
+func TestLookupController(t *testing.T) {
+	tests := []struct {
+		vendor  Vendor
+		product Product
+		want    ControllerType
+		layout  FaceLayout
+	}{
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX360_XUSB_CONTROLLER, ControllerXBox360, FaceLayoutXBox},
+		{USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX_ONE_ELITE_SERIES_2, ControllerXBoxOne, FaceLayoutXBox},
+		{USB_VENDOR_SONY, USB_PRODUCT_SONY_DS4_SLIM, ControllerPS4, FaceLayoutPlayStation},
+		{USB_VENDOR_SONY, USB_PRODUCT_SONY_DS5, ControllerPS5, FaceLayoutPlayStation},
+		{USB_VENDOR_NINTENDO, USB_PRODUCT_NINTENDO_SWITCH_PRO, ControllerSwitchPro, FaceLayoutNintendo},
+		{USB_VENDOR_NINTENDO, USB_PRODUCT_NINTENDO_SWITCH_JOYCON_PAIR, ControllerJoyCon, FaceLayoutNintendo},
+		{USB_VENDOR_GOOGLE, USB_PRODUCT_GOOGLE_STADIA_CONTROLLER, ControllerStadia, FaceLayoutXBox},
+	}
+	for _, tt := range tests {
+		c, ok := LookupController(tt.vendor, tt.product)
+		if !ok || c.Type != tt.want || c.Name == "" {
+			t.Errorf("LookupController(%#04x, %#04x) = %+v, %v, want %v", tt.vendor, tt.product, c, ok, tt.want)
+		}
+		if c.Layout() != tt.layout {
+			t.Errorf("%s layout = %v, want %v", c.Name, c.Layout(), tt.layout)
+		}
+	}
+}
+
+func TestLookupController_Unknown(t *testing.T) {
+	c, ok := LookupController(USB_VENDOR_SAITEK, 0xff0d)
+	if ok || c.Type != ControllerGeneric || c.Name != "" || c.Layout() != FaceLayoutUnknown {
+		t.Errorf("unexpected entry %+v, %v", c, ok)
+	}
+	if c.Vendor != USB_VENDOR_SAITEK || c.Product != 0xff0d {
+		t.Errorf("the IDs must be kept, got %+v", c)
+	}
+}
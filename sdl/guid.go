@@ -0,0 +1,96 @@
+package sdl
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// based on SDL_CreateJoystickGUID, https://github.com/libsdl-org/SDL/blob/release-2.30.x/src/joystick/SDL_joystick.c
+
+// GUID identifies a joystick the way SDL does, in its mappings and its hints.
+type GUID [16]byte
+
+// NewGUID computes the GUID SDL gives to a Linux evdev device.
+func NewGUID(bus linux.BusType, vendor Vendor, product Product, version uint16, name string) GUID {
+	var guid GUID
+	binary.LittleEndian.PutUint16(guid[0:], uint16(bus))
+	binary.LittleEndian.PutUint16(guid[2:], crc16([]byte(name)))
+	if vendor != 0 && product != 0 {
+		binary.LittleEndian.PutUint16(guid[4:], vendor)
+		binary.LittleEndian.PutUint16(guid[8:], product)
+		binary.LittleEndian.PutUint16(guid[12:], version)
+	} else {
+		copy(guid[4:15], name) // the last byte stays 0, name based GUIDs are truncated
+	}
+	return guid
+}
+
+// ParseGUID reads the 32 hexadecimal characters of a GUID.
+func ParseGUID(s string) (GUID, error) {
+	var guid GUID
+	if len(s) != 32 {
+		return guid, fmt.Errorf("invalid SDL GUID %q: expected 32 hexadecimal characters", s)
+	}
+	if _, err := hex.Decode(guid[:], []byte(s)); err != nil {
+		return guid, fmt.Errorf("invalid SDL GUID %q: %v", s, err)
+	}
+	return guid, nil
+}
+
+func (g GUID) String() string {
+	return hex.EncodeToString(g[:])
+}
+
+func (g GUID) Bus() linux.BusType {
+	return linux.BusType(binary.LittleEndian.Uint16(g[0:]))
+}
+
+// CRC returns the CRC16 of the device name, 0 in most of the published mappings.
+func (g GUID) CRC() uint16 {
+	return binary.LittleEndian.Uint16(g[2:])
+}
+
+// HasIDs reports whether the GUID holds the vendor, product and version, instead of the beginning of the name.
+func (g GUID) HasIDs() bool {
+	return g.Vendor() != 0 && g.Product() != 0 && g[6] == 0 && g[7] == 0 && g[10] == 0 && g[11] == 0
+}
+
+func (g GUID) Vendor() Vendor {
+	return binary.LittleEndian.Uint16(g[4:])
+}
+
+func (g GUID) Product() Product {
+	return binary.LittleEndian.Uint16(g[8:])
+}
+
+func (g GUID) Version() uint16 {
+	return binary.LittleEndian.Uint16(g[12:])
+}
+
+// WithoutCRC returns the GUID with the CRC cleared, as SDL matches it when no mapping has the exact GUID.
+func (g GUID) WithoutCRC() GUID {
+	g[2], g[3] = 0, 0
+	return g
+}
+
+// crc16 is SDL_crc16 (CRC-16/ARC).
+func crc16(data []byte) uint16 {
+	var crc uint16
+	for _, b := range data {
+		r := byte(crc) ^ b
+		var c uint16
+		for i := 0; i < 8; i++ {
+			if (c^uint16(r))&1 != 0 {
+				c = (c >> 1) ^ 0xA001
+			} else {
+				c >>= 1
+			}
+			r >>= 1
+		}
+		crc = c ^ crc>>8
+	}
+	return crc
+}
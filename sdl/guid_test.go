@@ -0,0 +1,55 @@
+package sdl
+
+import (
+	"testing"
+
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+func TestCRC16(t *testing.T) {
+	if crc := crc16([]byte("123456789")); crc != 0xbb3d {
+		t.Errorf("crc16 = %#04x, want 0xbb3d", crc)
+	}
+}
+
+func TestNewGUID(t *testing.T) {
+	guid := NewGUID(linux.BUS_USB, USB_VENDOR_MICROSOFT, USB_PRODUCT_XBOX360_WIRED_CONTROLLER, 0x114, "Microsoft X-Box 360 pad")
+	if got := guid.WithoutCRC().String(); got != "030000005e0400008e02000014010000" {
+		t.Errorf("GUID = %s", got)
+	}
+	if guid.CRC() != crc16([]byte("Microsoft X-Box 360 pad")) {
+		t.Errorf("CRC = %#04x", guid.CRC())
+	}
+	if !guid.HasIDs() || guid.Bus() != linux.BUS_USB || guid.Vendor() != USB_VENDOR_MICROSOFT ||
+		guid.Product() != USB_PRODUCT_XBOX360_WIRED_CONTROLLER || guid.Version() != 0x114 {
+		t.Errorf("unexpected fields in %s", guid)
+	}
+}
+
+func TestNewGUID_WithoutIDs(t *testing.T) {
+	guid := NewGUID(linux.BUS_VIRTUAL, 0, 0, 0, "My very long gamepad name")
+	if guid.HasIDs() {
+		t.Errorf("%s must not hold IDs", guid)
+	}
+	if got := string(guid[4:15]); got != "My very lon" {
+		t.Errorf("name part = %q", got)
+	}
+	if guid[15] != 0 {
+		t.Errorf("last byte = %d, want 0", guid[15])
+	}
+}
+
+func TestParseGUID(t *testing.T) {
+	guid, err := ParseGUID("030000005e0400008e02000014010000")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if guid.String() != "030000005e0400008e02000014010000" {
+		t.Errorf("round trip = %s", guid)
+	}
+	for _, s := range []string{"", "030000005e04", "zz0000005e0400008e02000014010000"} {
+		if _, err := ParseGUID(s); err == nil {
+			t.Errorf("ParseGUID(%q) must fail", s)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package sdl
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// https://github.com/mdqinc/SDL_GameControllerDB
+
+// Names of the gamepad inputs in a mapping.
+const (
+	InputA             = "a"
+	InputB             = "b"
+	InputX             = "x"
+	InputY             = "y"
+	InputBack          = "back"
+	InputGuide         = "guide"
+	InputStart         = "start"
+	InputLeftStick     = "leftstick"
+	InputRightStick    = "rightstick"
+	InputLeftShoulder  = "leftshoulder"
+	InputRightShoulder = "rightshoulder"
+	InputDPadUp        = "dpup"
+	InputDPadDown      = "dpdown"
+	InputDPadLeft      = "dpleft"
+	InputDPadRight     = "dpright"
+	InputMisc1         = "misc1"
+	InputPaddle1       = "paddle1"
+	InputPaddle2       = "paddle2"
+	InputPaddle3       = "paddle3"
+	InputPaddle4       = "paddle4"
+	InputTouchpad      = "touchpad"
+	InputLeftX         = "leftx"
+	InputLeftY         = "lefty"
+	InputRightX        = "rightx"
+	InputRightY        = "righty"
+	InputLeftTrigger   = "lefttrigger"
+	InputRightTrigger  = "righttrigger"
+)
+
+// Hat masks, as used in the hat bindings.
+const (
+	HatUp    = 1
+	HatRight = 2
+	HatDown  = 4
+	HatLeft  = 8
+)
+
+// BindingType is the kind of joystick input a gamepad input is bound to.
+type BindingType int
+
+const (
+	BindingButton BindingType = iota // bN
+	BindingAxis                      // aN, +aN, -aN, aN~
+	BindingHat                       // hN.M
+)
+
+// AxisRange selects the part of an axis used by a binding.
+type AxisRange int
+
+const (
+	AxisFull     AxisRange = iota // aN
+	AxisPositive                  // +aN
+	AxisNegative                  // -aN
+)
+
+// Binding is the joystick input of a gamepad input, e.g. "b0", "a2", "+a3", "a1~" or "h0.4".
+type Binding struct {
+	Type     BindingType
+	Index    int
+	Range    AxisRange // for axes
+	Inverted bool      // for axes
+	HatMask  int       // for hats, see HatUp, HatRight, HatDown and HatLeft
+}
+
+// ParseBinding reads the value of a mapping field.
+func ParseBinding(s string) (Binding, error) {
+	var b Binding
+	value := s
+	switch {
+	case strings.HasPrefix(value, "+"):
+		b.Range, value = AxisPositive, value[1:]
+	case strings.HasPrefix(value, "-"):
+		b.Range, value = AxisNegative, value[1:]
+	}
+	if strings.HasSuffix(value, "~") {
+		b.Inverted, value = true, strings.TrimSuffix(value, "~")
+	}
+	if value == "" {
+		return b, fmt.Errorf("invalid SDL binding %q", s)
+	}
+
+	var err error
+	switch value[0] {
+	case 'b':
+		b.Type = BindingButton
+		b.Index, err = strconv.Atoi(value[1:])
+	case 'a':
+		b.Type = BindingAxis
+		b.Index, err = strconv.Atoi(value[1:])
+	case 'h':
+		b.Type = BindingHat
+		hat, mask, found := strings.Cut(value[1:], ".")
+		if !found {
+			return b, fmt.Errorf("invalid SDL binding %q", s)
+		}
+		if b.Index, err = strconv.Atoi(hat); err == nil {
+			b.HatMask, err = strconv.Atoi(mask)
+		}
+	default:
+		return b, fmt.Errorf("invalid SDL binding %q", s)
+	}
+	if err != nil || b.Index < 0 {
+		return b, fmt.Errorf("invalid SDL binding %q", s)
+	}
+	if b.Type != BindingAxis && (b.Range != AxisFull || b.Inverted) {
+		return b, fmt.Errorf("invalid SDL binding %q: only axes have a range or an inversion", s)
+	}
+	return b, nil
+}
+
+func (b Binding) String() string {
+	switch b.Type {
+	case BindingButton:
+		return "b" + strconv.Itoa(b.Index)
+	case BindingHat:
+		return fmt.Sprintf("h%d.%d", b.Index, b.HatMask)
+	}
+	s := "a" + strconv.Itoa(b.Index)
+	switch b.Range {
+	case AxisPositive:
+		s = "+" + s
+	case AxisNegative:
+		s = "-" + s
+	}
+	if b.Inverted {
+		s += "~"
+	}
+	return s
+}
+
+// Mapping is a line of gamecontrollerdb.txt: the GUID and name of a joystick, and the bindings of the gamepad inputs.
+type Mapping struct {
+	GUID     GUID
+	Name     string
+	Bindings map[string]Binding // by input name, e.g. InputA
+	Platform string             // e.g. "Linux", empty for all the platforms
+	Extra    map[string]string  // the other fields, e.g. "crc" or "hint"
+}
+
+// ParseMapping reads a line of gamecontrollerdb.txt.
+func ParseMapping(line string) (Mapping, error) {
+	fields := strings.Split(strings.TrimSpace(line), ",")
+	if len(fields) < 2 {
+		return Mapping{}, errors.New("invalid SDL mapping: expected a GUID and a name")
+	}
+	guid, err := ParseGUID(fields[0])
+	if err != nil {
+		return Mapping{}, err
+	}
+	mapping := Mapping{
+		GUID:     guid,
+		Name:     fields[1],
+		Bindings: map[string]Binding{},
+		Extra:    map[string]string{},
+	}
+	for _, field := range fields[2:] {
+		if field == "" {
+			continue
+		}
+		key, value, found := strings.Cut(field, ":")
+		if !found {
+			return Mapping{}, fmt.Errorf("invalid SDL mapping field %q", field)
+		}
+		switch {
+		case key == "platform":
+			mapping.Platform = value
+		case isInput(key):
+			binding, err := ParseBinding(value)
+			if err != nil {
+				return Mapping{}, fmt.Errorf("%s: %w", key, err)
+			}
+			mapping.Bindings[key] = binding
+		default:
+			mapping.Extra[key] = value
+		}
+	}
+	return mapping, nil
+}
+
+// String renders the mapping as a line of gamecontrollerdb.txt, the bindings being sorted by input name.
+func (m Mapping) String() string {
+	parts := []string{m.GUID.String(), m.Name}
+	parts = append(parts, sortedFields(m.Bindings, func(b Binding) string { return b.String() })...)
+	parts = append(parts, sortedFields(m.Extra, func(s string) string { return s })...)
+	if m.Platform != "" {
+		parts = append(parts, "platform:"+m.Platform)
+	}
+	return strings.Join(parts, ",") + ","
+}
+
+func sortedFields[T any](fields map[string]T, format func(T) string) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, key := range keys {
+		parts[i] = key + ":" + format(fields[key])
+	}
+	return parts
+}
+
+// isInput reports whether a field is a gamepad input, the output modifiers ("+leftx", "-lefty") included.
+func isInput(key string) bool {
+	key = strings.TrimLeft(key, "+-")
+	switch key {
+	case InputA, InputB, InputX, InputY, InputBack, InputGuide, InputStart,
+		InputLeftStick, InputRightStick, InputLeftShoulder, InputRightShoulder,
+		InputDPadUp, InputDPadDown, InputDPadLeft, InputDPadRight,
+		InputMisc1, InputPaddle1, InputPaddle2, InputPaddle3, InputPaddle4, InputTouchpad,
+		InputLeftX, InputLeftY, InputRightX, InputRightY, InputLeftTrigger, InputRightTrigger:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+package sdl
+
+import (
+	"testing"
+)
+
+const xbox360 = "030000005e0400008e02000014010000,Microsoft X-Box 360 pad,a:b0,b:b1,back:b6,dpdown:h0.4,dpleft:h0.8,dpright:h0.2,dpup:h0.1,guide:b8,leftshoulder:b4,leftstick:b9,lefttrigger:a2,leftx:a0,lefty:a1,rightshoulder:b5,rightstick:b10,righttrigger:a5,rightx:a3,righty:a4,start:b7,x:b2,y:b3,platform:Linux,"
+
+func TestParseBinding(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Binding
+	}{
+		{"b12", Binding{Type: BindingButton, Index: 12}},
+		{"a2", Binding{Type: BindingAxis, Index: 2}},
+		{"+a3", Binding{Type: BindingAxis, Index: 3, Range: AxisPositive}},
+		{"-a3", Binding{Type: BindingAxis, Index: 3, Range: AxisNegative}},
+		{"a1~", Binding{Type: BindingAxis, Index: 1, Inverted: true}},
+		{"h0.4", Binding{Type: BindingHat, Index: 0, HatMask: HatDown}},
+	}
+	for _, tt := range tests {
+		got, err := ParseBinding(tt.value)
+		if err != nil {
+			t.Errorf("ParseBinding(%q): %v", tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseBinding(%q) = %+v, want %+v", tt.value, got, tt.want)
+		}
+		if got.String() != tt.value {
+			t.Errorf("String() = %q, want %q", got.String(), tt.value)
+		}
+	}
+}
+
+func TestParseBinding_Invalid(t *testing.T) {
+	for _, value := range []string{"", "b", "bx", "c1", "h1", "h.2", "+b1", "b1~", "a-1"} {
+		if _, err := ParseBinding(value); err == nil {
+			t.Errorf("ParseBinding(%q) must fail", value)
+		}
+	}
+}
+
+func TestParseMapping(t *testing.T) {
+	mapping, err := ParseMapping(xbox360)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mapping.Name != "Microsoft X-Box 360 pad" || mapping.Platform != "Linux" {
+		t.Errorf("unexpected mapping %+v", mapping)
+	}
+	if mapping.Bindings[InputDPadUp] != (Binding{Type: BindingHat, HatMask: HatUp}) {
+		t.Errorf("dpup = %v", mapping.Bindings[InputDPadUp])
+	}
+	if got := mapping.String(); got != xbox360 {
+		t.Errorf("round trip:\n got %s\nwant %s", got, xbox360)
+	}
+}
+
+func TestParseMapping_Extra(t *testing.T) {
+	line := "03000000000000000000000000000000,Pad,+leftx:h0.2,a:b0,crc:1234,hint:!SDL_GAMECONTROLLER_USE_BUTTON_LABELS:=1,"
+	mapping, err := ParseMapping(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := mapping.Bindings["+leftx"]; !ok {
+		t.Error("output modifiers must be parsed as bindings")
+	}
+	if mapping.Extra["crc"] != "1234" || mapping.Extra["hint"] != "!SDL_GAMECONTROLLER_USE_BUTTON_LABELS:=1" {
+		t.Errorf("extra = %v", mapping.Extra)
+	}
+	if got := mapping.String(); got != line {
+		t.Errorf("round trip:\n got %s\nwant %s", got, line)
+	}
+}
+
+func TestParseMapping_Invalid(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"030000005e0400008e02000014010000",
+		"nope,Pad,a:b0,",
+		"030000005e0400008e02000014010000,Pad,a:",
+		"030000005e0400008e02000014010000,Pad,a",
+	} {
+		if _, err := ParseMapping(line); err == nil {
+			t.Errorf("ParseMapping(%q) must fail", line)
+		}
+	}
+}
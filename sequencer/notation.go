@@ -0,0 +1,331 @@
+package sequencer
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/jbdemonte/virtual-device/gamepad"
+)
+
+// Step is what is held during one or more frames.
+type Step struct {
+	Directions []gamepad.Button // ButtonUp, ButtonRight, ButtonDown and ButtonLeft, none being neutral
+	Buttons    []gamepad.Button
+	Frames     int
+}
+
+// Facing tells on which side the character faces, numpad notation being written for a character facing right.
+type Facing int
+
+const (
+	FacingRight Facing = iota
+	FacingLeft         // 4 and 6 are swapped
+)
+
+// DefaultButtons names the buttons of the usual notations, laid out as on a six-button pad:
+// punches on the left of the face buttons and R1, kicks on the right and R2.
+func DefaultButtons() map[string]gamepad.Button {
+	return map[string]gamepad.Button{
+		"LP": gamepad.ButtonWest,
+		"MP": gamepad.ButtonNorth,
+		"HP": gamepad.ButtonR1,
+		"LK": gamepad.ButtonSouth,
+		"MK": gamepad.ButtonEast,
+		"HK": gamepad.ButtonR2,
+		"P":  gamepad.ButtonWest,
+		"K":  gamepad.ButtonSouth,
+		"S":  gamepad.ButtonNorth,
+		"H":  gamepad.ButtonEast,
+		"HS": gamepad.ButtonEast,
+		"D":  gamepad.ButtonR1,
+	}
+}
+
+// numpad gives the directions of the numpad notation, for a character facing right.
+var numpad = map[byte][]gamepad.Button{
+	'1': {gamepad.ButtonDown, gamepad.ButtonLeft},
+	'2': {gamepad.ButtonDown},
+	'3': {gamepad.ButtonRight, gamepad.ButtonDown},
+	'4': {gamepad.ButtonLeft},
+	'5': {},
+	'6': {gamepad.ButtonRight},
+	'7': {gamepad.ButtonUp, gamepad.ButtonLeft},
+	'8': {gamepad.ButtonUp},
+	'9': {gamepad.ButtonUp, gamepad.ButtonRight},
+}
+
+// parser turns a notation into steps, see Parse.
+type parser struct {
+	input        string
+	pos          int
+	buttons      map[string]gamepad.Button
+	facing       Facing
+	motionFrames int
+	chargeFrames int
+
+	steps   []Step
+	held    map[gamepad.Button]bool // buttons held with [B] until ]B[
+	open    bool                    // the last step can receive the next buttons
+	tapped  bool                    // the last step already has buttons tapped
+	joining bool                    // the last token is a +
+}
+
+// Parse compiles a notation into steps:
+//   - 1 to 9: a direction, in numpad notation, held motionFrames; 5 is neutral
+//   - 4+6: several directions at once, resolved by the SOCD mode of the gamepad
+//   - [4]: a direction held chargeFrames
+//   - P, LP+LK: buttons pressed with the previous direction, or on a new step after a separator
+//   - [P] and ]P[: a button held from here, and released on a step of its own (negative edge)
+//   - (N): the previous step lasts N frames
+//   - .: a frame keeping the directions and the held buttons, releasing the tapped ones
+//   - , and >: separators, the next buttons start a new step
+//
+// Spaces are ignored.
+func Parse(notation string, buttons map[string]gamepad.Button, facing Facing, motionFrames, chargeFrames int) ([]Step, error) {
+	p := &parser{
+		input:        notation,
+		buttons:      buttons,
+		facing:       facing,
+		motionFrames: motionFrames,
+		chargeFrames: chargeFrames,
+		held:         map[gamepad.Button]bool{},
+	}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.steps, nil
+}
+
+func (p *parser) parse() error {
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		switch {
+		case c == ' ' || c == '\t':
+			p.pos++
+		case isDigit(c):
+			if err := p.direction(p.motionFrames); err != nil {
+				return err
+			}
+		case c == '+':
+			if len(p.steps) == 0 || p.joining {
+				return p.errorf("unexpected +")
+			}
+			p.joining = true
+			p.pos++
+			continue
+		case c == '[':
+			if err := p.hold(); err != nil {
+				return err
+			}
+		case c == ']':
+			if err := p.release(); err != nil {
+				return err
+			}
+		case c == '(':
+			if err := p.duration(); err != nil {
+				return err
+			}
+		case c == '.':
+			p.newStep(p.lastDirections(), 1)
+			p.open = false
+			p.pos++
+		case c == ',' || c == '>':
+			p.open = false
+			p.pos++
+		case isLetter(c):
+			if err := p.button(); err != nil {
+				return err
+			}
+		default:
+			return p.errorf("unexpected %q", c)
+		}
+		p.joining = false
+	}
+	if p.joining {
+		return p.errorf("unexpected end after +")
+	}
+	if len(p.held) > 0 {
+		return fmt.Errorf("buttons held until the end of %q", p.input)
+	}
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%s at %d in %q", fmt.Sprintf(format, args...), p.pos, p.input)
+}
+
+func isDigit(c byte) bool {
+	return c >= '1' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+}
+
+// directions returns the directions of a numpad digit, mirrored when facing left.
+func (p *parser) directions(c byte) []gamepad.Button {
+	directions := make([]gamepad.Button, 0, 2)
+	for _, direction := range numpad[c] {
+		if p.facing == FacingLeft {
+			switch direction {
+			case gamepad.ButtonLeft:
+				direction = gamepad.ButtonRight
+			case gamepad.ButtonRight:
+				direction = gamepad.ButtonLeft
+			}
+		}
+		directions = append(directions, direction)
+	}
+	sort.Slice(directions, func(i, j int) bool { return directions[i] < directions[j] })
+	return directions
+}
+
+// direction reads a digit, added to the last step after a + or starting a new one.
+func (p *parser) direction(frames int) error {
+	directions := p.directions(p.input[p.pos])
+	p.pos++
+	if p.joining {
+		last := &p.steps[len(p.steps)-1]
+		last.Directions = union(last.Directions, directions)
+		return nil
+	}
+	p.newStep(directions, frames)
+	p.open = true
+	return nil
+}
+
+// hold reads [d] or [B].
+func (p *parser) hold() error {
+	end := p.pos + 1
+	for end < len(p.input) && p.input[end] != ']' {
+		end++
+	}
+	if end == len(p.input) {
+		return p.errorf("unclosed [")
+	}
+	content := p.input[p.pos+1 : end]
+	if len(content) == 1 && isDigit(content[0]) {
+		p.pos++
+		if err := p.direction(p.chargeFrames); err != nil {
+			return err
+		}
+		p.pos = end + 1
+		return nil
+	}
+	button, ok := p.buttons[content]
+	if !ok {
+		return p.errorf("unknown button %q", content)
+	}
+	if !p.open {
+		p.newStep(p.lastDirections(), 1)
+		p.open = true
+	}
+	p.held[button] = true
+	last := &p.steps[len(p.steps)-1]
+	last.Buttons = union(last.Buttons, []gamepad.Button{button})
+	p.pos = end + 1
+	return nil
+}
+
+// release reads ]B[, a step of its own releasing a held button.
+func (p *parser) release() error {
+	end := p.pos + 1
+	for end < len(p.input) && p.input[end] != '[' {
+		end++
+	}
+	if end == len(p.input) {
+		return p.errorf("unclosed ]")
+	}
+	content := p.input[p.pos+1 : end]
+	button, ok := p.buttons[content]
+	if !ok {
+		return p.errorf("unknown button %q", content)
+	}
+	if !p.held[button] {
+		return p.errorf("%s released without being held", content)
+	}
+	delete(p.held, button)
+	p.newStep(p.lastDirections(), 1)
+	p.open = false
+	p.pos = end + 1
+	return nil
+}
+
+// duration reads (N), the number of frames of the last step.
+func (p *parser) duration() error {
+	end := p.pos + 1
+	for end < len(p.input) && p.input[end] != ')' {
+		end++
+	}
+	if end == len(p.input) {
+		return p.errorf("unclosed (")
+	}
+	frames, err := strconv.Atoi(p.input[p.pos+1 : end])
+	if err != nil || frames < 1 {
+		return p.errorf("invalid duration %q", p.input[p.pos+1:end])
+	}
+	if len(p.steps) == 0 {
+		return p.errorf("duration without step")
+	}
+	p.steps[len(p.steps)-1].Frames = frames
+	p.pos = end + 1
+	return nil
+}
+
+// button reads a button name, tapped with the last step or on a new one.
+func (p *parser) button() error {
+	end := p.pos
+	for end < len(p.input) && isLetter(p.input[end]) {
+		end++
+	}
+	name := p.input[p.pos:end]
+	button, ok := p.buttons[name]
+	if !ok {
+		return p.errorf("unknown button %q", name)
+	}
+	if !p.joining && (!p.open || p.tapped) {
+		p.newStep(p.lastDirections(), p.motionFrames)
+	}
+	last := &p.steps[len(p.steps)-1]
+	last.Buttons = union(last.Buttons, []gamepad.Button{button})
+	p.open = true
+	p.tapped = true
+	p.pos = end
+	return nil
+}
+
+// newStep appends a step with the directions and the held buttons.
+func (p *parser) newStep(directions []gamepad.Button, frames int) {
+	held := make([]gamepad.Button, 0, len(p.held))
+	for button := range p.held {
+		held = append(held, button)
+	}
+	sort.Slice(held, func(i, j int) bool { return held[i] < held[j] })
+	p.steps = append(p.steps, Step{Directions: directions, Buttons: held, Frames: frames})
+	p.tapped = false
+}
+
+func (p *parser) lastDirections() []gamepad.Button {
+	if len(p.steps) == 0 {
+		return []gamepad.Button{}
+	}
+	return append([]gamepad.Button{}, p.steps[len(p.steps)-1].Directions...)
+}
+
+// union returns the sorted buttons of both lists, without duplicates.
+func union(a, b []gamepad.Button) []gamepad.Button {
+	set := map[gamepad.Button]bool{}
+	for _, button := range a {
+		set[button] = true
+	}
+	for _, button := range b {
+		set[button] = true
+	}
+	result := make([]gamepad.Button, 0, len(set))
+	for button := range set {
+		result = append(result, button)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
@@ -0,0 +1,245 @@
+package sequencer
+
+import (
+	"context"
+	"time"
+
+	"github.com/jbdemonte/virtual-device/action"
+	"github.com/jbdemonte/virtual-device/clock"
+	"github.com/jbdemonte/virtual-device/gamepad"
+)
+
+// Sequencer plays fighting-game notations ("236P", "[4]6P") on a gamepad with a frame-accurate timing.
+type Sequencer interface {
+	// Parse compiles a notation into steps, without playing it.
+	Parse(notation string) ([]Step, error)
+
+	// Play plays a notation and returns once its last frame is over, everything it pressed being released.
+	Play(notation string) error
+	// PlayContext plays a notation, stopping and releasing what it pressed when ctx is done.
+	PlayContext(ctx context.Context, notation string) error
+	// PlayAsync plays a notation in the background, one progress step per frame.
+	PlayAsync(ctx context.Context, notation string) (action.Handle, error)
+	// PlaySteps plays already parsed steps.
+	PlaySteps(ctx context.Context, steps []Step) error
+
+	// FrameDuration returns the duration of a frame at the configured frame rate.
+	FrameDuration() time.Duration
+}
+
+// SequencerFactory configures and creates Sequencer instances.
+type SequencerFactory interface {
+	WithGamepad(gp gamepad.VirtualGamepad) SequencerFactory
+	WithFrameRate(fps float64) SequencerFactory
+	WithButtons(buttons map[string]gamepad.Button) SequencerFactory
+	WithFacing(facing Facing) SequencerFactory
+	WithMotionFrames(frames int) SequencerFactory
+	WithChargeFrames(frames int) SequencerFactory
+	WithClock(clock clock.Clock) SequencerFactory
+	Create() Sequencer
+}
+
+const (
+	// DefaultFrameRate is the frame rate of most fighting games.
+	DefaultFrameRate = 60
+	// DefaultMotionFrames is the number of frames a direction of a motion is held.
+	DefaultMotionFrames = 1
+	// DefaultChargeFrames is the number of frames a charge ([4]) is held, longer than the charge time of most games.
+	DefaultChargeFrames = 60
+)
+
+type sequencerFactory struct {
+	gamepad      gamepad.VirtualGamepad
+	fps          float64
+	buttons      map[string]gamepad.Button
+	facing       Facing
+	motionFrames int
+	chargeFrames int
+	clock        clock.Clock
+}
+
+// NewSequencerFactory returns a new factory for building sequencers.
+func NewSequencerFactory() SequencerFactory {
+	return &sequencerFactory{
+		fps:          DefaultFrameRate,
+		buttons:      DefaultButtons(),
+		motionFrames: DefaultMotionFrames,
+		chargeFrames: DefaultChargeFrames,
+	}
+}
+
+func (f *sequencerFactory) WithGamepad(gp gamepad.VirtualGamepad) SequencerFactory {
+	f.gamepad = gp
+	return f
+}
+
+func (f *sequencerFactory) WithFrameRate(fps float64) SequencerFactory {
+	f.fps = fps
+	return f
+}
+
+// WithButtons replaces the button names of the notation, DefaultButtons being used otherwise.
+func (f *sequencerFactory) WithButtons(buttons map[string]gamepad.Button) SequencerFactory {
+	f.buttons = buttons
+	return f
+}
+
+func (f *sequencerFactory) WithFacing(facing Facing) SequencerFactory {
+	f.facing = facing
+	return f
+}
+
+func (f *sequencerFactory) WithMotionFrames(frames int) SequencerFactory {
+	f.motionFrames = frames
+	return f
+}
+
+func (f *sequencerFactory) WithChargeFrames(frames int) SequencerFactory {
+	f.chargeFrames = frames
+	return f
+}
+
+func (f *sequencerFactory) WithClock(clock clock.Clock) SequencerFactory {
+	f.clock = clock
+	return f
+}
+
+// Create falls back to the defaults for a frame rate or a number of frames which is not positive.
+func (f *sequencerFactory) Create() Sequencer {
+	c := f.clock
+	if c == nil {
+		c = clock.New()
+	}
+	fps := f.fps
+	if !(fps > 0) {
+		fps = DefaultFrameRate
+	}
+	motionFrames := f.motionFrames
+	if motionFrames <= 0 {
+		motionFrames = DefaultMotionFrames
+	}
+	chargeFrames := f.chargeFrames
+	if chargeFrames <= 0 {
+		chargeFrames = DefaultChargeFrames
+	}
+	return &sequencer{
+		gamepad:      f.gamepad,
+		fps:          fps,
+		buttons:      f.buttons,
+		facing:       f.facing,
+		motionFrames: motionFrames,
+		chargeFrames: chargeFrames,
+		clock:        c,
+	}
+}
+
+type sequencer struct {
+	gamepad      gamepad.VirtualGamepad
+	fps          float64
+	buttons      map[string]gamepad.Button
+	facing       Facing
+	motionFrames int
+	chargeFrames int
+	clock        clock.Clock
+}
+
+func (s *sequencer) Parse(notation string) ([]Step, error) {
+	return Parse(notation, s.buttons, s.facing, s.motionFrames, s.chargeFrames)
+}
+
+func (s *sequencer) FrameDuration() time.Duration {
+	return s.framesDuration(1)
+}
+
+// framesDuration is computed from the start of the sequence, so that rounding errors do not add up.
+func (s *sequencer) framesDuration(frames int) time.Duration {
+	return time.Duration(float64(frames) * float64(time.Second) / s.fps)
+}
+
+func (s *sequencer) Play(notation string) error {
+	return s.PlayContext(context.Background(), notation)
+}
+
+func (s *sequencer) PlayContext(ctx context.Context, notation string) error {
+	steps, err := s.Parse(notation)
+	if err != nil {
+		return err
+	}
+	return s.play(ctx, steps, func() {})
+}
+
+func (s *sequencer) PlayAsync(ctx context.Context, notation string) (action.Handle, error) {
+	steps, err := s.Parse(notation)
+	if err != nil {
+		return nil, err
+	}
+	return action.Start(ctx, totalFrames(steps), func(ctx context.Context, step func()) error {
+		return s.play(ctx, steps, step)
+	}), nil
+}
+
+func (s *sequencer) PlaySteps(ctx context.Context, steps []Step) error {
+	return s.play(ctx, steps, func() {})
+}
+
+func totalFrames(steps []Step) int {
+	total := 0
+	for _, step := range steps {
+		total += step.Frames
+	}
+	return total
+}
+
+// play applies each step as a single report on the frame it starts, then waits for its last frame to end.
+// The deadlines are computed from the start, so a late report does not delay the next ones.
+// The directions and the buttons of the steps are released at the end, the other inputs are left untouched.
+func (s *sequencer) play(ctx context.Context, steps []Step, step func()) error {
+	owned := map[gamepad.Button]bool{
+		gamepad.ButtonUp:    true,
+		gamepad.ButtonRight: true,
+		gamepad.ButtonDown:  true,
+		gamepad.ButtonLeft:  true,
+	}
+	for _, item := range steps {
+		for _, button := range item.Buttons {
+			owned[button] = true
+		}
+	}
+	defer s.apply(owned, nil, nil)
+
+	start := s.clock.Now()
+	frame := 0
+	for _, item := range steps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		s.apply(owned, item.Directions, item.Buttons)
+		for i := 0; i < item.Frames; i++ {
+			frame++
+			if err := clock.SleepContext(ctx, s.clock, start.Add(s.framesDuration(frame)).Sub(s.clock.Now())); err != nil {
+				return err
+			}
+			step()
+		}
+	}
+	return nil
+}
+
+// apply sets the owned inputs of the gamepad, pressing the given directions and buttons and releasing the others.
+func (s *sequencer) apply(owned map[gamepad.Button]bool, directions, buttons []gamepad.Button) {
+	state := s.gamepad.State()
+	state.Hat = gamepad.Hat{}
+	for button := range owned {
+		delete(state.Buttons, button)
+	}
+	if state.Buttons == nil {
+		state.Buttons = map[gamepad.Button]bool{}
+	}
+	for _, button := range directions {
+		state.Buttons[button] = true
+	}
+	for _, button := range buttons {
+		state.Buttons[button] = true
+	}
+	s.gamepad.SetState(state)
+}
@@ -0,0 +1,217 @@
+package sequencer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jbdemonte/virtual-device/clock"
+	"github.com/jbdemonte/virtual-device/gamepad"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+var (
+	up    = gamepad.ButtonUp
+	right = gamepad.ButtonRight
+	down  = gamepad.ButtonDown
+	left  = gamepad.ButtonLeft
+	lp    = gamepad.ButtonWest
+	lk    = gamepad.ButtonSouth
+)
+
+func b(buttons ...gamepad.Button) []gamepad.Button {
+	return append([]gamepad.Button{}, buttons...)
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		notation string
+		facing   Facing
+		want     []Step
+	}{
+		{"236P", FacingRight, []Step{
+			{Directions: b(down), Buttons: b(), Frames: 1},
+			{Directions: b(right, down), Buttons: b(), Frames: 1},
+			{Directions: b(right), Buttons: b(lp), Frames: 1},
+		}},
+		{"236P", FacingLeft, []Step{
+			{Directions: b(down), Buttons: b(), Frames: 1},
+			{Directions: b(down, left), Buttons: b(), Frames: 1},
+			{Directions: b(left), Buttons: b(lp), Frames: 1},
+		}},
+		{"623 K", FacingRight, []Step{
+			{Directions: b(right), Buttons: b(), Frames: 1},
+			{Directions: b(down), Buttons: b(), Frames: 1},
+			{Directions: b(right, down), Buttons: b(lk), Frames: 1},
+		}},
+		{"[4]6P", FacingRight, []Step{
+			{Directions: b(left), Buttons: b(), Frames: 60},
+			{Directions: b(right), Buttons: b(lp), Frames: 1},
+		}},
+		{"[2](45)8LP+LK(3)", FacingRight, []Step{
+			{Directions: b(down), Buttons: b(), Frames: 45},
+			{Directions: b(up), Buttons: b(lk, lp), Frames: 3},
+		}},
+		{"[P]2]P[", FacingRight, []Step{
+			{Directions: b(), Buttons: b(lp), Frames: 1},
+			{Directions: b(down), Buttons: b(lp), Frames: 1},
+			{Directions: b(down), Buttons: b(), Frames: 1},
+		}},
+		{"4+6P.P", FacingRight, []Step{
+			{Directions: b(right, left), Buttons: b(lp), Frames: 1},
+			{Directions: b(right, left), Buttons: b(), Frames: 1},
+			{Directions: b(right, left), Buttons: b(lp), Frames: 1},
+		}},
+		{"2MK > 236P", FacingRight, []Step{
+			{Directions: b(down), Buttons: b(gamepad.ButtonEast), Frames: 1},
+			{Directions: b(down), Buttons: b(), Frames: 1},
+			{Directions: b(right, down), Buttons: b(), Frames: 1},
+			{Directions: b(right), Buttons: b(lp), Frames: 1},
+		}},
+	}
+	for _, tt := range tests {
+		steps, err := Parse(tt.notation, DefaultButtons(), tt.facing, DefaultMotionFrames, DefaultChargeFrames)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.notation, err)
+			continue
+		}
+		if !reflect.DeepEqual(steps, tt.want) {
+			t.Errorf("Parse(%q) =\n%+v\nwant\n%+v", tt.notation, steps, tt.want)
+		}
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, notation := range []string{"236X", "0P", "+P", "2++P", "2+", "[4", "]P[", "[P]2", "(3)", "2(0)", "2(", "6P?"} {
+		if _, err := Parse(notation, DefaultButtons(), FacingRight, 1, 60); err == nil {
+			t.Errorf("Parse(%q) should fail", notation)
+		}
+	}
+}
+
+func newTestSequencer(mock *vdtest.Device, fake *clock.Fake) Sequencer {
+	gp := gamepad.NewVirtualGamepadFactory().
+		WithDevice(mock).
+		WithDigital(gamepad.MappingDigital{
+			gamepad.ButtonWest:  linux.BTN_WEST,
+			gamepad.ButtonSouth: linux.BTN_SOUTH,
+		}).
+		WithDPad(gamepad.DPadHat).
+		WithSOCD(gamepad.SOCDNeutral).
+		Create()
+	return NewSequencerFactory().
+		WithGamepad(gp).
+		WithClock(fake).
+		Create()
+}
+
+// run plays the notation, advancing the fake clock one frame at a time.
+func run(t *testing.T, s Sequencer, fake *clock.Fake, notation string, frames int) {
+	t.Helper()
+	done := make(chan error)
+	go func() {
+		done <- s.Play(notation)
+	}()
+	for i := 0; i < frames; i++ {
+		fake.BlockUntil(1)
+		fake.Advance(s.FrameDuration() + 1)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSequencer_Motion(t *testing.T) {
+	start := time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	mock := vdtest.NewDevice().SetClock(fake)
+	s := newTestSequencer(mock, fake)
+
+	run(t, s, fake, "236P", 3)
+
+	frames := mock.Frames()
+	if len(frames) != 4 {
+		t.Fatalf("got %d frames, want 4: %v", len(frames), frames)
+	}
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_HAT0Y, 1))
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_HAT0X, 1))
+	mock.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_HAT0Y, 0), vdtest.Button(linux.BTN_WEST, 1))
+	mock.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_HAT0X, 0), vdtest.Button(linux.BTN_WEST, 0))
+
+	for i, frame := range frames {
+		want := s.FrameDuration() * time.Duration(i)
+		if got := frame[0].Time.Sub(start); got < want || got > want+time.Duration(i) {
+			t.Errorf("frame %d at %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestSequencer_Charge(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	mock := vdtest.NewDevice()
+	s := newTestSequencer(mock, fake)
+
+	run(t, s, fake, "[4](10)6P", 11)
+
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_HAT0X, -1))
+	mock.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_HAT0X, 1), vdtest.Button(linux.BTN_WEST, 1))
+	mock.ExpectFrameContaining(t, vdtest.Abs(linux.ABS_HAT0X, 0), vdtest.Button(linux.BTN_WEST, 0))
+	mock.ExpectNoFrame(t)
+}
+
+func TestSequencer_CancelReleases(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	mock := vdtest.NewDevice()
+	s := newTestSequencer(mock, fake)
+
+	handle, err := s.PlayAsync(context.Background(), "[P]2(30)]P[")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.BlockUntil(1)
+	fake.Advance(s.FrameDuration() + 1)
+	fake.BlockUntil(1)
+	handle.Cancel()
+	if err := handle.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait = %v, want context.Canceled", err)
+	}
+	if done, total := handle.Progress(); done != 1 || total != 32 {
+		t.Errorf("Progress = %d/%d, want 1/32", done, total)
+	}
+	state := mock.State()
+	if state.IsButtonPressed(linux.BTN_WEST) || state.AbsValue(linux.ABS_HAT0Y) != 0 {
+		t.Error("inputs must be released on cancel")
+	}
+}
+
+func TestSequencer_ParseError(t *testing.T) {
+	s := newTestSequencer(vdtest.NewDevice(), clock.NewFake(time.Now()))
+	if err := s.Play("236Z"); err == nil {
+		t.Error("Play should fail on an unknown button")
+	}
+	if _, err := s.PlayAsync(context.Background(), "[4"); err == nil {
+		t.Error("PlayAsync should fail on an unclosed charge")
+	}
+}
+
+func TestSequencerFactory_InvalidSettings(t *testing.T) {
+	s := NewSequencerFactory().
+		WithFrameRate(0).
+		WithMotionFrames(0).
+		WithChargeFrames(-1).
+		Create()
+	if got, want := s.FrameDuration(), NewSequencerFactory().Create().FrameDuration(); got != want {
+		t.Errorf("FrameDuration = %v, want %v", got, want)
+	}
+	steps, err := s.Parse("[4]6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := Parse("[4]6", DefaultButtons(), FacingRight, DefaultMotionFrames, DefaultChargeFrames)
+	if !reflect.DeepEqual(steps, want) {
+		t.Errorf("steps = %+v, want %+v", steps, want)
+	}
+}
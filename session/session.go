@@ -0,0 +1,217 @@
+package session
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jbdemonte/virtual-device/clock"
+	"github.com/jbdemonte/virtual-device/gamepad"
+)
+
+// MaxSlots is the number of players of a session.
+const MaxSlots = 8
+
+// Profile creates the gamepad of a slot, e.g. gamepad.NewXBox360.
+type Profile func() gamepad.VirtualGamepad
+
+// Client is the owner of a slot, receiving its feedback.
+// The callbacks are called from the goroutines of the gamepad and must not block.
+type Client struct {
+	Profile     Profile // nil for the profile of the slot
+	OnRumble    func(player int, rumble Rumble)
+	OnIndicator func(player int, indicator Indicator)
+}
+
+// Session allocates the player slots of a multi-controller session.
+type Session interface {
+	// Join allocates the first free slot to a client and plugs its gamepad.
+	Join(client Client) (Slot, error)
+	// Leave unplugs the gamepad of a player and frees its slot.
+	Leave(player int) error
+	// Slot returns the slot of a player, from 1 to the number of slots, nil when free.
+	Slot(player int) Slot
+	// Slots returns the allocated slots, by player.
+	Slots() []Slot
+	// Close frees all the slots.
+	Close() error
+
+	// ID identifies the session in the phys of its gamepads.
+	ID() string
+}
+
+// SessionFactory configures and creates Session instances.
+type SessionFactory interface {
+	WithSlots(count int) SessionFactory
+	WithProfile(profile Profile) SessionFactory
+	WithSlotProfile(player int, profile Profile) SessionFactory
+	WithClock(clock clock.Clock) SessionFactory
+	Create() Session
+}
+
+// ErrSessionFull is returned by Join when all the slots are allocated.
+var ErrSessionFull = errors.New("no free player slot")
+
+// sessionCounter makes each session ID unique within the process.
+var sessionCounter atomic.Int64
+
+type sessionFactory struct {
+	slots    int
+	profile  Profile
+	profiles map[int]Profile
+	clock    clock.Clock
+}
+
+// NewSessionFactory returns a new factory for building sessions of MaxSlots Xbox 360 pads.
+func NewSessionFactory() SessionFactory {
+	return &sessionFactory{
+		slots:    MaxSlots,
+		profile:  gamepad.NewXBox360,
+		profiles: map[int]Profile{},
+	}
+}
+
+// WithSlots sets the number of slots, up to MaxSlots.
+func (f *sessionFactory) WithSlots(count int) SessionFactory {
+	f.slots = count
+	return f
+}
+
+// WithProfile sets the profile of the slots without a profile of their own.
+func (f *sessionFactory) WithProfile(profile Profile) SessionFactory {
+	f.profile = profile
+	return f
+}
+
+// WithSlotProfile sets the profile of a slot, the profile of a client taking precedence.
+func (f *sessionFactory) WithSlotProfile(player int, profile Profile) SessionFactory {
+	f.profiles[player] = profile
+	return f
+}
+
+func (f *sessionFactory) WithClock(clock clock.Clock) SessionFactory {
+	f.clock = clock
+	return f
+}
+
+func (f *sessionFactory) Create() Session {
+	c := f.clock
+	if c == nil {
+		c = clock.New()
+	}
+	count := f.slots
+	if count <= 0 || count > MaxSlots {
+		count = MaxSlots
+	}
+	profiles := map[int]Profile{}
+	for player, profile := range f.profiles {
+		profiles[player] = profile
+	}
+	return &session{
+		id:       fmt.Sprintf("%d-%d", os.Getpid(), sessionCounter.Add(1)),
+		slots:    make([]*slot, count),
+		profile:  f.profile,
+		profiles: profiles,
+		clock:    c,
+	}
+}
+
+type session struct {
+	id       string
+	profile  Profile
+	profiles map[int]Profile
+	clock    clock.Clock
+
+	mu     sync.Mutex
+	slots  []*slot // by player - 1, nil when free
+	joined int     // makes the uniq of each joined gamepad unique within the session
+}
+
+func (s *session) ID() string {
+	return s.id
+}
+
+func (s *session) Join(client Client) (Slot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	index := -1
+	for i, sl := range s.slots {
+		if sl == nil {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		return nil, ErrSessionFull
+	}
+	player := index + 1
+
+	profile := client.Profile
+	if profile == nil {
+		profile = s.profiles[player]
+	}
+	if profile == nil {
+		profile = s.profile
+	}
+
+	s.joined++
+	uniq := fmt.Sprintf("%s-p%d-%d", s.id, player, s.joined)
+	sl := newSlot(player, uniq, profile(), client, s.clock)
+	if err := sl.Plug(); err != nil {
+		return nil, err
+	}
+	s.slots[index] = sl
+	return sl, nil
+}
+
+func (s *session) Leave(player int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if player < 1 || player > len(s.slots) || s.slots[player-1] == nil {
+		return fmt.Errorf("player %d has no slot", player)
+	}
+	err := s.slots[player-1].Unplug()
+	s.slots[player-1] = nil
+	return err
+}
+
+func (s *session) Slot(player int) Slot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if player < 1 || player > len(s.slots) || s.slots[player-1] == nil {
+		return nil
+	}
+	return s.slots[player-1]
+}
+
+func (s *session) Slots() []Slot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var slots []Slot
+	for _, sl := range s.slots {
+		if sl != nil {
+			slots = append(slots, sl)
+		}
+	}
+	return slots
+}
+
+func (s *session) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for i, sl := range s.slots {
+		if sl != nil {
+			errs = append(errs, sl.Unplug())
+			s.slots[i] = nil
+		}
+	}
+	return errors.Join(errs...)
+}
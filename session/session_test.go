@@ -0,0 +1,319 @@
+package session
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jbdemonte/virtual-device/clock"
+	"github.com/jbdemonte/virtual-device/gamepad"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+// devices records the device of each gamepad created by its profile.
+type devices struct {
+	mu      sync.Mutex
+	created []*vdtest.Device
+	fail    error
+}
+
+func (d *devices) profile() gamepad.VirtualGamepad {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	mock := vdtest.NewDevice().FailRegister(d.fail)
+	d.created = append(d.created, mock)
+	return gamepad.NewVirtualGamepadFactory().
+		WithDevice(mock).
+		WithDigital(gamepad.MappingDigital{gamepad.ButtonSouth: linux.BTN_SOUTH}).
+		Create()
+}
+
+func (d *devices) last() *vdtest.Device {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.created[len(d.created)-1]
+}
+
+// recorder records the feedback of a client.
+type recorder struct {
+	mu         sync.Mutex
+	rumbles    []Rumble
+	indicators []Indicator
+}
+
+func (r *recorder) client() Client {
+	return Client{
+		OnRumble: func(player int, rumble Rumble) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.rumbles = append(r.rumbles, rumble)
+		},
+		OnIndicator: func(player int, indicator Indicator) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.indicators = append(r.indicators, indicator)
+		},
+	}
+}
+
+func (r *recorder) lastRumble() Rumble {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.rumbles) == 0 {
+		return Rumble{}
+	}
+	return r.rumbles[len(r.rumbles)-1]
+}
+
+func TestSession_JoinAllocatesSlots(t *testing.T) {
+	d := &devices{}
+	s := NewSessionFactory().WithSlots(2).WithProfile(d.profile).Create()
+
+	r := &recorder{}
+	first, err := s.Join(r.client())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := s.Join(Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Join(Client{}); !errors.Is(err, ErrSessionFull) {
+		t.Fatalf("Join = %v, want ErrSessionFull", err)
+	}
+
+	if first.Player() != 1 || second.Player() != 2 {
+		t.Errorf("players = %d, %d, want 1, 2", first.Player(), second.Player())
+	}
+	if first.Phys() == second.Phys() || first.Uniq() == second.Uniq() {
+		t.Errorf("phys %q and %q, uniq %q and %q must be unique", first.Phys(), second.Phys(), first.Uniq(), second.Uniq())
+	}
+	if d.created[0].Phys != first.Phys() || !d.created[0].Registered() {
+		t.Errorf("device 1: phys %q, registered %v", d.created[0].Phys, d.created[0].Registered())
+	}
+	if len(d.created[0].FFEffects) != 1 || d.created[0].FFEffects[0] != linux.FF_RUMBLE {
+		t.Errorf("FFEffects = %v, want FF_RUMBLE", d.created[0].FFEffects)
+	}
+	if want := (Indicator{Player: 1, LEDs: 0b0001, Plugged: true}); len(r.indicators) != 1 || r.indicators[0] != want {
+		t.Errorf("indicators = %+v, want %+v", r.indicators, want)
+	}
+	if second.Indicator().LEDs != 0b0011 {
+		t.Errorf("LEDs of player 2 = %04b, want 0011", second.Indicator().LEDs)
+	}
+
+	// a freed slot is given to the next client, with a new identity
+	if err := s.Leave(1); err != nil {
+		t.Fatal(err)
+	}
+	if d.created[0].Registered() || s.Slot(1) != nil || len(s.Slots()) != 1 {
+		t.Error("Leave must unregister the gamepad and free the slot")
+	}
+	again, err := s.Join(Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Player() != 1 || again.Uniq() == first.Uniq() {
+		t.Errorf("player %d, uniq %q, want player 1 with a new uniq", again.Player(), again.Uniq())
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for i, mock := range d.created {
+		if mock.Registered() {
+			t.Errorf("device %d still registered after Close", i)
+		}
+	}
+}
+
+func TestSession_Profiles(t *testing.T) {
+	session, slot3, client := &devices{}, &devices{}, &devices{}
+	s := NewSessionFactory().
+		WithProfile(session.profile).
+		WithSlotProfile(3, slot3.profile).
+		Create()
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.Join(Client{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Leave(3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Join(Client{Profile: client.profile}); err != nil {
+		t.Fatal(err)
+	}
+	if len(session.created) != 2 || len(slot3.created) != 1 || len(client.created) != 1 {
+		t.Errorf("created %d, %d, %d gamepads, want 2, 1, 1", len(session.created), len(slot3.created), len(client.created))
+	}
+}
+
+func TestSession_JoinRegisterError(t *testing.T) {
+	d := &devices{fail: errors.New("no uinput")}
+	s := NewSessionFactory().WithProfile(d.profile).Create()
+	if _, err := s.Join(Client{}); err == nil {
+		t.Fatal("Join should fail")
+	}
+	if len(s.Slots()) != 0 {
+		t.Error("the slot must stay free")
+	}
+}
+
+func TestSlot_UnplugPlug(t *testing.T) {
+	d := &devices{}
+	s := NewSessionFactory().WithProfile(d.profile).Create()
+	r := &recorder{}
+	first, _ := s.Join(r.client())
+	second, _ := s.Join(Client{})
+
+	first.Gamepad().Press(gamepad.ButtonSouth)
+	second.Gamepad().Press(gamepad.ButtonSouth)
+	if err := first.Unplug(); err != nil {
+		t.Fatal(err)
+	}
+	if first.IsPlugged() || d.created[0].Registered() {
+		t.Error("the first gamepad must be unregistered")
+	}
+	if !second.IsPlugged() || !d.created[1].Registered() || !second.Gamepad().State().Buttons[gamepad.ButtonSouth] {
+		t.Error("the second gamepad must be left untouched")
+	}
+	if first.Gamepad().State().Buttons[gamepad.ButtonSouth] {
+		t.Error("the inputs of an unplugged gamepad must be released")
+	}
+
+	if err := first.Plug(); err != nil {
+		t.Fatal(err)
+	}
+	if !d.created[0].Registered() || s.Slot(1) != first {
+		t.Error("the first gamepad must be registered again in its slot")
+	}
+	want := []Indicator{{1, 0b0001, true}, {1, 0b0001, false}, {1, 0b0001, true}}
+	if len(r.indicators) != len(want) {
+		t.Fatalf("indicators = %+v, want %+v", r.indicators, want)
+	}
+	for i := range want {
+		if r.indicators[i] != want[i] {
+			t.Errorf("indicator %d = %+v, want %+v", i, r.indicators[i], want[i])
+		}
+	}
+}
+
+func rumbleEffect(strong, weak, length uint16) linux.FFEffect {
+	effect := linux.FFEffect{Type: uint16(linux.FF_RUMBLE), ID: -1, Replay: linux.FFReplay{Length: length}}
+	effect.SetRumble(linux.FFRumbleEffect{StrongMagnitude: strong, WeakMagnitude: weak})
+	return effect
+}
+
+func TestSlot_Rumble(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	d := &devices{}
+	s := NewSessionFactory().WithProfile(d.profile).WithClock(fake).Create()
+	r := &recorder{}
+	sl, _ := s.Join(r.client())
+	mock := d.last()
+
+	strong, err := mock.UploadEffect(rumbleEffect(0x8000, 0, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	weak, err := mock.UploadEffect(rumbleEffect(0, 0x4000, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.PlayEffect(strong, 1)
+	mock.PlayEffect(weak, 1)
+	if got, want := sl.Rumble(), (Rumble{Strong: 0x8000, Weak: 0x4000}); got != want || r.lastRumble() != want {
+		t.Errorf("rumble = %+v, notified %+v, want %+v", got, r.lastRumble(), want)
+	}
+
+	mock.SetGain(0x7fff)
+	if got := sl.Rumble(); got.Strong != 0x3fff || got.Weak != 0x1fff {
+		t.Errorf("rumble with half gain = %+v", got)
+	}
+	mock.SetGain(0xffff)
+
+	// the weak effect lasts 100 ms
+	fake.BlockUntil(1)
+	fake.Advance(100 * time.Millisecond)
+	deadline := time.Now().Add(time.Second)
+	for sl.Rumble() != (Rumble{Strong: 0x8000}) {
+		if time.Now().After(deadline) {
+			t.Fatalf("rumble = %+v, want the strong motor only", sl.Rumble())
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	mock.StopEffect(strong)
+	if got := r.lastRumble(); got != (Rumble{}) {
+		t.Errorf("rumble = %+v, want none", got)
+	}
+
+	// unplugging stops the rumble
+	mock.PlayEffect(strong, 1)
+	if err := sl.Unplug(); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.lastRumble(); got != (Rumble{}) {
+		t.Errorf("rumble after Unplug = %+v, want none", got)
+	}
+}
+
+func TestSlot_UnplugEndsReplays(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	d := &devices{}
+	s := NewSessionFactory().WithProfile(d.profile).WithClock(fake).Create()
+	sl, _ := s.Join(Client{})
+	mock := d.last()
+
+	id, err := mock.UploadEffect(rumbleEffect(0x8000, 0, 0xffff))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.PlayEffect(id, 1<<31-1) // forever, the length being saturated
+	fake.BlockUntil(1)
+	if err := sl.Unplug(); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for fake.Sleepers() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the replay must end with the plug")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestReplayLength(t *testing.T) {
+	if got := replayLength(100, 3); got != 300*time.Millisecond {
+		t.Errorf("replayLength(100, 3) = %v, want 300ms", got)
+	}
+	if got := replayLength(0xffff, 1<<31-1); got != math.MaxInt64 {
+		t.Errorf("replayLength(0xffff, max) = %v, want saturated", got)
+	}
+	if got := replayLength(100, 0); got != 0 {
+		t.Errorf("replayLength(100, 0) = %v, want 0", got)
+	}
+}
+
+func TestSlot_KeepsProfileEffects(t *testing.T) {
+	s := NewSessionFactory().WithProfile(func() gamepad.VirtualGamepad {
+		mock := vdtest.NewDevice()
+		mock.WithForceFeedback([]linux.FFEffectType{linux.FF_CONSTANT, linux.FF_GAIN}, 8)
+		return gamepad.NewVirtualGamepadFactory().WithDevice(mock).Create()
+	}).Create()
+	sl, err := s.Join(Client{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	effects, effectsMax := sl.Gamepad().Device().ForceFeedback()
+	want := []linux.FFEffectType{linux.FF_CONSTANT, linux.FF_GAIN, linux.FF_RUMBLE}
+	if !reflect.DeepEqual(effects, want) || effectsMax != 8 {
+		t.Errorf("ForceFeedback = %v, %d, want %v, 8", effects, effectsMax, want)
+	}
+}
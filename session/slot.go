@@ -0,0 +1,322 @@
+package session
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/clock"
+	"github.com/jbdemonte/virtual-device/gamepad"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// Slot is a player of a session with its gamepad.
+type Slot interface {
+	// Player returns the number of the player, from 1.
+	Player() int
+	Gamepad() gamepad.VirtualGamepad
+
+	// Phys returns the phys of the gamepad, unique to the slot.
+	Phys() string
+	// Uniq returns the identity of the gamepad, unique to each Join.
+	// uinput can not set the uniq of a node, which stays empty, so the identity is carried by the phys.
+	Uniq() string
+
+	// Indicator returns the player LEDs of the slot.
+	Indicator() Indicator
+	// Rumble returns the rumble requested by the applications.
+	Rumble() Rumble
+
+	// Plug registers the gamepad again after Unplug, the other slots being left untouched.
+	Plug() error
+	// Unplug releases the inputs of the gamepad and unregisters it, keeping the slot.
+	Unplug() error
+	IsPlugged() bool
+}
+
+// Indicator is the player indicator of a slot, as shown by the LEDs of a controller.
+type Indicator struct {
+	Player  int
+	LEDs    uint8 // 4 player LEDs, bit 0 being the first one
+	Plugged bool
+}
+
+// playerLEDs are the patterns of the Nintendo controllers (hid-nintendo), the first LED being bit 0.
+var playerLEDs = [MaxSlots]uint8{0b0001, 0b0011, 0b0111, 0b1111, 0b1001, 0b0101, 0b1101, 0b0110}
+
+// Rumble is the strength of the motors, from 0 to 0xffff, with the gain applied.
+type Rumble struct {
+	Strong uint16
+	Weak   uint16
+}
+
+type slot struct {
+	player  int
+	uniq    string
+	phys    string
+	gamepad gamepad.VirtualGamepad
+	client  Client
+	clock   clock.Clock
+
+	plugMu     sync.Mutex // serializes Plug and Unplug
+	mu         sync.Mutex
+	plugged    bool
+	unplugged  chan struct{} // closed by Unplug, ending the replays of the plug
+	effects    map[int16]linux.FFEffect
+	playing    map[int16]int // generation of the play of each effect
+	active     map[int16]bool
+	generation int
+	gain       uint16
+	rumble     Rumble
+}
+
+func newSlot(player int, uniq string, gp gamepad.VirtualGamepad, client Client, c clock.Clock) *slot {
+	sl := &slot{
+		player:  player,
+		uniq:    uniq,
+		phys:    "virtual-device-session-" + uniq + "/input0",
+		gamepad: gp,
+		client:  client,
+		clock:   c,
+		effects: map[int16]linux.FFEffect{},
+		playing: map[int16]int{},
+		active:  map[int16]bool{},
+		gain:    0xffff,
+	}
+	// the effects of the profile are kept, FF_RUMBLE being added for the rumble reports
+	effects, effectsMax := gp.Device().ForceFeedback()
+	if !slices.Contains(effects, linux.FF_RUMBLE) {
+		effects = append(slices.Clip(effects), linux.FF_RUMBLE)
+	}
+	gp.Device().
+		WithPhys(sl.phys).
+		WithForceFeedback(effects, effectsMax).
+		WithForceFeedbackHandler(sl.handleForceFeedback)
+	return sl
+}
+
+func (sl *slot) Player() int {
+	return sl.player
+}
+
+func (sl *slot) Gamepad() gamepad.VirtualGamepad {
+	return sl.gamepad
+}
+
+func (sl *slot) Phys() string {
+	return sl.phys
+}
+
+func (sl *slot) Uniq() string {
+	return sl.uniq
+}
+
+func (sl *slot) Indicator() Indicator {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	return sl.indicator()
+}
+
+func (sl *slot) indicator() Indicator {
+	return Indicator{Player: sl.player, LEDs: playerLEDs[sl.player-1], Plugged: sl.plugged}
+}
+
+func (sl *slot) Rumble() Rumble {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	return sl.rumble
+}
+
+func (sl *slot) IsPlugged() bool {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	return sl.plugged
+}
+
+func (sl *slot) Plug() error {
+	sl.plugMu.Lock()
+	defer sl.plugMu.Unlock()
+
+	if sl.IsPlugged() {
+		return nil
+	}
+	if err := sl.gamepad.Register(); err != nil {
+		return err
+	}
+	sl.mu.Lock()
+	sl.plugged = true
+	sl.unplugged = make(chan struct{})
+	indicator := sl.indicator()
+	sl.mu.Unlock()
+
+	sl.notifyIndicator(indicator)
+	return nil
+}
+
+// Unplug stops the rumble: the effects of the applications are lost with the node.
+// The gamepad is unregistered without holding mu, the force feedback requests being handled until its node is removed.
+func (sl *slot) Unplug() error {
+	sl.plugMu.Lock()
+	defer sl.plugMu.Unlock()
+
+	if !sl.IsPlugged() {
+		return nil
+	}
+	sl.gamepad.SetState(gamepad.GamepadState{})
+	err := sl.gamepad.Unregister()
+
+	sl.mu.Lock()
+	sl.plugged = false
+	close(sl.unplugged)
+	sl.effects = map[int16]linux.FFEffect{}
+	sl.playing = map[int16]int{}
+	sl.active = map[int16]bool{}
+	sl.gain = 0xffff
+	rumble, changed := sl.updateRumble()
+	indicator := sl.indicator()
+	sl.mu.Unlock()
+
+	if changed {
+		sl.notifyRumble(rumble)
+	}
+	sl.notifyIndicator(indicator)
+	return err
+}
+
+func (sl *slot) handleForceFeedback(event virtual_device.ForceFeedbackEvent) {
+	sl.mu.Lock()
+	switch event.Type {
+	case virtual_device.FFUpload:
+		if event.Effect.Type == uint16(linux.FF_RUMBLE) {
+			sl.effects[event.EffectID] = event.Effect
+		}
+	case virtual_device.FFErase:
+		delete(sl.effects, event.EffectID)
+		delete(sl.playing, event.EffectID)
+		delete(sl.active, event.EffectID)
+	case virtual_device.FFPlay:
+		sl.play(event.EffectID, event.Value)
+	case virtual_device.FFStop:
+		delete(sl.playing, event.EffectID)
+		delete(sl.active, event.EffectID)
+	case virtual_device.FFGain:
+		sl.gain = uint16(event.Value)
+	}
+	rumble, changed := sl.updateRumble()
+	sl.mu.Unlock()
+
+	if changed {
+		sl.notifyRumble(rumble)
+	}
+}
+
+// play starts an effect after its delay, and stops it once its repetitions are over.
+// uinput leaves the replay to the device: no stop request comes from the kernel when the length is over.
+func (sl *slot) play(id int16, count int32) {
+	effect, ok := sl.effects[id]
+	if !ok {
+		return
+	}
+	sl.generation++
+	generation := sl.generation
+	sl.playing[id] = generation
+
+	delay := time.Duration(effect.Replay.Delay) * time.Millisecond
+	length := replayLength(effect.Replay.Length, count)
+	if delay == 0 {
+		sl.active[id] = true
+	}
+	if delay > 0 || length > 0 {
+		go sl.replay(sl.unplugged, id, generation, delay, length)
+	}
+}
+
+// replayLength returns the length of count repetitions of an effect, saturated as a huge count means forever.
+func replayLength(length uint16, count int32) time.Duration {
+	once := time.Duration(length) * time.Millisecond
+	if once == 0 || count <= 0 {
+		return 0
+	}
+	if time.Duration(count) > math.MaxInt64/once {
+		return math.MaxInt64
+	}
+	return once * time.Duration(count)
+}
+
+// replay applies the delay and the length of an effect, until the gamepad is unplugged.
+func (sl *slot) replay(unplugged <-chan struct{}, id int16, generation int, delay, length time.Duration) {
+	if delay > 0 {
+		if !sl.wait(unplugged, delay) || !sl.update(id, generation, true) {
+			return
+		}
+	}
+	if length > 0 && sl.wait(unplugged, length) {
+		sl.update(id, generation, false)
+	}
+}
+
+// wait sleeps for d, reporting false when the gamepad is unplugged first.
+func (sl *slot) wait(unplugged <-chan struct{}, d time.Duration) bool {
+	timer := sl.clock.NewTimer(d)
+	select {
+	case <-timer.C():
+		return true
+	case <-unplugged:
+		timer.Stop()
+		return false
+	}
+}
+
+// update starts or stops an effect unless it has been played again, stopped or erased since, reporting whether it was.
+func (sl *slot) update(id int16, generation int, active bool) bool {
+	sl.mu.Lock()
+	if sl.playing[id] != generation {
+		sl.mu.Unlock()
+		return false
+	}
+	if active {
+		sl.active[id] = true
+	} else {
+		delete(sl.playing, id)
+		delete(sl.active, id)
+	}
+	rumble, changed := sl.updateRumble()
+	sl.mu.Unlock()
+
+	if changed {
+		sl.notifyRumble(rumble)
+	}
+	return true
+}
+
+// updateRumble sums the active effects, reporting whether the rumble changed.
+func (sl *slot) updateRumble() (Rumble, bool) {
+	var strong, weak uint32
+	for id := range sl.active {
+		effect := sl.effects[id]
+		rumble := effect.Rumble()
+		strong += uint32(rumble.StrongMagnitude)
+		weak += uint32(rumble.WeakMagnitude)
+	}
+	rumble := Rumble{
+		Strong: uint16(min(strong, 0xffff) * uint32(sl.gain) / 0xffff),
+		Weak:   uint16(min(weak, 0xffff) * uint32(sl.gain) / 0xffff),
+	}
+	changed := rumble != sl.rumble
+	sl.rumble = rumble
+	return rumble, changed
+}
+
+func (sl *slot) notifyRumble(rumble Rumble) {
+	if sl.client.OnRumble != nil {
+		sl.client.OnRumble(sl.player, rumble)
+	}
+}
+
+func (sl *slot) notifyIndicator(indicator Indicator) {
+	if sl.client.OnIndicator != nil {
+		sl.client.OnIndicator(sl.player, indicator)
+	}
+}
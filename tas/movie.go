@@ -0,0 +1,230 @@
+package tas
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/jbdemonte/virtual-device/gamepad"
+)
+
+// Column is a character of the buttons fields of a frame, '.' when the button is released.
+type Column struct {
+	Mnemonic byte
+	Button   gamepad.Button
+}
+
+// Axis identifies an analog value of a frame.
+type Axis int
+
+const (
+	AxisLeftX Axis = iota + 1
+	AxisLeftY
+	AxisRightX
+	AxisRightY
+	AxisL2 // from 0 to AxisMax
+	AxisR2 // from 0 to AxisMax
+)
+
+// Layout describes the fields of the frames: the buttons, one character each, and the analog values, in order.
+type Layout struct {
+	Buttons []Column
+	Axes    []Axis
+	AxisMax float64 // analog value of a stick pushed to the end, or of a fully pulled trigger
+}
+
+// DefaultLayout is a BizHawk-like layout: sticks from -127 to 127, then the D-pad, Select, Start, Mode,
+// the face buttons (North, West, East, South), the shoulders, the triggers and the stick clicks.
+//
+//	|  0,  0,  0,  0|UDLRsSHYXBAlrzZcC|
+func DefaultLayout() Layout {
+	return Layout{
+		Buttons: []Column{
+			{'U', gamepad.ButtonUp},
+			{'D', gamepad.ButtonDown},
+			{'L', gamepad.ButtonLeft},
+			{'R', gamepad.ButtonRight},
+			{'s', gamepad.ButtonSelect},
+			{'S', gamepad.ButtonStart},
+			{'H', gamepad.ButtonMode},
+			{'Y', gamepad.ButtonNorth},
+			{'X', gamepad.ButtonWest},
+			{'B', gamepad.ButtonEast},
+			{'A', gamepad.ButtonSouth},
+			{'l', gamepad.ButtonL1},
+			{'r', gamepad.ButtonR1},
+			{'z', gamepad.ButtonL2},
+			{'Z', gamepad.ButtonR2},
+			{'c', gamepad.ButtonL3},
+			{'C', gamepad.ButtonR3},
+		},
+		Axes:    []Axis{AxisLeftX, AxisLeftY, AxisRightX, AxisRightY},
+		AxisMax: 127,
+	}
+}
+
+// Movie is a list of frames, each one being the full state of the gamepad during a frame.
+type Movie struct {
+	Frames []gamepad.GamepadState
+}
+
+// Parse reads a movie, one frame per line. A frame is made of fields separated by '|', each one holding
+// comma-separated analog values, then buttons, a '.' or a space being a released button. As in BizHawk,
+// a field may hold both, as in "   0,   0,.......".
+// Empty lines, '#' comments, [sections] and LogKey lines are ignored, as in a BizHawk input log.
+func Parse(r io.Reader, layout Layout) (Movie, error) {
+	var movie Movie
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "LogKey:") {
+			continue
+		}
+		frame, err := parseFrame(text, layout)
+		if err != nil {
+			return Movie{}, fmt.Errorf("line %d: %w", line, err)
+		}
+		movie.Frames = append(movie.Frames, frame)
+	}
+	if err := scanner.Err(); err != nil {
+		return Movie{}, err
+	}
+	return movie, nil
+}
+
+func parseFrame(text string, layout Layout) (gamepad.GamepadState, error) {
+	text = strings.TrimLeft(text, " \t")
+	if !strings.HasPrefix(text, "|") {
+		return gamepad.GamepadState{}, fmt.Errorf("frame must start with |: %q", text)
+	}
+	// the spaces are kept, a space being a released button
+	fields := strings.Split(strings.TrimSuffix(strings.TrimRight(text[1:], "\r\n"), "|"), "|")
+
+	var values []float64
+	var buttons []byte
+	for _, field := range fields {
+		fieldValues, fieldButtons, err := splitField(field)
+		if err != nil {
+			return gamepad.GamepadState{}, err
+		}
+		values = append(values, fieldValues...)
+		buttons = append(buttons, fieldButtons...)
+	}
+	if len(values) != len(layout.Axes) {
+		return gamepad.GamepadState{}, fmt.Errorf("%d analog values, want %d", len(values), len(layout.Axes))
+	}
+	if len(buttons) != len(layout.Buttons) {
+		return gamepad.GamepadState{}, fmt.Errorf("%d buttons, want %d", len(buttons), len(layout.Buttons))
+	}
+
+	state := gamepad.GamepadState{Buttons: map[gamepad.Button]bool{}}
+	for i, c := range buttons {
+		if c != '.' && c != ' ' {
+			state.Buttons[layout.Buttons[i].Button] = true
+		}
+	}
+	for i, axis := range layout.Axes {
+		value := float32(values[i] / layout.AxisMax)
+		switch axis {
+		case AxisLeftX:
+			state.LeftStick.X = value
+		case AxisLeftY:
+			state.LeftStick.Y = value
+		case AxisRightX:
+			state.RightStick.X = value
+		case AxisRightY:
+			state.RightStick.Y = value
+		case AxisL2:
+			state.LeftTrigger = value
+		case AxisR2:
+			state.RightTrigger = value
+		}
+	}
+	return state, nil
+}
+
+// splitField separates the comma-separated analog values of a field from the buttons which follow them.
+func splitField(field string) (values []float64, buttons string, err error) {
+	items := strings.Split(field, ",")
+	if last := items[len(items)-1]; !isNumber(last) {
+		buttons = last
+		items = items[:len(items)-1]
+	}
+	for _, item := range items {
+		value, err := strconv.ParseFloat(strings.TrimSpace(item), 64)
+		if err != nil || !isNumber(item) {
+			return nil, "", fmt.Errorf("invalid analog value %q", item)
+		}
+		values = append(values, value)
+	}
+	return values, buttons, nil
+}
+
+// isNumber reports whether an item holds an analog value: digits, signs, a decimal point and spaces only.
+func isNumber(item string) bool {
+	for _, c := range item {
+		if !(c >= '0' && c <= '9' || c == '-' || c == '+' || c == '.' || c == ' ') {
+			return false
+		}
+	}
+	return strings.ContainsAny(item, "0123456789")
+}
+
+// Format writes a movie in the format read by Parse, the analog values being rounded.
+func Format(w io.Writer, movie Movie, layout Layout) error {
+	for _, frame := range movie.Frames {
+		var b strings.Builder
+		b.WriteByte('|')
+		if len(layout.Axes) > 0 {
+			for i, axis := range layout.Axes {
+				if i > 0 {
+					b.WriteByte(',')
+				}
+				fmt.Fprintf(&b, "%4d", int(roundHalfAway(float64(axisValue(frame, axis))*layout.AxisMax)))
+			}
+			b.WriteByte('|')
+		}
+		for _, column := range layout.Buttons {
+			if frame.Buttons[column.Button] {
+				b.WriteByte(column.Mnemonic)
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		b.WriteString("|\n")
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func axisValue(state gamepad.GamepadState, axis Axis) float32 {
+	switch axis {
+	case AxisLeftX:
+		return state.LeftStick.X
+	case AxisLeftY:
+		return state.LeftStick.Y
+	case AxisRightX:
+		return state.RightStick.X
+	case AxisRightY:
+		return state.RightStick.Y
+	case AxisL2:
+		return state.LeftTrigger
+	case AxisR2:
+		return state.RightTrigger
+	}
+	return 0
+}
+
+func roundHalfAway(value float64) float64 {
+	if value < 0 {
+		return -float64(int64(-value + 0.5))
+	}
+	return float64(int64(value + 0.5))
+}
@@ -0,0 +1,200 @@
+package tas
+
+import (
+	"context"
+	"runtime"
+	"time"
+
+	"github.com/jbdemonte/virtual-device/action"
+	"github.com/jbdemonte/virtual-device/clock"
+	"github.com/jbdemonte/virtual-device/gamepad"
+)
+
+// Player replays movies on a gamepad, one frame at a fixed frame rate.
+type Player interface {
+	// Play plays a movie and returns once its last frame is over, the gamepad being released.
+	Play(movie Movie) (Report, error)
+	// PlayContext plays a movie, stopping and releasing the gamepad when ctx is done.
+	PlayContext(ctx context.Context, movie Movie) (Report, error)
+	// PlayAsync plays a movie in the background, one progress step per frame.
+	// The report, unless nil, is filled once the handle is done.
+	PlayAsync(ctx context.Context, movie Movie, report *Report) action.Handle
+
+	// FrameDuration returns the duration of a frame at the configured frame rate.
+	FrameDuration() time.Duration
+}
+
+// PlayerFactory configures and creates Player instances.
+type PlayerFactory interface {
+	WithGamepad(gp gamepad.VirtualGamepad) PlayerFactory
+	WithFrameRate(fps float64) PlayerFactory
+	WithSpin(margin time.Duration) PlayerFactory
+	WithDropLateFrames(drop bool) PlayerFactory
+	WithClock(clock clock.Clock) PlayerFactory
+	Create() Player
+}
+
+// Report describes how close to its schedule a movie has been played.
+type Report struct {
+	Frames    int             // frames of the movie
+	Drifts    []time.Duration // delay of each frame after its deadline, 0 for the dropped frames
+	Late      []int           // frames applied, or dropped, once the next frame was due
+	Dropped   []int           // late frames not applied, see WithDropLateFrames
+	MaxDrift  time.Duration
+	MeanDrift time.Duration // mean drift of the applied frames
+	Duration  time.Duration // from the first frame to the release of the gamepad
+}
+
+// DefaultFrameRate is the frame rate of most consoles.
+const DefaultFrameRate = 60
+
+type playerFactory struct {
+	gamepad gamepad.VirtualGamepad
+	fps     float64
+	spin    time.Duration
+	drop    bool
+	clock   clock.Clock
+}
+
+// NewPlayerFactory returns a new factory for building players.
+func NewPlayerFactory() PlayerFactory {
+	return &playerFactory{fps: DefaultFrameRate}
+}
+
+func (f *playerFactory) WithGamepad(gp gamepad.VirtualGamepad) PlayerFactory {
+	f.gamepad = gp
+	return f
+}
+
+func (f *playerFactory) WithFrameRate(fps float64) PlayerFactory {
+	f.fps = fps
+	return f
+}
+
+// WithSpin sleeps until margin before each deadline, then busy-waits for it, trading CPU for accuracy.
+// It is meant for the real clock: a fake clock never moves while spinning.
+func (f *playerFactory) WithSpin(margin time.Duration) PlayerFactory {
+	f.spin = margin
+	return f
+}
+
+// WithDropLateFrames skips the frames reached once the next one is due, to catch up with the schedule,
+// instead of applying each frame of the movie.
+func (f *playerFactory) WithDropLateFrames(drop bool) PlayerFactory {
+	f.drop = drop
+	return f
+}
+
+func (f *playerFactory) WithClock(clock clock.Clock) PlayerFactory {
+	f.clock = clock
+	return f
+}
+
+// Create falls back to DefaultFrameRate for a frame rate which is not positive.
+func (f *playerFactory) Create() Player {
+	c := f.clock
+	if c == nil {
+		c = clock.New()
+	}
+	fps := f.fps
+	if !(fps > 0) {
+		fps = DefaultFrameRate
+	}
+	return &player{
+		gamepad: f.gamepad,
+		fps:     fps,
+		spin:    f.spin,
+		drop:    f.drop,
+		clock:   c,
+	}
+}
+
+type player struct {
+	gamepad gamepad.VirtualGamepad
+	fps     float64
+	spin    time.Duration
+	drop    bool
+	clock   clock.Clock
+}
+
+func (p *player) FrameDuration() time.Duration {
+	return p.framesDuration(1)
+}
+
+// framesDuration is computed from the start of the movie, so that rounding errors do not add up.
+func (p *player) framesDuration(frames int) time.Duration {
+	return time.Duration(float64(frames) * float64(time.Second) / p.fps)
+}
+
+func (p *player) Play(movie Movie) (Report, error) {
+	return p.PlayContext(context.Background(), movie)
+}
+
+func (p *player) PlayContext(ctx context.Context, movie Movie) (Report, error) {
+	var report Report
+	err := p.play(ctx, movie, &report, func() {})
+	return report, err
+}
+
+func (p *player) PlayAsync(ctx context.Context, movie Movie, report *Report) action.Handle {
+	if report == nil {
+		report = &Report{}
+	}
+	return action.Start(ctx, len(movie.Frames), func(ctx context.Context, step func()) error {
+		return p.play(ctx, movie, report, step)
+	})
+}
+
+// play applies each frame as a single report on its deadline, then releases the gamepad once the last frame is over.
+// The deadlines are computed from the start, so a late frame does not delay the next ones.
+func (p *player) play(ctx context.Context, movie Movie, report *Report, step func()) error {
+	*report = Report{Frames: len(movie.Frames), Drifts: make([]time.Duration, len(movie.Frames))}
+	start := p.clock.Now()
+	defer func() {
+		p.gamepad.SetState(gamepad.GamepadState{})
+		report.Duration = p.clock.Now().Sub(start)
+	}()
+
+	var total time.Duration
+	applied := 0
+	for i, frame := range movie.Frames {
+		if err := p.wait(ctx, start.Add(p.framesDuration(i))); err != nil {
+			return err
+		}
+		if !p.clock.Now().Before(start.Add(p.framesDuration(i + 1))) {
+			report.Late = append(report.Late, i)
+			if p.drop {
+				report.Dropped = append(report.Dropped, i)
+				step()
+				continue
+			}
+		}
+		p.gamepad.SetState(frame)
+		drift := p.clock.Now().Sub(start.Add(p.framesDuration(i)))
+		report.Drifts[i] = drift
+		if drift > report.MaxDrift {
+			report.MaxDrift = drift
+		}
+		total += drift
+		applied++
+		report.MeanDrift = total / time.Duration(applied)
+		step()
+	}
+	return p.wait(ctx, start.Add(p.framesDuration(len(movie.Frames))))
+}
+
+// wait sleeps until the deadline, busy-waiting its last part when a spin margin is set.
+func (p *player) wait(ctx context.Context, deadline time.Time) error {
+	if err := clock.SleepContext(ctx, p.clock, deadline.Sub(p.clock.Now())-p.spin); err != nil {
+		return err
+	}
+	if p.spin > 0 {
+		for p.clock.Now().Before(deadline) {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			runtime.Gosched()
+		}
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,251 @@
+package tas
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/clock"
+	"github.com/jbdemonte/virtual-device/gamepad"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+const movieText = `[Input]
+LogKey:#LX|LY|RX|RY|#Up|Down|...
+# comment
+|   0,   0,   0,   0|.................|
+| 127,-127,   0,   0|...R......A......|
+|   0,   0,   0,   0|U.....H..........|
+[/Input]
+`
+
+func TestParse(t *testing.T) {
+	movie, err := Parse(strings.NewReader(movieText), DefaultLayout())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(movie.Frames) != 3 {
+		t.Fatalf("got %d frames, want 3", len(movie.Frames))
+	}
+	if len(movie.Frames[0].Buttons) != 0 || movie.Frames[0].LeftStick != (gamepad.Stick{}) {
+		t.Errorf("frame 0 = %+v, want neutral", movie.Frames[0])
+	}
+	want := map[gamepad.Button]bool{gamepad.ButtonRight: true, gamepad.ButtonSouth: true}
+	if !reflect.DeepEqual(movie.Frames[1].Buttons, want) {
+		t.Errorf("frame 1 buttons = %v, want %v", movie.Frames[1].Buttons, want)
+	}
+	if movie.Frames[1].LeftStick != (gamepad.Stick{X: 1, Y: -1}) {
+		t.Errorf("frame 1 left stick = %+v", movie.Frames[1].LeftStick)
+	}
+	want = map[gamepad.Button]bool{gamepad.ButtonUp: true, gamepad.ButtonMode: true}
+	if !reflect.DeepEqual(movie.Frames[2].Buttons, want) {
+		t.Errorf("frame 2 buttons = %v, want %v", movie.Frames[2].Buttons, want)
+	}
+}
+
+func TestParse_LeadingReleasedButtons(t *testing.T) {
+	layout := Layout{Buttons: DefaultLayout().Buttons}
+	movie, err := Parse(strings.NewReader("|   R......A......|\n|                 |\n"), layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[gamepad.Button]bool{gamepad.ButtonRight: true, gamepad.ButtonSouth: true}
+	if len(movie.Frames) != 2 || !reflect.DeepEqual(movie.Frames[0].Buttons, want) || len(movie.Frames[1].Buttons) != 0 {
+		t.Errorf("frames = %+v, want Right and South then none", movie.Frames)
+	}
+}
+
+func TestParse_MixedField(t *testing.T) {
+	// BizHawk puts the analog values and the buttons in the same field
+	movie, err := Parse(strings.NewReader("|  64,-127,   0,   0,.............zZ..|\n"), DefaultLayout())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[gamepad.Button]bool{gamepad.ButtonL2: true, gamepad.ButtonR2: true}
+	if len(movie.Frames) != 1 || !reflect.DeepEqual(movie.Frames[0].Buttons, want) {
+		t.Fatalf("frames = %+v, want L2 and R2", movie.Frames)
+	}
+	if stick := movie.Frames[0].LeftStick; stick.X != 64.0/127 || stick.Y != -1 {
+		t.Errorf("left stick = %+v, want {64/127, -1}", stick)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, text := range []string{
+		"0,0,0,0|.................|",
+		"|0,0,0|.................|",
+		"|0,0,0,0|................|",
+		"|0,0,x0,0|.................|",
+	} {
+		if _, err := Parse(strings.NewReader(text), DefaultLayout()); err == nil {
+			t.Errorf("Parse(%q) should fail", text)
+		}
+	}
+}
+
+func TestFormat_RoundTrip(t *testing.T) {
+	layout := DefaultLayout()
+	layout.Axes = append(layout.Axes, AxisL2, AxisR2)
+	movie := Movie{Frames: []gamepad.GamepadState{
+		{Buttons: map[gamepad.Button]bool{gamepad.ButtonStart: true}, RightStick: gamepad.Stick{X: -1}, LeftTrigger: 1},
+		{Buttons: map[gamepad.Button]bool{}},
+	}}
+	var b strings.Builder
+	if err := Format(&b, movie, layout); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := Parse(strings.NewReader(b.String()), layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, movie) {
+		t.Errorf("round trip = %+v, want %+v\n%s", parsed, movie, b.String())
+	}
+}
+
+func newTestPlayer(mock *vdtest.Device, fake *clock.Fake, drop bool) Player {
+	gp := gamepad.NewVirtualGamepadFactory().
+		WithDevice(mock).
+		WithDigital(gamepad.MappingDigital{
+			gamepad.ButtonSouth: linux.BTN_SOUTH,
+		}).
+		WithLeftStick(gamepad.MappingStick{
+			X: virtual_device.AbsAxis{Axis: linux.ABS_X, Min: -32768, Max: 32767},
+			Y: virtual_device.AbsAxis{Axis: linux.ABS_Y, Min: -32768, Max: 32767},
+		}).
+		Create()
+	return NewPlayerFactory().
+		WithGamepad(gp).
+		WithFrameRate(50). // 20ms frames, without rounding errors
+		WithClock(fake).
+		WithDropLateFrames(drop).
+		Create()
+}
+
+func south(pressed bool) gamepad.GamepadState {
+	return gamepad.GamepadState{Buttons: map[gamepad.Button]bool{gamepad.ButtonSouth: pressed}}
+}
+
+func TestPlayer_Play(t *testing.T) {
+	start := time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	mock := vdtest.NewDevice().SetClock(fake)
+	p := newTestPlayer(mock, fake, false)
+
+	movie := Movie{Frames: []gamepad.GamepadState{
+		south(true),
+		{LeftStick: gamepad.Stick{X: 1}},
+		south(true),
+	}}
+	done := make(chan Report)
+	go func() {
+		report, err := p.Play(movie)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- report
+	}()
+	for i := 0; i < len(movie.Frames); i++ {
+		fake.BlockUntil(1)
+		fake.Advance(p.FrameDuration())
+	}
+	report := <-done
+
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_SOUTH, 1))
+	mock.ExpectFrameContaining(t, vdtest.Button(linux.BTN_SOUTH, 0), vdtest.Abs(linux.ABS_X, 32767))
+	mock.ExpectFrameContaining(t, vdtest.Button(linux.BTN_SOUTH, 1), vdtest.Abs(linux.ABS_X, 0))
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_SOUTH, 0))
+	mock.ExpectNoFrame(t)
+
+	if report.Frames != 3 || report.MaxDrift != 0 || len(report.Late) != 0 {
+		t.Errorf("report = %+v, want 3 frames on time", report)
+	}
+	if report.Duration != 3*p.FrameDuration() {
+		t.Errorf("Duration = %v, want %v", report.Duration, 3*p.FrameDuration())
+	}
+}
+
+func TestPlayer_LateFrames(t *testing.T) {
+	for _, drop := range []bool{false, true} {
+		fake := clock.NewFake(time.Now())
+		mock := vdtest.NewDevice()
+		p := newTestPlayer(mock, fake, drop)
+		frame := p.FrameDuration()
+
+		movie := Movie{Frames: []gamepad.GamepadState{south(true), south(false), south(true), south(false)}}
+		var report Report
+		handle := p.PlayAsync(context.Background(), movie, &report)
+		fake.BlockUntil(1)
+		fake.Advance(frame * 5 / 2)
+		for i := 0; i < 2; i++ {
+			fake.BlockUntil(1)
+			fake.Advance(frame)
+		}
+		if err := handle.Wait(); err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(report.Late, []int{1}) {
+			t.Errorf("drop=%v: Late = %v, want [1]", drop, report.Late)
+		}
+		if drop {
+			if !reflect.DeepEqual(report.Dropped, []int{1}) {
+				t.Errorf("Dropped = %v, want [1]", report.Dropped)
+			}
+			if report.Drifts[2] != frame/2 || report.MaxDrift != frame/2 {
+				t.Errorf("report = %+v, want a half frame drift", report)
+			}
+			continue
+		}
+		if len(report.Dropped) != 0 || report.MaxDrift != frame*3/2 {
+			t.Errorf("report = %+v, want a drift of 1.5 frame", report)
+		}
+		if done, total := handle.Progress(); done != 4 || total != 4 {
+			t.Errorf("Progress = %d/%d, want 4/4", done, total)
+		}
+	}
+}
+
+func TestPlayer_PlayAsyncWithoutReport(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	p := newTestPlayer(vdtest.NewDevice(), fake, false)
+
+	handle := p.PlayAsync(context.Background(), Movie{Frames: []gamepad.GamepadState{south(true)}}, nil)
+	fake.BlockUntil(1)
+	fake.Advance(p.FrameDuration())
+	if err := handle.Wait(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlayerFactory_InvalidFrameRate(t *testing.T) {
+	want := NewPlayerFactory().Create().FrameDuration()
+	for _, fps := range []float64{0, -60, math.NaN()} {
+		if got := NewPlayerFactory().WithFrameRate(fps).Create().FrameDuration(); got != want {
+			t.Errorf("FrameDuration at %v fps = %v, want %v", fps, got, want)
+		}
+	}
+}
+
+func TestPlayer_CancelReleases(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	mock := vdtest.NewDevice()
+	p := newTestPlayer(mock, fake, false)
+
+	var report Report
+	handle := p.PlayAsync(context.Background(), Movie{Frames: []gamepad.GamepadState{south(true), south(true)}}, &report)
+	fake.BlockUntil(1)
+	handle.Cancel()
+	if err := handle.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait = %v, want context.Canceled", err)
+	}
+	if mock.State().IsButtonPressed(linux.BTN_SOUTH) {
+		t.Error("the gamepad must be released on cancel")
+	}
+}
@@ -8,8 +8,20 @@ import (
 )
 
 // original function taken from: https://github.com/tianon/debian-golang-pty/blob/master/ioctl.go
+// The descriptor is reached through SyscallConn, as Fd() would switch the file to blocking mode
+// and a pending Read of the force feedback requests could no longer be interrupted by Close.
 func ioctl(deviceFile *os.File, cmd, arg uintptr) error {
-	_, _, errorCode := syscall.Syscall(syscall.SYS_IOCTL, deviceFile.Fd(), cmd, arg)
+	conn, err := deviceFile.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var errorCode syscall.Errno
+	err = conn.Control(func(fd uintptr) {
+		_, _, errorCode = syscall.Syscall(syscall.SYS_IOCTL, fd, cmd, arg)
+	})
+	if err != nil {
+		return err
+	}
 	if errorCode != 0 {
 		return errorCode
 	}
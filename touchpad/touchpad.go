@@ -1,9 +1,12 @@
 package touchpad
 
 import (
+	"context"
 	"time"
 
 	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/action"
+	"github.com/jbdemonte/virtual-device/clock"
 	"github.com/jbdemonte/virtual-device/linux"
 )
 
@@ -31,6 +34,11 @@ type VirtualTouchpad interface {
 	Click(btn linux.Button)
 	DoubleClick(btn linux.Button)
 
+	ClickContext(ctx context.Context, btn linux.Button) error
+	DoubleClickContext(ctx context.Context, btn linux.Button) error
+	ClickAsync(ctx context.Context, btn linux.Button) action.Handle
+	DoubleClickAsync(ctx context.Context, btn linux.Button) action.Handle
+
 	ClickLeft()
 	ClickRight()
 
@@ -40,6 +48,7 @@ type VirtualTouchpad interface {
 	Send(evType, code uint16, value int32)
 
 	EventPath() string
+	DeviceInfo() (virtual_device.DeviceInfo, error)
 }
 
 // VirtualTouchpadFactory configures and creates VirtualTouchpad instances.
@@ -51,6 +60,7 @@ type VirtualTouchpadFactory interface {
 	WithButtons(buttons []linux.Button) VirtualTouchpadFactory
 	WithProperties(properties []linux.InputProp) VirtualTouchpadFactory
 	WithLegacyMultitouch() VirtualTouchpadFactory
+	WithClock(clock clock.Clock) VirtualTouchpadFactory
 	Create() VirtualTouchpad
 }
 
@@ -70,6 +80,7 @@ type virtualTouchpadFactory struct {
 	buttons          []linux.Button
 	properties       []linux.InputProp
 	protocolA        bool
+	clock            clock.Clock
 }
 
 func (f *virtualTouchpadFactory) WithDevice(device virtual_device.VirtualDevice) VirtualTouchpadFactory {
@@ -107,6 +118,11 @@ func (f *virtualTouchpadFactory) WithLegacyMultitouch() VirtualTouchpadFactory {
 	return f
 }
 
+func (f *virtualTouchpadFactory) WithClock(clock clock.Clock) VirtualTouchpadFactory {
+	f.clock = clock
+	return f
+}
+
 func (f *virtualTouchpadFactory) Create() VirtualTouchpad {
 	clickDelay := f.clickDelay
 	if clickDelay < 0 {
@@ -118,6 +134,11 @@ func (f *virtualTouchpadFactory) Create() VirtualTouchpad {
 		doubleClickDelay = 250
 	}
 
+	c := f.clock
+	if c == nil {
+		c = clock.New()
+	}
+
 	f.device.WithAbsAxes(f.axes)
 	f.device.WithButtons(f.buttons)
 	f.device.WithProperties(f.properties)
@@ -135,6 +156,7 @@ func (f *virtualTouchpadFactory) Create() VirtualTouchpad {
 		axes:             axes,
 		currentSlots:     map[int]bool{},
 		protocolA:        f.protocolA,
+		clock:            c,
 	}
 }
 
@@ -146,6 +168,7 @@ type virtualTouchpad struct {
 	currentSlots     map[int]bool
 	fingerCount      int
 	protocolA        bool
+	clock            clock.Clock
 }
 
 func (vt *virtualTouchpad) Register() error {
@@ -280,15 +303,60 @@ func (vt *virtualTouchpad) ReleaseButton(button linux.Button) {
 }
 
 func (vt *virtualTouchpad) Click(btn linux.Button) {
+	_ = vt.ClickContext(context.Background(), btn)
+}
+
+func (vt *virtualTouchpad) DoubleClick(btn linux.Button) {
+	_ = vt.DoubleClickContext(context.Background(), btn)
+}
+
+// ClickContext clicks a button, releasing it right away if ctx is done while it is held.
+func (vt *virtualTouchpad) ClickContext(ctx context.Context, btn linux.Button) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	vt.PressButton(btn)
-	time.Sleep(time.Millisecond * time.Duration(vt.clickDelay))
+	err := clock.SleepContext(ctx, vt.clock, time.Millisecond*time.Duration(vt.clickDelay))
 	vt.ReleaseButton(btn)
+	return err
 }
 
-func (vt *virtualTouchpad) DoubleClick(btn linux.Button) {
-	vt.Click(btn)
-	time.Sleep(time.Millisecond * time.Duration(vt.doubleClickDelay))
-	vt.Click(btn)
+// DoubleClickContext double-clicks a button, stopping as soon as ctx is done.
+func (vt *virtualTouchpad) DoubleClickContext(ctx context.Context, btn linux.Button) error {
+	return vt.doubleClick(ctx, btn, func() {})
+}
+
+// ClickAsync clicks a button in the background.
+func (vt *virtualTouchpad) ClickAsync(ctx context.Context, btn linux.Button) action.Handle {
+	return action.Start(ctx, 1, func(ctx context.Context, step func()) error {
+		if err := vt.ClickContext(ctx, btn); err != nil {
+			return err
+		}
+		step()
+		return nil
+	})
+}
+
+// DoubleClickAsync double-clicks a button in the background, one progress step per click.
+func (vt *virtualTouchpad) DoubleClickAsync(ctx context.Context, btn linux.Button) action.Handle {
+	return action.Start(ctx, 2, func(ctx context.Context, step func()) error {
+		return vt.doubleClick(ctx, btn, step)
+	})
+}
+
+func (vt *virtualTouchpad) doubleClick(ctx context.Context, btn linux.Button, step func()) error {
+	if err := vt.ClickContext(ctx, btn); err != nil {
+		return err
+	}
+	step()
+	if err := clock.SleepContext(ctx, vt.clock, time.Millisecond*time.Duration(vt.doubleClickDelay)); err != nil {
+		return err
+	}
+	if err := vt.ClickContext(ctx, btn); err != nil {
+		return err
+	}
+	step()
+	return nil
 }
 
 func (vt *virtualTouchpad) ClickLeft() {
@@ -314,3 +382,7 @@ func (vt *virtualTouchpad) Send(evType, code uint16, value int32) {
 func (vt *virtualTouchpad) EventPath() string {
 	return vt.device.EventPath()
 }
+
+func (vt *virtualTouchpad) DeviceInfo() (virtual_device.DeviceInfo, error) {
+	return vt.device.DeviceInfo()
+}
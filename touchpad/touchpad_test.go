@@ -1,14 +1,16 @@
 package touchpad
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	virtual_device "github.com/jbdemonte/virtual-device"
-	"github.com/jbdemonte/virtual-device/internal/testutil"
 	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
 )
 
-func newTestTouchpad(mock *testutil.MockDevice) VirtualTouchpad {
+func newTestTouchpad(mock *vdtest.Device) VirtualTouchpad {
 	return NewVirtualTouchpadFactory().
 		WithDevice(mock).
 		WithClickDelay(0).
@@ -29,7 +31,7 @@ func newTestTouchpad(mock *testutil.MockDevice) VirtualTouchpad {
 }
 
 func TestTouchpad_AssignSlotIfNeeded_NoDuplicates(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	tp := newTestTouchpad(mock).(*virtualTouchpad)
 
 	slots := []TouchSlot{
@@ -43,7 +45,7 @@ func TestTouchpad_AssignSlotIfNeeded_NoDuplicates(t *testing.T) {
 }
 
 func TestTouchpad_AssignSlotIfNeeded_DuplicateSlots(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	tp := newTestTouchpad(mock).(*virtualTouchpad)
 
 	slots := []TouchSlot{
@@ -57,7 +59,7 @@ func TestTouchpad_AssignSlotIfNeeded_DuplicateSlots(t *testing.T) {
 }
 
 func TestTouchpad_MultiTouchB_SingleFingerPress(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	tp := newTestTouchpad(mock)
 
 	slots := tp.MultiTouch([]TouchSlot{
@@ -93,7 +95,7 @@ func TestTouchpad_MultiTouchB_SingleFingerPress(t *testing.T) {
 }
 
 func TestTouchpad_MultiTouchB_FingerRelease(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	tp := newTestTouchpad(mock)
 
 	// Press finger
@@ -121,7 +123,7 @@ func TestTouchpad_MultiTouchB_FingerRelease(t *testing.T) {
 }
 
 func TestTouchpad_MultiTouchB_FingerCountToggle(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	tp := newTestTouchpad(mock)
 
 	// Press 1 finger
@@ -169,7 +171,7 @@ func TestTouchpad_MultiTouchB_FingerCountToggle(t *testing.T) {
 }
 
 func TestTouchpad_Touch(t *testing.T) {
-	mock := testutil.NewMockDevice()
+	mock := vdtest.NewDevice()
 	tp := newTestTouchpad(mock)
 
 	tp.Touch(0.5, 0.5, 0.5)
@@ -189,3 +191,30 @@ func TestTouchpad_Touch(t *testing.T) {
 		t.Errorf("event[2] = %+v, want ABS_PRESSURE", events[2])
 	}
 }
+
+func TestTouchpad_DoubleClickContext_Cancelled(t *testing.T) {
+	mock := vdtest.NewDevice()
+	tp := newTestTouchpad(mock)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := tp.DoubleClickContext(ctx, linux.BTN_LEFT); !errors.Is(err, context.Canceled) {
+		t.Fatalf("DoubleClickContext = %v, want context.Canceled", err)
+	}
+	mock.ExpectNoFrame(t)
+}
+
+func TestTouchpad_DoubleClickAsync(t *testing.T) {
+	mock := vdtest.NewDevice()
+	tp := newTestTouchpad(mock)
+
+	h := tp.DoubleClickAsync(context.Background(), linux.BTN_LEFT)
+	if err := h.Wait(); err != nil {
+		t.Fatalf("Wait = %v, want nil", err)
+	}
+	if done, total := h.Progress(); done != 2 || total != 2 {
+		t.Errorf("Progress = %d/%d, want 2/2", done, total)
+	}
+	mock.ExpectButtonTap(t, linux.BTN_LEFT)
+	mock.ExpectButtonTap(t, linux.BTN_LEFT)
+}
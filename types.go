@@ -61,18 +61,25 @@ type Config struct {
 	leds         []linux.Led
 	properties   []linux.InputProp
 	miscEvents   []linux.MiscEvent
+	switches     []linux.SwitchEvent
+	ffEffects    []linux.FFEffectType
+	ffEffectsMax uint32
+	ffHandler    ForceFeedbackHandler
 }
 
 type virtualDevice struct {
 	fd           *os.File
 	path         string
 	eventPath    string
+	sysName      string
 	mode         os.FileMode
 	queueLen     int
 	name         string
+	phys         string
 	id           linux.InputID
 	config       Config
 	isRegistered *utils.AtomicBool
 	queue        chan *linux.InputEvent
 	pullDone     chan struct{}
+	listenDone   chan struct{}
 }
@@ -0,0 +1,404 @@
+package vdtest
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/clock"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// Event records a single input event sent to a Device.
+// Time is read from the device clock and is ignored by the Expect* assertions.
+type Event struct {
+	EvType uint16
+	Code   uint16
+	Value  int32
+	Time   time.Time
+}
+
+// Equal reports whether both events have the same type, code and value.
+func (e Event) Equal(other Event) bool {
+	return e.EvType == other.EvType && e.Code == other.Code && e.Value == other.Value
+}
+
+// Device is an in-memory VirtualDevice recording every event it receives.
+// It does not need /dev/uinput, so code built on VirtualDevice can be unit-tested anywhere.
+type Device struct {
+	mu         sync.Mutex
+	events     []Event
+	cursor     int // index of the first event not consumed by an Expect* assertion
+	state      *State
+	registered bool
+	clock      clock.Clock
+
+	registerErr   error
+	unregisterErr error
+	writeErr      error
+	writeBudget   int // remaining successful writes before writeErr, -1 when unlimited
+	dropped       []Event
+
+	Path         string
+	Mode         os.FileMode
+	QueueLen     int
+	BusType      linux.BusType
+	Vendor       uint16
+	Product      uint16
+	Version      uint16
+	Name         string
+	Phys         string
+	Keys         []linux.Key
+	Buttons      []linux.Button
+	AbsAxes      []virtual_device.AbsAxis
+	RelAxes      []linux.RelativeAxis
+	RepeatDelay  int32
+	RepeatPeriod int32
+	LEDs         []linux.Led
+	Properties   []linux.InputProp
+	MiscEvents   []linux.MiscEvent
+	Switches     []linux.SwitchEvent
+	FFEffects    []linux.FFEffectType
+	FFEffectsMax uint32
+
+	ffHandler virtual_device.ForceFeedbackHandler
+	ffLoaded  map[int16]bool
+}
+
+// NewDevice returns a new recording Device ready for use in tests.
+func NewDevice() *Device {
+	return &Device{
+		state:       newState(),
+		writeBudget: -1,
+		clock:       clock.New(),
+	}
+}
+
+// SetClock sets the clock used to timestamp the recorded events,
+// typically the clock.Fake also given to the factory under test.
+func (d *Device) SetClock(clock clock.Clock) *Device {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.clock = clock
+	return d
+}
+
+// Events returns a copy of all the recorded events.
+func (d *Device) Events() []Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	cp := make([]Event, len(d.events))
+	copy(cp, d.events)
+	return cp
+}
+
+// Reset forgets the recorded events, the mirrored state and the failures.
+func (d *Device) Reset() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.events = nil
+	d.cursor = 0
+	d.dropped = nil
+	d.state = newState()
+}
+
+// FailRegister makes Register return err (nil restores the default behavior).
+func (d *Device) FailRegister(err error) *Device {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.registerErr = err
+	return d
+}
+
+// FailUnregister makes Unregister return err (nil restores the default behavior).
+func (d *Device) FailUnregister(err error) *Device {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.unregisterErr = err
+	return d
+}
+
+// FailWritesAfter lets count events through, then drops the following ones the way the real device
+// does when writing to uinput fails. A negative count disables the failure.
+func (d *Device) FailWritesAfter(count int, err error) *Device {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if count < 0 {
+		d.writeBudget = -1
+		d.writeErr = nil
+		return d
+	}
+	if err == nil {
+		err = errors.New("write failed")
+	}
+	d.writeBudget = count
+	d.writeErr = err
+	return d
+}
+
+// Dropped returns the events rejected because of FailWritesAfter.
+func (d *Device) Dropped() []Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	cp := make([]Event, len(d.dropped))
+	copy(cp, d.dropped)
+	return cp
+}
+
+// WriteError returns the error configured with FailWritesAfter once it has been triggered.
+func (d *Device) WriteError() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.dropped) == 0 {
+		return nil
+	}
+	return d.writeErr
+}
+
+// Registered reports whether Register succeeded and Unregister was not called since.
+func (d *Device) Registered() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.registered
+}
+
+func (d *Device) record(evType, code uint16, value int32) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	event := Event{EvType: evType, Code: code, Value: value, Time: d.clock.Now()}
+	if d.writeBudget == 0 {
+		d.dropped = append(d.dropped, event)
+		return
+	}
+	if d.writeBudget > 0 {
+		d.writeBudget--
+	}
+	d.events = append(d.events, event)
+	d.state.apply(event)
+}
+
+func (d *Device) WithPath(path string) virtual_device.VirtualDevice {
+	d.Path = path
+	return d
+}
+
+func (d *Device) WithMode(mode os.FileMode) virtual_device.VirtualDevice {
+	d.Mode = mode
+	return d
+}
+
+func (d *Device) WithQueueLen(queueLen int) virtual_device.VirtualDevice {
+	d.QueueLen = queueLen
+	return d
+}
+
+func (d *Device) WithBusType(busType linux.BusType) virtual_device.VirtualDevice {
+	d.BusType = busType
+	return d
+}
+
+func (d *Device) WithVendor(vendor uint16) virtual_device.VirtualDevice {
+	d.Vendor = vendor
+	return d
+}
+
+func (d *Device) WithProduct(product uint16) virtual_device.VirtualDevice {
+	d.Product = product
+	return d
+}
+
+func (d *Device) WithVersion(version uint16) virtual_device.VirtualDevice {
+	d.Version = version
+	return d
+}
+
+func (d *Device) WithName(name string) virtual_device.VirtualDevice {
+	d.Name = name
+	return d
+}
+
+func (d *Device) WithPhys(phys string) virtual_device.VirtualDevice {
+	d.Phys = phys
+	return d
+}
+
+func (d *Device) WithKeys(keys []linux.Key) virtual_device.VirtualDevice {
+	d.Keys = keys
+	return d
+}
+
+func (d *Device) WithButtons(buttons []linux.Button) virtual_device.VirtualDevice {
+	d.Buttons = buttons
+	return d
+}
+
+func (d *Device) WithAbsAxes(absoluteAxes []virtual_device.AbsAxis) virtual_device.VirtualDevice {
+	d.AbsAxes = absoluteAxes
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, axis := range absoluteAxes {
+		d.state.abs[axis.Axis] = axis.Value
+	}
+	return d
+}
+
+func (d *Device) WithRelAxes(relativeAxes []linux.RelativeAxis) virtual_device.VirtualDevice {
+	d.RelAxes = relativeAxes
+	return d
+}
+
+func (d *Device) WithRepeat(delay, period int32) virtual_device.VirtualDevice {
+	d.RepeatDelay = delay
+	d.RepeatPeriod = period
+	return d
+}
+
+func (d *Device) WithLEDs(leds []linux.Led) virtual_device.VirtualDevice {
+	d.LEDs = leds
+	return d
+}
+
+func (d *Device) WithProperties(properties []linux.InputProp) virtual_device.VirtualDevice {
+	d.Properties = properties
+	return d
+}
+
+func (d *Device) WithMiscEvents(events []linux.MiscEvent) virtual_device.VirtualDevice {
+	d.MiscEvents = events
+	return d
+}
+
+func (d *Device) WithSwitches(switches []linux.SwitchEvent) virtual_device.VirtualDevice {
+	d.Switches = switches
+	return d
+}
+
+func (d *Device) WithForceFeedback(effects []linux.FFEffectType, effectsMax uint32) virtual_device.VirtualDevice {
+	d.FFEffects = effects
+	d.FFEffectsMax = effectsMax
+	return d
+}
+
+func (d *Device) ForceFeedback() ([]linux.FFEffectType, uint32) {
+	return d.FFEffects, d.FFEffectsMax
+}
+
+func (d *Device) WithForceFeedbackHandler(handler virtual_device.ForceFeedbackHandler) virtual_device.VirtualDevice {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.ffHandler = handler
+	return d
+}
+
+func (d *Device) Register() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.registerErr != nil {
+		return d.registerErr
+	}
+	d.registered = true
+	return nil
+}
+
+func (d *Device) Unregister() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.registered = false
+	return d.unregisterErr
+}
+
+func (d *Device) Send(evType, code uint16, value int32) {
+	d.record(evType, code, value)
+}
+
+func (d *Device) Sync(evType linux.SyncEvent) {
+	d.Send(uint16(linux.EV_SYN), uint16(evType), 0)
+}
+
+func (d *Device) SyncReport() {
+	d.Sync(linux.SYN_REPORT)
+}
+
+func (d *Device) PressKey(key linux.Key) {
+	d.Send(uint16(linux.EV_KEY), uint16(key), 1)
+}
+
+func (d *Device) ReleaseKey(key linux.Key) {
+	d.Send(uint16(linux.EV_KEY), uint16(key), 0)
+}
+
+func (d *Device) PressButton(button linux.Button) {
+	d.Send(uint16(linux.EV_KEY), uint16(button), 1)
+}
+
+func (d *Device) ReleaseButton(button linux.Button) {
+	d.Send(uint16(linux.EV_KEY), uint16(button), 0)
+}
+
+func (d *Device) SendAbsoluteEvent(axis linux.AbsoluteAxis, value int32) {
+	d.Send(uint16(linux.EV_ABS), uint16(axis), value)
+}
+
+func (d *Device) SendRelativeEvent(axis linux.RelativeAxis, value int32) {
+	d.Send(uint16(linux.EV_REL), uint16(axis), value)
+}
+
+func (d *Device) SendMiscEvent(event linux.MiscEvent, value int32) {
+	d.Send(uint16(linux.EV_MSC), uint16(event), value)
+}
+
+func (d *Device) SetLed(led linux.Led, state bool) {
+	value := int32(0)
+	if state {
+		value = 1
+	}
+	d.Send(uint16(linux.EV_LED), uint16(led), value)
+}
+
+func (d *Device) SetSwitch(sw linux.SwitchEvent, state bool) {
+	value := int32(0)
+	if state {
+		value = 1
+	}
+	d.Send(uint16(linux.EV_SW), uint16(sw), value)
+}
+
+func (d *Device) EventPath() string {
+	return "/dev/input/event99"
+}
+
+func (d *Device) DeviceInfo() (virtual_device.DeviceInfo, error) {
+	keys := append([]linux.Key{}, d.Keys...)
+	for _, button := range d.Buttons {
+		keys = append(keys, linux.Key(button))
+	}
+	absAxes := make([]linux.AbsoluteAxis, 0, len(d.AbsAxes))
+	for _, axis := range d.AbsAxes {
+		absAxes = append(absAxes, axis.Axis)
+	}
+	return virtual_device.DeviceInfo{
+		SysName:   "input99",
+		SysPath:   "/sys/devices/virtual/input/input99",
+		EventPath: d.EventPath(),
+		Name:      d.Name,
+		Phys:      d.Phys,
+		ID: linux.InputID{
+			BusType: d.BusType,
+			Vendor:  d.Vendor,
+			Product: d.Product,
+			Version: d.Version,
+		},
+		Capabilities: virtual_device.Capabilities{
+			Keys:         keys,
+			RelAxes:      d.RelAxes,
+			AbsAxes:      absAxes,
+			MiscEvents:   d.MiscEvents,
+			LEDs:         d.LEDs,
+			Switches:     d.Switches,
+			ForceEffects: d.FFEffects,
+			Properties:   d.Properties,
+		},
+	}, nil
+}
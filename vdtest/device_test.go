@@ -0,0 +1,168 @@
+package vdtest
+
+import (
+	"errors"
+	"testing"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+func TestDevice_RecordsEvents(t *testing.T) {
+	d := NewDevice()
+	d.PressKey(linux.KEY_A)
+	d.SyncReport()
+
+	events := d.Events()
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if !events[0].Equal(Key(linux.KEY_A, 1)) {
+		t.Errorf("event[0] = %v, want KEY_A press", events[0])
+	}
+}
+
+func TestDevice_FailRegister(t *testing.T) {
+	boom := errors.New("boom")
+	d := NewDevice().FailRegister(boom)
+
+	if err := d.Register(); err != boom {
+		t.Errorf("Register = %v, want %v", err, boom)
+	}
+	if d.Registered() {
+		t.Error("expected device not to be registered")
+	}
+
+	d.FailRegister(nil)
+	if err := d.Register(); err != nil {
+		t.Errorf("Register = %v, want nil", err)
+	}
+	if !d.Registered() {
+		t.Error("expected device to be registered")
+	}
+}
+
+func TestDevice_FailUnregister(t *testing.T) {
+	boom := errors.New("boom")
+	d := NewDevice().FailUnregister(boom)
+	if err := d.Unregister(); err != boom {
+		t.Errorf("Unregister = %v, want %v", err, boom)
+	}
+}
+
+func TestDevice_FailWritesAfter(t *testing.T) {
+	d := NewDevice().FailWritesAfter(2, nil)
+
+	d.PressKey(linux.KEY_A)
+	d.SyncReport()
+	if d.WriteError() != nil {
+		t.Errorf("unexpected write error before the budget is exhausted: %v", d.WriteError())
+	}
+
+	d.ReleaseKey(linux.KEY_A)
+	d.SyncReport()
+
+	if len(d.Events()) != 2 {
+		t.Errorf("expected 2 recorded events, got %d", len(d.Events()))
+	}
+	if len(d.Dropped()) != 2 {
+		t.Errorf("expected 2 dropped events, got %d", len(d.Dropped()))
+	}
+	if d.WriteError() == nil {
+		t.Error("expected a write error")
+	}
+	// the release never reached the device
+	d.ExpectKeyPressed(t, linux.KEY_A, true)
+}
+
+func TestDevice_Reset(t *testing.T) {
+	d := NewDevice()
+	d.PressKey(linux.KEY_A)
+	d.SyncReport()
+	d.Reset()
+
+	if len(d.Events()) != 0 {
+		t.Error("expected no event after Reset")
+	}
+	d.ExpectKeyPressed(t, linux.KEY_A, false)
+}
+
+func TestState_AppliedOnSyncReport(t *testing.T) {
+	d := NewDevice()
+	d.PressButton(linux.BTN_SOUTH)
+	d.SendAbsoluteEvent(linux.ABS_X, 100)
+	d.SendRelativeEvent(linux.REL_X, 5)
+	d.SetLed(linux.LED_CAPSL, true)
+
+	if d.State().IsButtonPressed(linux.BTN_SOUTH) {
+		t.Error("state must not change before SYN_REPORT")
+	}
+
+	d.SyncReport()
+	d.SendRelativeEvent(linux.REL_X, 3)
+	d.SyncReport()
+
+	state := d.State()
+	if !state.IsButtonPressed(linux.BTN_SOUTH) {
+		t.Error("expected BTN_SOUTH pressed")
+	}
+	if state.AbsValue(linux.ABS_X) != 100 {
+		t.Errorf("ABS_X = %d, want 100", state.AbsValue(linux.ABS_X))
+	}
+	if state.RelTotal(linux.REL_X) != 8 {
+		t.Errorf("REL_X = %d, want 8", state.RelTotal(linux.REL_X))
+	}
+	if !state.IsLedOn(linux.LED_CAPSL) {
+		t.Error("expected LED_CAPSL on")
+	}
+}
+
+func TestDevice_ForceFeedback(t *testing.T) {
+	d := NewDevice()
+	d.WithForceFeedback([]linux.FFEffectType{linux.FF_CONSTANT}, 2)
+	var events []virtual_device.ForceFeedbackEvent
+	d.WithForceFeedbackHandler(func(event virtual_device.ForceFeedbackEvent) {
+		events = append(events, event)
+	})
+
+	if _, err := d.UploadEffect(linux.FFEffect{Type: uint16(linux.FF_SPRING), ID: -1}); err == nil {
+		t.Error("an unsupported effect type must be rejected")
+	}
+	first, _ := d.UploadEffect(linux.FFEffect{Type: uint16(linux.FF_CONSTANT), ID: -1})
+	second, _ := d.UploadEffect(linux.FFEffect{Type: uint16(linux.FF_CONSTANT), ID: -1})
+	if first != 0 || second != 1 {
+		t.Errorf("IDs = %d, %d, want 0, 1", first, second)
+	}
+	if _, err := d.UploadEffect(linux.FFEffect{Type: uint16(linux.FF_CONSTANT), ID: -1}); err == nil {
+		t.Error("uploading more effects than the maximum must fail")
+	}
+	if err := d.EraseEffect(first); err != nil {
+		t.Fatal(err)
+	}
+	if id, _ := d.UploadEffect(linux.FFEffect{Type: uint16(linux.FF_CONSTANT), ID: -1}); id != first {
+		t.Errorf("the erased slot must be reused, got %d", id)
+	}
+	d.PlayEffect(second, 3)
+	d.SetGain(0x8000)
+
+	want := []virtual_device.ForceFeedbackEventType{
+		virtual_device.FFUpload, virtual_device.FFUpload, virtual_device.FFErase, virtual_device.FFUpload,
+		virtual_device.FFPlay, virtual_device.FFGain,
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d", len(events), len(want))
+	}
+	for i, event := range events {
+		if event.Type != want[i] {
+			t.Errorf("event %d = %v, want %v", i, event.Type, want[i])
+		}
+	}
+	if events[4].EffectID != second || events[4].Value != 3 || events[5].Value != 0x8000 {
+		t.Errorf("unexpected events %+v", events[4:])
+	}
+
+	info, _ := d.DeviceInfo()
+	if len(info.Capabilities.ForceEffects) != 1 || info.Capabilities.ForceEffects[0] != linux.FF_CONSTANT {
+		t.Errorf("ForceEffects = %v", info.Capabilities.ForceEffects)
+	}
+}
@@ -0,0 +1,206 @@
+package vdtest
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// Frame is the list of events sent between two SYN_REPORT, the terminating SYN_REPORT excluded.
+type Frame []Event
+
+func (f Frame) String() string {
+	parts := make([]string, len(f))
+	for i, e := range f {
+		parts[i] = e.String()
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+func (f Frame) contains(event Event) bool {
+	for _, e := range f {
+		if e.Equal(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// Key builds an EV_KEY event for a key.
+func Key(key linux.Key, value int32) Event {
+	return Event{EvType: uint16(linux.EV_KEY), Code: uint16(key), Value: value}
+}
+
+// Button builds an EV_KEY event for a button.
+func Button(button linux.Button, value int32) Event {
+	return Event{EvType: uint16(linux.EV_KEY), Code: uint16(button), Value: value}
+}
+
+// Abs builds an EV_ABS event.
+func Abs(axis linux.AbsoluteAxis, value int32) Event {
+	return Event{EvType: uint16(linux.EV_ABS), Code: uint16(axis), Value: value}
+}
+
+// Rel builds an EV_REL event.
+func Rel(axis linux.RelativeAxis, value int32) Event {
+	return Event{EvType: uint16(linux.EV_REL), Code: uint16(axis), Value: value}
+}
+
+// Misc builds an EV_MSC event.
+func Misc(event linux.MiscEvent, value int32) Event {
+	return Event{EvType: uint16(linux.EV_MSC), Code: uint16(event), Value: value}
+}
+
+// Switch builds an EV_SW event.
+func Switch(sw linux.SwitchEvent, value int32) Event {
+	return Event{EvType: uint16(linux.EV_SW), Code: uint16(sw), Value: value}
+}
+
+// Sync builds an EV_SYN event.
+func Sync(event linux.SyncEvent) Event {
+	return Event{EvType: uint16(linux.EV_SYN), Code: uint16(event)}
+}
+
+func isReport(e Event) bool {
+	return e.EvType == uint16(linux.EV_SYN) && e.Code == uint16(linux.SYN_REPORT)
+}
+
+func splitFrames(events []Event) []Frame {
+	frames := make([]Frame, 0)
+	start := 0
+	for i, e := range events {
+		if isReport(e) {
+			frames = append(frames, Frame(append([]Event{}, events[start:i]...)))
+			start = i + 1
+		}
+	}
+	return frames
+}
+
+// Frames returns all the complete frames recorded so far.
+func (d *Device) Frames() []Frame {
+	return splitFrames(d.Events())
+}
+
+// NextFrame consumes and returns the next complete frame not yet checked by an Expect* assertion.
+func (d *Device) NextFrame() (Frame, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := d.cursor; i < len(d.events); i++ {
+		if isReport(d.events[i]) {
+			frame := Frame(append([]Event{}, d.events[d.cursor:i]...))
+			d.cursor = i + 1
+			return frame, true
+		}
+	}
+	return nil, false
+}
+
+// ExpectFrame checks that the next frame is exactly made of events, in order.
+func (d *Device) ExpectFrame(t testing.TB, events ...Event) {
+	t.Helper()
+	frame, ok := d.NextFrame()
+	if !ok {
+		t.Fatalf("expected frame %v, got no frame", Frame(events))
+		return
+	}
+	if len(frame) != len(events) {
+		t.Fatalf("expected frame %v, got %v", Frame(events), frame)
+		return
+	}
+	for i := range events {
+		if !frame[i].Equal(events[i]) {
+			t.Fatalf("expected frame %v, got %v", Frame(events), frame)
+			return
+		}
+	}
+}
+
+// ExpectFrameContaining checks that the next frame holds all the events, in any order, among others.
+func (d *Device) ExpectFrameContaining(t testing.TB, events ...Event) {
+	t.Helper()
+	frame, ok := d.NextFrame()
+	if !ok {
+		t.Fatalf("expected frame containing %v, got no frame", Frame(events))
+		return
+	}
+	for _, e := range events {
+		if !frame.contains(e) {
+			t.Fatalf("expected frame containing %v, got %v", Frame(events), frame)
+			return
+		}
+	}
+}
+
+// ExpectKeyTap checks that the next two frames press then release the key.
+func (d *Device) ExpectKeyTap(t testing.TB, key linux.Key) {
+	t.Helper()
+	d.ExpectFrameContaining(t, Key(key, 1))
+	d.ExpectFrameContaining(t, Key(key, 0))
+}
+
+// ExpectButtonTap checks that the next two frames press then release the button.
+func (d *Device) ExpectButtonTap(t testing.TB, button linux.Button) {
+	t.Helper()
+	d.ExpectFrameContaining(t, Button(button, 1))
+	d.ExpectFrameContaining(t, Button(button, 0))
+}
+
+// ExpectNoFrame checks that every complete frame has been consumed.
+func (d *Device) ExpectNoFrame(t testing.TB) {
+	t.Helper()
+	if frame, ok := d.NextFrame(); ok {
+		t.Fatalf("expected no frame, got %v", frame)
+	}
+}
+
+// ExpectStickAt checks the mirrored position of a stick, given its axes and normalized coordinates.
+func (d *Device) ExpectStickAt(t testing.TB, xAxis, yAxis virtual_device.AbsAxis, x, y float32) {
+	t.Helper()
+	state := d.State()
+	gotX, gotY := state.AbsValue(xAxis.Axis), state.AbsValue(yAxis.Axis)
+	wantX, wantY := xAxis.Denormalize(x), yAxis.Denormalize(y)
+	if gotX != wantX || gotY != wantY {
+		t.Fatalf("expected stick at (%d, %d), got (%d, %d)", wantX, wantY, gotX, gotY)
+	}
+}
+
+// ExpectKeyPressed checks the mirrored state of a key.
+func (d *Device) ExpectKeyPressed(t testing.TB, key linux.Key, pressed bool) {
+	t.Helper()
+	if got := d.State().IsKeyPressed(key); got != pressed {
+		t.Fatalf("expected key 0x%x pressed=%v, got %v", key, pressed, got)
+	}
+}
+
+// ExpectButtonPressed checks the mirrored state of a button.
+func (d *Device) ExpectButtonPressed(t testing.TB, button linux.Button, pressed bool) {
+	t.Helper()
+	if got := d.State().IsButtonPressed(button); got != pressed {
+		t.Fatalf("expected button 0x%x pressed=%v, got %v", button, pressed, got)
+	}
+}
+
+var eventTypeNames = map[linux.EventType]string{
+	linux.EV_SYN: "EV_SYN",
+	linux.EV_KEY: "EV_KEY",
+	linux.EV_REL: "EV_REL",
+	linux.EV_ABS: "EV_ABS",
+	linux.EV_MSC: "EV_MSC",
+	linux.EV_SW:  "EV_SW",
+	linux.EV_LED: "EV_LED",
+	linux.EV_SND: "EV_SND",
+	linux.EV_REP: "EV_REP",
+	linux.EV_FF:  "EV_FF",
+}
+
+func (e Event) String() string {
+	name, ok := eventTypeNames[linux.EventType(e.EvType)]
+	if !ok {
+		name = fmt.Sprintf("0x%02x", e.EvType)
+	}
+	return fmt.Sprintf("%s 0x%03x %d", name, e.Code, e.Value)
+}
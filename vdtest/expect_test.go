@@ -0,0 +1,135 @@
+package vdtest
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// recorder captures failures instead of stopping the test.
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestFrames(t *testing.T) {
+	d := NewDevice()
+	d.PressKey(linux.KEY_A)
+	d.SyncReport()
+	d.ReleaseKey(linux.KEY_A)
+	d.SyncReport()
+	d.PressKey(linux.KEY_B) // incomplete frame
+
+	frames := d.Frames()
+	if len(frames) != 2 {
+		t.Fatalf("expected 2 frames, got %d", len(frames))
+	}
+	if len(frames[0]) != 1 || !frames[0][0].Equal(Key(linux.KEY_A, 1)) {
+		t.Errorf("frame[0] = %v", frames[0])
+	}
+}
+
+func TestExpectFrame(t *testing.T) {
+	d := NewDevice()
+	d.PressButton(linux.BTN_SOUTH)
+	d.SendMiscEvent(linux.MSC_SCAN, 0x90001)
+	d.SyncReport()
+
+	d.ExpectFrame(t, Button(linux.BTN_SOUTH, 1), Misc(linux.MSC_SCAN, 0x90001))
+	d.ExpectNoFrame(t)
+}
+
+func TestExpectFrame_Mismatch(t *testing.T) {
+	d := NewDevice()
+	d.PressButton(linux.BTN_SOUTH)
+	d.SyncReport()
+
+	r := &recorder{TB: t}
+	d.ExpectFrame(r, Button(linux.BTN_EAST, 1))
+	if len(r.failures) != 1 {
+		t.Errorf("expected 1 failure, got %v", r.failures)
+	}
+
+	r = &recorder{TB: t}
+	d.ExpectFrame(r, Button(linux.BTN_EAST, 1))
+	if len(r.failures) != 1 {
+		t.Errorf("expected a failure when no frame is left, got %v", r.failures)
+	}
+}
+
+func TestExpectKeyTap(t *testing.T) {
+	d := NewDevice()
+	d.PressKey(linux.KEY_LEFTSHIFT)
+	d.PressKey(linux.KEY_A)
+	d.SyncReport()
+	d.ReleaseKey(linux.KEY_A)
+	d.ReleaseKey(linux.KEY_LEFTSHIFT)
+	d.SyncReport()
+
+	d.ExpectKeyTap(t, linux.KEY_A)
+	d.ExpectNoFrame(t)
+}
+
+func TestExpectKeyTap_MissingRelease(t *testing.T) {
+	d := NewDevice()
+	d.PressKey(linux.KEY_A)
+	d.SyncReport()
+
+	r := &recorder{TB: t}
+	d.ExpectKeyTap(r, linux.KEY_A)
+	if len(r.failures) != 1 {
+		t.Errorf("expected 1 failure, got %v", r.failures)
+	}
+}
+
+func TestExpectStickAt(t *testing.T) {
+	x := virtual_device.AbsAxis{Axis: linux.ABS_X, Min: -32768, Max: 32767}
+	y := virtual_device.AbsAxis{Axis: linux.ABS_Y, Min: -32768, Max: 32767}
+
+	d := NewDevice()
+	d.SendAbsoluteEvent(linux.ABS_X, x.Denormalize(0.5))
+	d.SendAbsoluteEvent(linux.ABS_Y, y.Denormalize(-1))
+	d.SyncReport()
+
+	d.ExpectStickAt(t, x, y, 0.5, -1)
+
+	r := &recorder{TB: t}
+	d.ExpectStickAt(r, x, y, 0, 0)
+	if len(r.failures) != 1 {
+		t.Errorf("expected 1 failure, got %v", r.failures)
+	}
+}
+
+func TestExpectGolden(t *testing.T) {
+	d := NewDevice()
+	d.PressButton(linux.BTN_SOUTH)
+	d.SyncReport()
+	d.SendAbsoluteEvent(linux.ABS_X, -32768)
+	d.SendAbsoluteEvent(linux.ABS_Y, 32767)
+	d.SyncReport()
+	d.ReleaseButton(linux.BTN_SOUTH)
+	d.SyncReport()
+
+	d.ExpectGolden(t, filepath.Join("testdata", "golden.txt"))
+}
+
+func TestExpectGolden_Mismatch(t *testing.T) {
+	d := NewDevice()
+	d.PressButton(linux.BTN_EAST)
+	d.SyncReport()
+
+	r := &recorder{TB: t}
+	d.ExpectGolden(r, filepath.Join("testdata", "golden.txt"))
+	if len(r.failures) != 1 {
+		t.Errorf("expected 1 failure, got %v", r.failures)
+	}
+}
@@ -0,0 +1,105 @@
+package vdtest
+
+import (
+	"errors"
+	"fmt"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// The methods below play the role of an application using the force feedback of the device,
+// calling the handler given to WithForceFeedbackHandler the way the real device does.
+
+// UploadEffect uploads an effect like EVIOCSFF: an ID of -1 allocates a new effect, another ID updates it.
+// It returns the ID of the effect.
+func (d *Device) UploadEffect(effect linux.FFEffect) (int16, error) {
+	d.mu.Lock()
+	if !d.supportsEffect(linux.FFEffectType(effect.Type)) {
+		d.mu.Unlock()
+		return 0, fmt.Errorf("effect type 0x%x not supported", effect.Type)
+	}
+	if d.ffLoaded == nil {
+		d.ffLoaded = map[int16]bool{}
+	}
+	if effect.ID == -1 {
+		effect.ID = d.freeEffectID()
+		if effect.ID < 0 {
+			d.mu.Unlock()
+			return 0, errors.New("no room for a new effect")
+		}
+	} else if !d.ffLoaded[effect.ID] {
+		d.mu.Unlock()
+		return 0, fmt.Errorf("effect %d not uploaded", effect.ID)
+	}
+	d.ffLoaded[effect.ID] = true
+	d.mu.Unlock()
+
+	d.notifyForceFeedback(virtual_device.ForceFeedbackEvent{Type: virtual_device.FFUpload, EffectID: effect.ID, Effect: effect})
+	return effect.ID, nil
+}
+
+// EraseEffect removes an uploaded effect.
+func (d *Device) EraseEffect(id int16) error {
+	d.mu.Lock()
+	if !d.ffLoaded[id] {
+		d.mu.Unlock()
+		return fmt.Errorf("effect %d not uploaded", id)
+	}
+	delete(d.ffLoaded, id)
+	d.mu.Unlock()
+
+	d.notifyForceFeedback(virtual_device.ForceFeedbackEvent{Type: virtual_device.FFErase, EffectID: id})
+	return nil
+}
+
+// PlayEffect starts an uploaded effect count times.
+func (d *Device) PlayEffect(id int16, count int32) {
+	d.notifyForceFeedback(virtual_device.ForceFeedbackEvent{Type: virtual_device.FFPlay, EffectID: id, Value: count})
+}
+
+// StopEffect stops an effect.
+func (d *Device) StopEffect(id int16) {
+	d.notifyForceFeedback(virtual_device.ForceFeedbackEvent{Type: virtual_device.FFStop, EffectID: id})
+}
+
+// SetGain sets the gain of all the effects, from 0 to 0xffff.
+func (d *Device) SetGain(gain uint16) {
+	d.notifyForceFeedback(virtual_device.ForceFeedbackEvent{Type: virtual_device.FFGain, Value: int32(gain)})
+}
+
+// SetAutocenter sets the strength of the auto-centering, from 0 to 0xffff.
+func (d *Device) SetAutocenter(strength uint16) {
+	d.notifyForceFeedback(virtual_device.ForceFeedbackEvent{Type: virtual_device.FFAutocenter, Value: int32(strength)})
+}
+
+func (d *Device) supportsEffect(effect linux.FFEffectType) bool {
+	for _, supported := range d.FFEffects {
+		if supported == effect {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Device) freeEffectID() int16 {
+	max := d.FFEffectsMax
+	if max == 0 {
+		max = virtual_device.DefaultFFEffectsMax
+	}
+	for id := int16(0); id < int16(max); id++ {
+		if !d.ffLoaded[id] {
+			return id
+		}
+	}
+	return -1
+}
+
+func (d *Device) notifyForceFeedback(event virtual_device.ForceFeedbackEvent) {
+	d.mu.Lock()
+	handler := d.ffHandler
+	d.mu.Unlock()
+	if handler != nil {
+		handler(event)
+	}
+}
@@ -0,0 +1,49 @@
+package vdtest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("vdtest.update", false, "rewrite the vdtest golden files")
+
+// Dump renders the recorded events, one per line, with an empty line after each SYN_REPORT.
+func (d *Device) Dump() string {
+	var sb strings.Builder
+	for _, e := range d.Events() {
+		sb.WriteString(e.String())
+		sb.WriteString("\n")
+		if isReport(e) {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// ExpectGolden compares the recorded events with the content of a golden file.
+// Run the tests with -vdtest.update to (re)write the file.
+func (d *Device) ExpectGolden(t testing.TB, path string) {
+	t.Helper()
+	got := d.Dump()
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("unable to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatalf("unable to write %s: %v", path, err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("unable to read %s (run with -vdtest.update to create it): %v", path, err)
+	}
+	if string(want) != got {
+		t.Fatalf("events do not match %s\n--- want\n%s\n--- got\n%s", path, want, got)
+	}
+}
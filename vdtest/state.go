@@ -0,0 +1,105 @@
+package vdtest
+
+import "github.com/jbdemonte/virtual-device/linux"
+
+// State mirrors what a reader of the device would know after the last SYN_REPORT:
+// pressed keys, absolute axis positions, accumulated relative motion, LED and switch states.
+type State struct {
+	keys map[uint16]bool
+	abs  map[linux.AbsoluteAxis]int32
+	rel  map[linux.RelativeAxis]int32
+	leds map[linux.Led]bool
+	sw   map[linux.SwitchEvent]bool
+
+	pending []Event
+}
+
+func newState() *State {
+	return &State{
+		keys: map[uint16]bool{},
+		abs:  map[linux.AbsoluteAxis]int32{},
+		rel:  map[linux.RelativeAxis]int32{},
+		leds: map[linux.Led]bool{},
+		sw:   map[linux.SwitchEvent]bool{},
+	}
+}
+
+// apply buffers the event until the next SYN_REPORT, like evdev does.
+func (s *State) apply(event Event) {
+	if event.EvType != uint16(linux.EV_SYN) || event.Code != uint16(linux.SYN_REPORT) {
+		s.pending = append(s.pending, event)
+		return
+	}
+	for _, e := range s.pending {
+		switch linux.EventType(e.EvType) {
+		case linux.EV_KEY:
+			s.keys[e.Code] = e.Value != 0
+		case linux.EV_ABS:
+			s.abs[linux.AbsoluteAxis(e.Code)] = e.Value
+		case linux.EV_REL:
+			s.rel[linux.RelativeAxis(e.Code)] += e.Value
+		case linux.EV_LED:
+			s.leds[linux.Led(e.Code)] = e.Value != 0
+		case linux.EV_SW:
+			s.sw[linux.SwitchEvent(e.Code)] = e.Value != 0
+		}
+	}
+	s.pending = nil
+}
+
+func (s *State) copy() State {
+	cp := *newState()
+	for k, v := range s.keys {
+		cp.keys[k] = v
+	}
+	for k, v := range s.abs {
+		cp.abs[k] = v
+	}
+	for k, v := range s.rel {
+		cp.rel[k] = v
+	}
+	for k, v := range s.leds {
+		cp.leds[k] = v
+	}
+	for k, v := range s.sw {
+		cp.sw[k] = v
+	}
+	return cp
+}
+
+// IsKeyPressed reports whether the key is held.
+func (s State) IsKeyPressed(key linux.Key) bool {
+	return s.keys[uint16(key)]
+}
+
+// IsButtonPressed reports whether the button is held.
+func (s State) IsButtonPressed(button linux.Button) bool {
+	return s.keys[uint16(button)]
+}
+
+// AbsValue returns the last synchronized value of an absolute axis.
+func (s State) AbsValue(axis linux.AbsoluteAxis) int32 {
+	return s.abs[axis]
+}
+
+// RelTotal returns the sum of all the synchronized motions on a relative axis.
+func (s State) RelTotal(axis linux.RelativeAxis) int32 {
+	return s.rel[axis]
+}
+
+// IsLedOn reports whether the LED is on.
+func (s State) IsLedOn(led linux.Led) bool {
+	return s.leds[led]
+}
+
+// State returns a snapshot of the mirrored device state.
+func (d *Device) State() State {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.state.copy()
+}
+
+// IsSwitchOn reports whether the switch is set.
+func (s State) IsSwitchOn(sw linux.SwitchEvent) bool {
+	return s.sw[sw]
+}
@@ -23,6 +23,7 @@ type VirtualDevice interface {
 	WithProduct(product sdl.Product) VirtualDevice
 	WithVersion(version uint16) VirtualDevice
 	WithName(name string) VirtualDevice
+	WithPhys(phys string) VirtualDevice
 	WithKeys(keys []linux.Key) VirtualDevice
 	WithButtons(buttons []linux.Button) VirtualDevice
 	WithAbsAxes(absoluteAxes []AbsAxis) VirtualDevice
@@ -31,6 +32,10 @@ type VirtualDevice interface {
 	WithLEDs(leds []linux.Led) VirtualDevice
 	WithProperties(properties []linux.InputProp) VirtualDevice
 	WithMiscEvents(events []linux.MiscEvent) VirtualDevice
+	WithSwitches(switches []linux.SwitchEvent) VirtualDevice
+	WithForceFeedback(effects []linux.FFEffectType, effectsMax uint32) VirtualDevice
+	WithForceFeedbackHandler(handler ForceFeedbackHandler) VirtualDevice
+	ForceFeedback() (effects []linux.FFEffectType, effectsMax uint32)
 
 	Register() error
 	Unregister() error
@@ -46,8 +51,10 @@ type VirtualDevice interface {
 	SendRelativeEvent(axis linux.RelativeAxis, value int32)
 	SendMiscEvent(event linux.MiscEvent, value int32)
 	SetLed(led linux.Led, state bool)
+	SetSwitch(sw linux.SwitchEvent, state bool)
 
 	EventPath() string
+	DeviceInfo() (DeviceInfo, error)
 }
 
 // NewVirtualDevice creates a new VirtualDevice with default settings.
@@ -100,6 +107,12 @@ func (vd *virtualDevice) WithName(name string) VirtualDevice {
 	return vd
 }
 
+// WithPhys sets the physical path reported by the device, shared by the nodes of a same physical controller.
+func (vd *virtualDevice) WithPhys(phys string) VirtualDevice {
+	vd.phys = phys
+	return vd
+}
+
 func (vd *virtualDevice) WithKeys(keys []linux.Key) VirtualDevice {
 	vd.config.keys = keys
 	return vd
@@ -142,11 +155,20 @@ func (vd *virtualDevice) WithMiscEvents(miscEvents []linux.MiscEvent) VirtualDev
 
 }
 
+func (vd *virtualDevice) WithSwitches(switches []linux.SwitchEvent) VirtualDevice {
+	vd.config.switches = switches
+	return vd
+}
+
 func (vd *virtualDevice) Register() error {
 	if vd.isRegistered.Get() {
 		return nil
 	}
-	fd, err := os.OpenFile(vd.path, syscall.O_WRONLY|syscall.O_NONBLOCK, vd.mode)
+	flags := syscall.O_WRONLY
+	if vd.hasForceFeedback() {
+		flags = syscall.O_RDWR // the force feedback requests are read from the uinput file
+	}
+	fd, err := os.OpenFile(vd.path, flags|syscall.O_NONBLOCK, vd.mode)
 	if err != nil {
 		return errors.New("could not open device file")
 	}
@@ -159,6 +181,9 @@ func (vd *virtualDevice) Register() error {
 		vd.registerProperties,
 		vd.registerMiscEvents,
 		vd.registerLeds,
+		vd.registerSwitches,
+		vd.registerForceFeedback,
+		vd.registerPhys,
 		vd.createDevice,
 	}
 
@@ -169,6 +194,7 @@ func (vd *virtualDevice) Register() error {
 	}
 
 	vd.pull()
+	vd.listen()
 
 	vd.isRegistered.Set(true)
 
@@ -176,7 +202,6 @@ func (vd *virtualDevice) Register() error {
 }
 
 func (vd *virtualDevice) fetchEventPath() (string, error) {
-	sysInputDir := "/sys/devices/virtual/input/"
 	path := make([]byte, 65) // 64 bytes + null byte
 
 	err := ioctl(vd.fd, linux.UI_GET_SYSNAME(64), uintptr(unsafe.Pointer(&path[0])))
@@ -184,7 +209,8 @@ func (vd *virtualDevice) fetchEventPath() (string, error) {
 		return "", fmt.Errorf("ioctl uiGetSysname failed: %v", err)
 	}
 
-	sysPath := sysInputDir + strings.TrimRight(string(path), "\x00")
+	vd.sysName = strings.TrimRight(string(path), "\x00")
+	sysPath := sysInputDir + vd.sysName
 
 	files, err := os.ReadDir(sysPath)
 	if err != nil {
@@ -210,6 +236,7 @@ func (vd *virtualDevice) createDevice() (err error) {
 	var uinputDev linux.UInputUserDev
 	copy(uinputDev.Name[:], fixedSizeName[:])
 	uinputDev.ID = vd.id
+	uinputDev.EffectsMax = vd.effectsMax()
 
 	setAbsResolution := false
 
@@ -393,6 +420,35 @@ func (vd *virtualDevice) registerLeds() error {
 	return nil
 }
 
+func (vd *virtualDevice) registerSwitches() error {
+	if len(vd.config.switches) == 0 {
+		return nil
+	}
+	err := ioctl(vd.fd, linux.UI_SET_EVBIT, uintptr(linux.EV_SW))
+	if err != nil {
+		return fmt.Errorf("failed to set UI_SET_EVBIT, EV_SW: %v", err)
+	}
+	for _, sw := range vd.config.switches {
+		err := ioctl(vd.fd, linux.UI_SET_SWBIT, uintptr(sw))
+		if err != nil {
+			return fmt.Errorf("failed to set UI_SET_SWBIT, 0x%x: %v", sw, err)
+		}
+	}
+	return nil
+}
+
+func (vd *virtualDevice) registerPhys() error {
+	if vd.phys == "" {
+		return nil
+	}
+	phys := append([]byte(vd.phys), 0)
+	err := ioctl(vd.fd, linux.UI_SET_PHYS, uintptr(unsafe.Pointer(&phys[0])))
+	if err != nil {
+		return fmt.Errorf("failed to set UI_SET_PHYS, %s: %v", vd.phys, err)
+	}
+	return nil
+}
+
 func (vd *virtualDevice) pull() {
 	vd.queue = make(chan *linux.InputEvent, vd.queueLen)
 	vd.pullDone = make(chan struct{})
@@ -472,10 +528,15 @@ func (vd *virtualDevice) Unregister() (err error) {
 
 	vd.closeQueue()
 
-	return concatErrors(
+	err = concatErrors(
 		vd.releaseDevice(),
 		vd.closeDevice(),
 	)
+	if vd.listenDone != nil {
+		<-vd.listenDone
+		vd.listenDone = nil
+	}
+	return err
 }
 
 // Send an event to the device.
@@ -537,3 +598,11 @@ func (vd *virtualDevice) SetLed(led linux.Led, state bool) {
 	}
 	vd.Send(uint16(linux.EV_LED), uint16(led), value)
 }
+
+func (vd *virtualDevice) SetSwitch(sw linux.SwitchEvent, state bool) {
+	value := int32(0)
+	if state {
+		value = 1
+	}
+	vd.Send(uint16(linux.EV_SW), uint16(sw), value)
+}
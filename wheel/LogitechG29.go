@@ -0,0 +1,71 @@
+package wheel
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/sdl"
+)
+
+func NewLogitechG29() VirtualWheel {
+	return newLogitechG29(
+		virtual_device.
+			NewVirtualDevice().
+			WithBusType(linux.BUS_USB).
+			WithVendor(sdl.USB_VENDOR_LOGITECH).
+			WithProduct(0xc24f).
+			WithVersion(0x111).
+			WithName("Logitech G29 Driving Force Racing Wheel"),
+	)
+}
+
+func newLogitechG29(device virtual_device.VirtualDevice) VirtualWheel {
+	return NewVirtualWheelFactory().
+		WithDevice(device).
+		WithSteering(virtual_device.AbsAxis{Axis: linux.ABS_X, Min: 0, Value: 32768, Max: 65535}).
+		WithRotationRange(900).
+		WithClutch(Pedal{Axis: virtual_device.AbsAxis{Axis: linux.ABS_Y, Min: 0, Max: 255}, Inverted: true}).
+		WithThrottle(Pedal{Axis: virtual_device.AbsAxis{Axis: linux.ABS_Z, Min: 0, Max: 255}, Inverted: true}).
+		WithBrake(Pedal{Axis: virtual_device.AbsAxis{Axis: linux.ABS_RZ, Min: 0, Max: 255}, Inverted: true}).
+		WithButtons(
+			MappingButtons{
+				ButtonSouth: linux.BTN_TRIGGER, // Cross
+				ButtonWest:  linux.BTN_THUMB,   // Square
+				ButtonEast:  linux.BTN_THUMB2,  // Circle
+				ButtonNorth: linux.BTN_TOP,     // Triangle
+
+				ButtonShiftUp:   linux.BTN_TOP2,
+				ButtonShiftDown: linux.BTN_PINKIE,
+
+				ButtonR2: linux.BTN_BASE,
+				ButtonL2: linux.BTN_BASE2,
+
+				ButtonSelect: linux.BTN_BASE3, // Share
+				ButtonStart:  linux.BTN_BASE4, // Options
+
+				ButtonR3: linux.BTN_BASE5,
+				ButtonL3: linux.BTN_BASE6,
+
+				ButtonPlus:      linux.BTN_TRIGGER_HAPPY4,
+				ButtonMinus:     linux.BTN_TRIGGER_HAPPY5,
+				ButtonDialCW:    linux.BTN_TRIGGER_HAPPY6,
+				ButtonDialCCW:   linux.BTN_TRIGGER_HAPPY7,
+				ButtonDialEnter: linux.BTN_TRIGGER_HAPPY8,
+				ButtonMode:      linux.BTN_TRIGGER_HAPPY9, // PS
+			},
+		).
+		WithGears(
+			// Driving Force Shifter, plugged on the wheel base
+			MappingGears{
+				1:           0x12c, // unnamed codes, between BTN_BASE6 and BTN_DEAD
+				2:           0x12d,
+				3:           0x12e,
+				4:           linux.BTN_DEAD,
+				5:           linux.BTN_TRIGGER_HAPPY1,
+				6:           linux.BTN_TRIGGER_HAPPY2,
+				GearReverse: linux.BTN_TRIGGER_HAPPY3,
+			},
+		).
+		WithDPad().
+		WithForceFeedback(effects, 16).
+		Create()
+}
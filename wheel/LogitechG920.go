@@ -0,0 +1,63 @@
+package wheel
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/sdl"
+)
+
+func NewLogitechG920() VirtualWheel {
+	return newLogitechG920(
+		virtual_device.
+			NewVirtualDevice().
+			WithBusType(linux.BUS_USB).
+			WithVendor(sdl.USB_VENDOR_LOGITECH).
+			WithProduct(0xc262).
+			WithVersion(0x111).
+			WithName("Logitech G920 Driving Force Racing Wheel for Xbox One"),
+	)
+}
+
+func newLogitechG920(device virtual_device.VirtualDevice) VirtualWheel {
+	return NewVirtualWheelFactory().
+		WithDevice(device).
+		WithSteering(virtual_device.AbsAxis{Axis: linux.ABS_X, Min: 0, Value: 32768, Max: 65535}).
+		WithRotationRange(900).
+		WithThrottle(Pedal{Axis: virtual_device.AbsAxis{Axis: linux.ABS_Y, Min: 0, Max: 255}, Inverted: true}).
+		WithBrake(Pedal{Axis: virtual_device.AbsAxis{Axis: linux.ABS_Z, Min: 0, Max: 255}, Inverted: true}).
+		WithClutch(Pedal{Axis: virtual_device.AbsAxis{Axis: linux.ABS_RZ, Min: 0, Max: 255}, Inverted: true}).
+		WithButtons(
+			MappingButtons{
+				ButtonSouth: linux.BTN_TRIGGER, // A
+				ButtonEast:  linux.BTN_THUMB,   // B
+				ButtonWest:  linux.BTN_THUMB2,  // X
+				ButtonNorth: linux.BTN_TOP,     // Y
+
+				ButtonShiftUp:   linux.BTN_TOP2,
+				ButtonShiftDown: linux.BTN_PINKIE,
+
+				ButtonStart:  linux.BTN_BASE,  // Menu
+				ButtonSelect: linux.BTN_BASE2, // View
+
+				ButtonR3: linux.BTN_BASE3, // RSB
+				ButtonL3: linux.BTN_BASE4, // LSB
+
+				ButtonMode: linux.BTN_BASE5, // Xbox
+			},
+		).
+		WithGears(
+			// Driving Force Shifter, plugged on the wheel base
+			MappingGears{
+				1:           linux.BTN_BASE6,
+				2:           0x12c, // unnamed codes, between BTN_BASE6 and BTN_DEAD
+				3:           0x12d,
+				4:           0x12e,
+				5:           linux.BTN_DEAD,
+				6:           linux.BTN_TRIGGER_HAPPY1,
+				GearReverse: linux.BTN_TRIGGER_HAPPY2,
+			},
+		).
+		WithDPad().
+		WithForceFeedback(effects, 16).
+		Create()
+}
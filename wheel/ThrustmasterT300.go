@@ -0,0 +1,54 @@
+package wheel
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/sdl"
+)
+
+func NewThrustmasterT300RS() VirtualWheel {
+	return newThrustmasterT300RS(
+		virtual_device.
+			NewVirtualDevice().
+			WithBusType(linux.BUS_USB).
+			WithVendor(sdl.USB_VENDOR_THRUSTMASTER).
+			WithProduct(0xb66e).
+			WithVersion(0x111).
+			WithName("Thrustmaster T300RS Racing wheel"),
+	)
+}
+
+func newThrustmasterT300RS(device virtual_device.VirtualDevice) VirtualWheel {
+	return NewVirtualWheelFactory().
+		WithDevice(device).
+		WithSteering(virtual_device.AbsAxis{Axis: linux.ABS_X, Min: 0, Value: 32768, Max: 65535}).
+		WithRotationRange(1080).
+		WithThrottle(Pedal{Axis: virtual_device.AbsAxis{Axis: linux.ABS_Y, Min: 0, Max: 1023}}).
+		WithClutch(Pedal{Axis: virtual_device.AbsAxis{Axis: linux.ABS_Z, Min: 0, Max: 1023}}).
+		WithBrake(Pedal{Axis: virtual_device.AbsAxis{Axis: linux.ABS_RZ, Min: 0, Max: 1023}}).
+		WithButtons(
+			MappingButtons{
+				ButtonSouth: linux.BTN_TRIGGER, // Cross
+				ButtonWest:  linux.BTN_THUMB,   // Square
+				ButtonEast:  linux.BTN_THUMB2,  // Circle
+				ButtonNorth: linux.BTN_TOP,     // Triangle
+
+				ButtonShiftUp:   linux.BTN_TOP2,
+				ButtonShiftDown: linux.BTN_PINKIE,
+
+				ButtonR2: linux.BTN_BASE,
+				ButtonL2: linux.BTN_BASE2,
+
+				ButtonSelect: linux.BTN_BASE3, // Share
+				ButtonStart:  linux.BTN_BASE4, // Options
+
+				ButtonR3: linux.BTN_BASE5,
+				ButtonL3: linux.BTN_BASE6,
+
+				ButtonMode: linux.BTN_DEAD, // PS
+			},
+		).
+		WithDPad().
+		WithForceFeedback(effects, 16).
+		Create()
+}
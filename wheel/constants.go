@@ -0,0 +1,42 @@
+package wheel
+
+import "github.com/jbdemonte/virtual-device/linux"
+
+// Button identifies a logical wheel button.
+type Button int
+
+const (
+	ButtonShiftUp   Button = iota + 1 // Right paddle shifter
+	ButtonShiftDown                   // Left paddle shifter
+
+	ButtonNorth
+	ButtonEast
+	ButtonSouth
+	ButtonWest
+
+	ButtonL2
+	ButtonR2
+	ButtonL3
+	ButtonR3
+
+	ButtonSelect // Share (PlayStation) or View (Xbox)
+	ButtonStart  // Options (PlayStation) or Menu (Xbox)
+	ButtonMode   // PS or Xbox button
+
+	ButtonPlus
+	ButtonMinus
+	ButtonDialCW  // Rotary dial, clockwise step
+	ButtonDialCCW // Rotary dial, counterclockwise step
+	ButtonDialEnter
+)
+
+// Gear is a position of the H-shifter.
+type Gear int
+
+const (
+	GearReverse Gear = -1
+	GearNeutral Gear = 0
+)
+
+// effects supported by the force feedback wheels profiles.
+var effects = []linux.FFEffectType{linux.FF_CONSTANT, linux.FF_SPRING, linux.FF_DAMPER, linux.FF_AUTOCENTER, linux.FF_GAIN}
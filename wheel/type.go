@@ -0,0 +1,18 @@
+package wheel
+
+import (
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// MappingButtons maps wheel buttons to their codes.
+type MappingButtons map[Button]linux.Button
+
+// MappingGears maps the H-shifter gears to their codes, GearNeutral being the absence of any.
+type MappingGears map[Gear]linux.Button
+
+// Pedal is the axis of a pedal. An inverted pedal is at its maximum when released.
+type Pedal struct {
+	Axis     virtual_device.AbsAxis
+	Inverted bool
+}
@@ -0,0 +1,437 @@
+package wheel
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	virtual_device "github.com/jbdemonte/virtual-device"
+	"github.com/jbdemonte/virtual-device/linux"
+)
+
+// VirtualWheel is a high-level virtual steering wheel, with its pedals, shifters and force feedback.
+type VirtualWheel interface {
+	Register() error
+	Unregister() error
+
+	// Steer turns the wheel, from -1 (full left) to 1 (full right).
+	Steer(position float32)
+	// SteerAngle turns the wheel by an angle in degrees, negative to the left, clamped to half the rotation range.
+	SteerAngle(degrees float64)
+	RotationRange() float64
+
+	// Throttle, Brake and Clutch push the pedals, from 0 (released) to 1 (floored).
+	Throttle(value float32)
+	Brake(value float32)
+	Clutch(value float32)
+
+	Press(button Button)
+	Release(button Button)
+	SupportedButtons() []Button
+
+	// ShiftGear moves the H-shifter, releasing the previous gear. GearNeutral releases all of them.
+	ShiftGear(gear Gear)
+	Gear() Gear
+
+	// MoveDPad sets the D-pad hat, -1, 0 or 1 on each axis, y being -1 up.
+	MoveDPad(x, y int32)
+
+	// ForceFeedback returns the effects uploaded and played by the applications.
+	ForceFeedback() ForceFeedbackState
+
+	Send(evType, code uint16, value int32)
+
+	EventPath() string
+	DeviceInfo() (virtual_device.DeviceInfo, error)
+}
+
+// VirtualWheelFactory configures and creates VirtualWheel instances.
+type VirtualWheelFactory interface {
+	WithDevice(device virtual_device.VirtualDevice) VirtualWheelFactory
+	WithSteering(axis virtual_device.AbsAxis) VirtualWheelFactory
+	WithRotationRange(degrees float64) VirtualWheelFactory
+	WithThrottle(pedal Pedal) VirtualWheelFactory
+	WithBrake(pedal Pedal) VirtualWheelFactory
+	WithClutch(pedal Pedal) VirtualWheelFactory
+	WithButtons(mapping MappingButtons) VirtualWheelFactory
+	WithGears(mapping MappingGears) VirtualWheelFactory
+	WithDPad() VirtualWheelFactory
+	WithForceFeedback(effects []linux.FFEffectType, effectsMax uint32) VirtualWheelFactory
+	WithForceFeedbackHandler(handler virtual_device.ForceFeedbackHandler) VirtualWheelFactory
+	Create() VirtualWheel
+}
+
+// DefaultRotationRange is the rotation range, in degrees, of a wheel created without WithRotationRange.
+const DefaultRotationRange = 900
+
+type virtualWheelFactory struct {
+	device        virtual_device.VirtualDevice
+	steering      virtual_device.AbsAxis
+	rotationRange float64
+	throttle      *Pedal
+	brake         *Pedal
+	clutch        *Pedal
+	buttons       MappingButtons
+	gears         MappingGears
+	dpad          bool
+	ffEffects     []linux.FFEffectType
+	ffEffectsMax  uint32
+	ffHandler     virtual_device.ForceFeedbackHandler
+}
+
+// NewVirtualWheelFactory returns a new factory for building virtual wheels.
+func NewVirtualWheelFactory() VirtualWheelFactory {
+	return &virtualWheelFactory{
+		steering:      virtual_device.AbsAxis{Axis: linux.ABS_X, Min: 0, Value: 32768, Max: 65535},
+		rotationRange: DefaultRotationRange,
+	}
+}
+
+func (f *virtualWheelFactory) WithDevice(device virtual_device.VirtualDevice) VirtualWheelFactory {
+	f.device = device
+	return f
+}
+
+func (f *virtualWheelFactory) WithSteering(axis virtual_device.AbsAxis) VirtualWheelFactory {
+	f.steering = axis
+	return f
+}
+
+func (f *virtualWheelFactory) WithRotationRange(degrees float64) VirtualWheelFactory {
+	f.rotationRange = degrees
+	return f
+}
+
+func (f *virtualWheelFactory) WithThrottle(pedal Pedal) VirtualWheelFactory {
+	f.throttle = &pedal
+	return f
+}
+
+func (f *virtualWheelFactory) WithBrake(pedal Pedal) VirtualWheelFactory {
+	f.brake = &pedal
+	return f
+}
+
+func (f *virtualWheelFactory) WithClutch(pedal Pedal) VirtualWheelFactory {
+	f.clutch = &pedal
+	return f
+}
+
+func (f *virtualWheelFactory) WithButtons(mapping MappingButtons) VirtualWheelFactory {
+	f.buttons = mapping
+	return f
+}
+
+func (f *virtualWheelFactory) WithGears(mapping MappingGears) VirtualWheelFactory {
+	f.gears = mapping
+	return f
+}
+
+func (f *virtualWheelFactory) WithDPad() VirtualWheelFactory {
+	f.dpad = true
+	return f
+}
+
+func (f *virtualWheelFactory) WithForceFeedback(effects []linux.FFEffectType, effectsMax uint32) VirtualWheelFactory {
+	f.ffEffects = effects
+	f.ffEffectsMax = effectsMax
+	return f
+}
+
+func (f *virtualWheelFactory) WithForceFeedbackHandler(handler virtual_device.ForceFeedbackHandler) VirtualWheelFactory {
+	f.ffHandler = handler
+	return f
+}
+
+func (f *virtualWheelFactory) Create() VirtualWheel {
+	vw := &virtualWheel{
+		device:        f.device,
+		steering:      f.steering,
+		rotationRange: f.rotationRange,
+		throttle:      f.throttle,
+		brake:         f.brake,
+		clutch:        f.clutch,
+		buttons:       f.buttons,
+		gears:         f.gears,
+		dpad:          f.dpad,
+		handler:       f.ffHandler,
+		ff:            newForceFeedbackState(),
+	}
+	vw.init(f.ffEffects, f.ffEffectsMax)
+	return vw
+}
+
+type virtualWheel struct {
+	device        virtual_device.VirtualDevice
+	steering      virtual_device.AbsAxis
+	rotationRange float64
+	throttle      *Pedal
+	brake         *Pedal
+	clutch        *Pedal
+	buttons       MappingButtons
+	gears         MappingGears
+	dpad          bool
+	gear          Gear
+
+	mu      sync.Mutex // guards ff, updated from the goroutine reading the force feedback requests
+	ff      ForceFeedbackState
+	handler virtual_device.ForceFeedbackHandler
+}
+
+func (vw *virtualWheel) init(effects []linux.FFEffectType, effectsMax uint32) {
+	buttons := make([]linux.Button, 0, len(vw.buttons)+len(vw.gears))
+	seen := map[linux.Button]bool{}
+	for _, code := range vw.buttons {
+		if !seen[code] {
+			seen[code] = true
+			buttons = append(buttons, code)
+		}
+	}
+	for _, code := range vw.gears {
+		if !seen[code] {
+			seen[code] = true
+			buttons = append(buttons, code)
+		}
+	}
+	sort.Slice(buttons, func(i, j int) bool { return buttons[i] < buttons[j] })
+
+	axes := []virtual_device.AbsAxis{vw.steering}
+	for _, pedal := range []*Pedal{vw.throttle, vw.brake, vw.clutch} {
+		if pedal != nil {
+			axis := pedal.Axis
+			axis.Value = pedalValue(*pedal, 0)
+			axes = append(axes, axis)
+		}
+	}
+	if vw.dpad {
+		axes = append(axes,
+			virtual_device.AbsAxis{Axis: linux.ABS_HAT0X, Min: -1, Max: 1},
+			virtual_device.AbsAxis{Axis: linux.ABS_HAT0Y, Min: -1, Max: 1},
+		)
+	}
+
+	vw.device.WithButtons(buttons)
+	vw.device.WithAbsAxes(axes)
+	if len(effects) > 0 {
+		vw.device.WithForceFeedback(effects, effectsMax)
+		vw.device.WithForceFeedbackHandler(vw.handleForceFeedback)
+	}
+}
+
+func (vw *virtualWheel) Register() error {
+	return vw.device.Register()
+}
+
+func (vw *virtualWheel) Unregister() error {
+	return vw.device.Unregister()
+}
+
+func (vw *virtualWheel) EventPath() string {
+	return vw.device.EventPath()
+}
+
+func (vw *virtualWheel) DeviceInfo() (virtual_device.DeviceInfo, error) {
+	return vw.device.DeviceInfo()
+}
+
+func (vw *virtualWheel) Send(evType, code uint16, value int32) {
+	vw.device.Send(evType, code, value)
+}
+
+func (vw *virtualWheel) RotationRange() float64 {
+	return vw.rotationRange
+}
+
+func (vw *virtualWheel) Steer(position float32) {
+	vw.device.SendAbsoluteEvent(vw.steering.Axis, vw.steering.Denormalize(position))
+	vw.device.SyncReport()
+}
+
+func (vw *virtualWheel) SteerAngle(degrees float64) {
+	vw.Steer(float32(degrees / (vw.rotationRange / 2)))
+}
+
+// pedalValue converts a pedal position between 0 and 1 to the axis range.
+func pedalValue(pedal Pedal, value float32) int32 {
+	if pedal.Inverted {
+		value = 1 - value
+	}
+	axis := pedal.Axis
+	axis.IsUnidirectional = true
+	return axis.Denormalize(value)
+}
+
+func (vw *virtualWheel) movePedal(pedal *Pedal, value float32) {
+	if pedal == nil {
+		return
+	}
+	if value < 0 {
+		value = 0
+	}
+	if value > 1 {
+		value = 1
+	}
+	vw.device.SendAbsoluteEvent(pedal.Axis.Axis, pedalValue(*pedal, value))
+	vw.device.SyncReport()
+}
+
+func (vw *virtualWheel) Throttle(value float32) {
+	vw.movePedal(vw.throttle, value)
+}
+
+func (vw *virtualWheel) Brake(value float32) {
+	vw.movePedal(vw.brake, value)
+}
+
+func (vw *virtualWheel) Clutch(value float32) {
+	vw.movePedal(vw.clutch, value)
+}
+
+func (vw *virtualWheel) Press(button Button) {
+	code, exist := vw.buttons[button]
+	if !exist {
+		fmt.Printf("button not assigned (0x%x)\n", button)
+		return
+	}
+	vw.device.PressButton(code)
+	vw.device.SyncReport()
+}
+
+func (vw *virtualWheel) Release(button Button) {
+	code, exist := vw.buttons[button]
+	if !exist {
+		fmt.Printf("button not assigned (0x%x)\n", button)
+		return
+	}
+	vw.device.ReleaseButton(code)
+	vw.device.SyncReport()
+}
+
+// SupportedButtons returns the logical buttons mapped by the wheel, in the order of their constants.
+func (vw *virtualWheel) SupportedButtons() []Button {
+	buttons := make([]Button, 0, len(vw.buttons))
+	for button := range vw.buttons {
+		buttons = append(buttons, button)
+	}
+	sort.Slice(buttons, func(i, j int) bool { return buttons[i] < buttons[j] })
+	return buttons
+}
+
+func (vw *virtualWheel) ShiftGear(gear Gear) {
+	code, exist := vw.gears[gear]
+	if gear != GearNeutral && !exist {
+		fmt.Printf("gear not assigned (%d)\n", gear)
+		return
+	}
+	if gear == vw.gear {
+		return
+	}
+	if previous, ok := vw.gears[vw.gear]; ok {
+		vw.device.ReleaseButton(previous)
+	}
+	if gear != GearNeutral {
+		vw.device.PressButton(code)
+	}
+	vw.gear = gear
+	vw.device.SyncReport()
+}
+
+func (vw *virtualWheel) Gear() Gear {
+	return vw.gear
+}
+
+func (vw *virtualWheel) MoveDPad(x, y int32) {
+	if !vw.dpad {
+		return
+	}
+	vw.device.SendAbsoluteEvent(linux.ABS_HAT0X, clampHat(x))
+	vw.device.SendAbsoluteEvent(linux.ABS_HAT0Y, clampHat(y))
+	vw.device.SyncReport()
+}
+
+func clampHat(value int32) int32 {
+	if value < 0 {
+		return -1
+	}
+	if value > 0 {
+		return 1
+	}
+	return 0
+}
+
+// ForceFeedbackState holds the force feedback requested by the applications.
+type ForceFeedbackState struct {
+	Effects    map[int16]linux.FFEffect // uploaded effects, by ID
+	Playing    map[int16]bool           // IDs of the effects being played
+	Gain       uint16                   // from 0 to 0xffff
+	Autocenter uint16                   // from 0 to 0xffff
+}
+
+func newForceFeedbackState() ForceFeedbackState {
+	return ForceFeedbackState{
+		Effects: map[int16]linux.FFEffect{},
+		Playing: map[int16]bool{},
+		Gain:    0xffff,
+	}
+}
+
+func (s ForceFeedbackState) copy() ForceFeedbackState {
+	cp := newForceFeedbackState()
+	for id, effect := range s.Effects {
+		cp.Effects[id] = effect
+	}
+	for id, playing := range s.Playing {
+		cp.Playing[id] = playing
+	}
+	cp.Gain = s.Gain
+	cp.Autocenter = s.Autocenter
+	return cp
+}
+
+// ConstantForce sums the constant effects being played, scaled by the gain, from -1 to 1.
+// It is positive when the effects pull towards the 0x4000 direction, the left in linux/input.h.
+func (s ForceFeedbackState) ConstantForce() float64 {
+	force := 0.0
+	for id := range s.Playing {
+		effect, ok := s.Effects[id]
+		if !ok || effect.Type != uint16(linux.FF_CONSTANT) {
+			continue
+		}
+		angle := float64(effect.Direction) * 2 * math.Pi / 0x10000
+		force += float64(effect.Constant().Level) / 0x7fff * math.Sin(angle)
+	}
+	force *= float64(s.Gain) / 0xffff
+	return math.Max(-1, math.Min(1, force))
+}
+
+func (vw *virtualWheel) ForceFeedback() ForceFeedbackState {
+	vw.mu.Lock()
+	defer vw.mu.Unlock()
+	return vw.ff.copy()
+}
+
+func (vw *virtualWheel) handleForceFeedback(event virtual_device.ForceFeedbackEvent) {
+	vw.mu.Lock()
+	switch event.Type {
+	case virtual_device.FFUpload:
+		vw.ff.Effects[event.EffectID] = event.Effect
+	case virtual_device.FFErase:
+		delete(vw.ff.Effects, event.EffectID)
+		delete(vw.ff.Playing, event.EffectID)
+	case virtual_device.FFPlay:
+		vw.ff.Playing[event.EffectID] = true
+	case virtual_device.FFStop:
+		delete(vw.ff.Playing, event.EffectID)
+	case virtual_device.FFGain:
+		vw.ff.Gain = uint16(event.Value)
+	case virtual_device.FFAutocenter:
+		vw.ff.Autocenter = uint16(event.Value)
+	}
+	handler := vw.handler
+	vw.mu.Unlock()
+
+	if handler != nil {
+		handler(event)
+	}
+}
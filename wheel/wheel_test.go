@@ -0,0 +1,162 @@
+package wheel
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jbdemonte/virtual-device/linux"
+	"github.com/jbdemonte/virtual-device/vdtest"
+)
+
+func TestWheel_Steer(t *testing.T) {
+	mock := vdtest.NewDevice()
+	w := newLogitechG29(mock)
+
+	w.Steer(0)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_X, 32767))
+	w.Steer(-1)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_X, 0))
+	w.Steer(2)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_X, 65535))
+}
+
+func TestWheel_SteerAngle(t *testing.T) {
+	mock := vdtest.NewDevice()
+	w := newLogitechG29(mock)
+
+	if w.RotationRange() != 900 {
+		t.Fatalf("RotationRange = %v, want 900", w.RotationRange())
+	}
+	w.SteerAngle(225)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_X, 49151))
+	w.SteerAngle(-720)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_X, 0))
+}
+
+func TestWheel_Pedals(t *testing.T) {
+	mock := vdtest.NewDevice()
+	w := newLogitechG29(mock)
+
+	for _, axis := range mock.AbsAxes {
+		if axis.Axis == linux.ABS_Z && axis.Value != 255 {
+			t.Errorf("released inverted throttle = %d, want 255", axis.Value)
+		}
+	}
+
+	w.Throttle(1)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_Z, 0))
+	w.Brake(0.5)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_RZ, 127))
+	w.Clutch(0)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_Y, 255))
+
+	mock = vdtest.NewDevice()
+	w = newThrustmasterT300RS(mock)
+	w.Throttle(1)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_Y, 1023))
+	w.Brake(-1)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_RZ, 0))
+}
+
+func TestWheel_Buttons(t *testing.T) {
+	mock := vdtest.NewDevice()
+	w := newLogitechG920(mock)
+
+	w.Press(ButtonShiftUp)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_TOP2, 1))
+	w.Release(ButtonShiftUp)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_TOP2, 0))
+
+	w.Press(ButtonDialCW)
+	mock.ExpectNoFrame(t)
+
+	buttons := w.SupportedButtons()
+	if len(buttons) != 11 || buttons[0] != ButtonShiftUp {
+		t.Errorf("SupportedButtons = %v", buttons)
+	}
+}
+
+func TestWheel_Gears(t *testing.T) {
+	mock := vdtest.NewDevice()
+	w := newLogitechG29(mock)
+
+	w.ShiftGear(1)
+	mock.ExpectFrame(t, vdtest.Button(0x12c, 1))
+	w.ShiftGear(2)
+	mock.ExpectFrame(t, vdtest.Button(0x12c, 0), vdtest.Button(0x12d, 1))
+	w.ShiftGear(2)
+	mock.ExpectNoFrame(t)
+	w.ShiftGear(GearReverse)
+	mock.ExpectFrame(t, vdtest.Button(0x12d, 0), vdtest.Button(linux.BTN_TRIGGER_HAPPY3, 1))
+	if w.Gear() != GearReverse {
+		t.Errorf("Gear = %d, want reverse", w.Gear())
+	}
+	w.ShiftGear(GearNeutral)
+	mock.ExpectFrame(t, vdtest.Button(linux.BTN_TRIGGER_HAPPY3, 0))
+
+	w.ShiftGear(7)
+	mock.ExpectNoFrame(t)
+	if w.Gear() != GearNeutral {
+		t.Errorf("Gear = %d, want neutral", w.Gear())
+	}
+
+	mock = vdtest.NewDevice()
+	newThrustmasterT300RS(mock).ShiftGear(1)
+	mock.ExpectNoFrame(t)
+}
+
+func TestWheel_DPad(t *testing.T) {
+	mock := vdtest.NewDevice()
+	w := newLogitechG29(mock)
+
+	w.MoveDPad(5, -1)
+	mock.ExpectFrame(t, vdtest.Abs(linux.ABS_HAT0X, 1), vdtest.Abs(linux.ABS_HAT0Y, -1))
+}
+
+func TestWheel_ForceFeedback(t *testing.T) {
+	mock := vdtest.NewDevice()
+	w := newLogitechG29(mock)
+
+	if len(mock.FFEffects) != 5 || mock.FFEffectsMax != 16 {
+		t.Fatalf("FFEffects = %v, max %d", mock.FFEffects, mock.FFEffectsMax)
+	}
+
+	left := linux.FFEffect{Type: uint16(linux.FF_CONSTANT), ID: -1, Direction: 0x4000}
+	left.SetConstant(linux.FFConstantEffect{Level: 0x7fff})
+	id, err := mock.UploadEffect(left)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spring := linux.FFEffect{Type: uint16(linux.FF_SPRING), ID: -1}
+	if _, err := mock.UploadEffect(spring); err != nil {
+		t.Fatal(err)
+	}
+
+	state := w.ForceFeedback()
+	if len(state.Effects) != 2 || len(state.Playing) != 0 || state.ConstantForce() != 0 {
+		t.Fatalf("unexpected state after uploads: %+v", state)
+	}
+
+	mock.PlayEffect(id, 1)
+	mock.SetGain(0x8000)
+	mock.SetAutocenter(0x1000)
+	state = w.ForceFeedback()
+	if force := state.ConstantForce(); math.Abs(force-0.5) > 0.001 {
+		t.Errorf("ConstantForce = %v, want 0.5", force)
+	}
+	if state.Autocenter != 0x1000 {
+		t.Errorf("Autocenter = 0x%x", state.Autocenter)
+	}
+
+	mock.StopEffect(id)
+	if w.ForceFeedback().ConstantForce() != 0 {
+		t.Error("stopped effect still rendered")
+	}
+	mock.PlayEffect(id, 1)
+	if err := mock.EraseEffect(id); err != nil {
+		t.Fatal(err)
+	}
+	if state := w.ForceFeedback(); len(state.Effects) != 1 || len(state.Playing) != 0 {
+		t.Errorf("unexpected state after erase: %+v", state)
+	}
+}